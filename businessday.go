@@ -0,0 +1,262 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BusinessPeriod is the period within which a BusinessDaySchedule counts
+// business days.
+type BusinessPeriod int
+
+const (
+	// PerMonth counts the business days of each month.
+	PerMonth BusinessPeriod = iota
+
+	// PerQuarter counts the business days of each quarter, from January, April,
+	// July and October.
+	PerQuarter
+)
+
+// months returns the number of months in the period.
+func (p BusinessPeriod) months() int {
+	if p == PerQuarter {
+		return 3
+	}
+	return 1
+}
+
+// BusinessDaySchedule activates once in each month or quarter, on its Nth
+// business day, e.g. the 3rd business day of the month, or the last business
+// day of the quarter.  Business days are those its calendar does not exclude.
+type BusinessDaySchedule struct {
+	// N is the business day of the period: 1 for the first, 2 for the second,
+	// and so on, or -1 for the last, -2 for the last but one, and so on.
+	N int
+
+	Period BusinessPeriod
+
+	// Calendar excludes the days which are not business days.  If nil,
+	// Saturdays and Sundays are excluded.
+	Calendar Calendar
+
+	// Hour, Minute and Second give the time of day of the activations.
+	Hour, Minute, Second int
+
+	// Location in which days begin and end.  As with SpecSchedule,
+	// time.Local means the location of the time given to Next.
+	Location *time.Location
+}
+
+// BusinessDay returns a schedule activating at midnight on the Nth business
+// day of each period, counting from its end if n is negative.  The calendar
+// may be nil to only exclude weekends.
+func BusinessDay(n int, period BusinessPeriod, calendar Calendar) BusinessDaySchedule {
+	return BusinessDaySchedule{N: n, Period: period, Calendar: calendar, Location: time.Local}
+}
+
+// maxBusinessDays are the most weekdays there are in each period.
+var maxBusinessDays = map[BusinessPeriod]int{PerMonth: 23, PerQuarter: 66}
+
+// maxBusinessPeriods is the number of periods Next and Prev search for an
+// activation: five years of months.
+const maxBusinessPeriods = 5 * 12
+
+// Next returns the first activation after the given time, or the zero time if
+// there is none within five years.
+func (s BusinessDaySchedule) Next(t time.Time) time.Time {
+	origLocation := t.Location()
+	loc := s.location(t)
+	t = t.In(loc)
+	year, month := s.periodOf(t)
+	for i := 0; i < maxBusinessPeriods; i++ {
+		if next, ok := s.activation(year, month+time.Month(i*s.Period.months()), loc); ok && next.After(t) {
+			return next.In(origLocation)
+		}
+	}
+	return time.Time{}
+}
+
+// Prev returns the last activation before the given time, or the zero time if
+// there is none within five years.
+func (s BusinessDaySchedule) Prev(t time.Time) time.Time {
+	origLocation := t.Location()
+	loc := s.location(t)
+	t = t.In(loc)
+	year, month := s.periodOf(t)
+	for i := 0; i < maxBusinessPeriods; i++ {
+		if prev, ok := s.activation(year, month-time.Month(i*s.Period.months()), loc); ok && prev.Before(t) {
+			return prev.In(origLocation)
+		}
+	}
+	return time.Time{}
+}
+
+func (s BusinessDaySchedule) location(t time.Time) *time.Location {
+	if s.Location == nil || s.Location == time.Local {
+		return t.Location()
+	}
+	return s.Location
+}
+
+// periodOf returns the first month of the period containing the given time.
+func (s BusinessDaySchedule) periodOf(t time.Time) (int, time.Month) {
+	n := s.Period.months()
+	return t.Year(), t.Month() - (t.Month()-1)%time.Month(n)
+}
+
+// activation returns the activation in the period starting with the given
+// month, which is normalized as by time.Date, or false if the period does not
+// have enough business days.
+func (s BusinessDaySchedule) activation(year int, month time.Month, loc *time.Location) (time.Time, bool) {
+	var (
+		first = time.Date(year, month, 1, 0, 0, 0, 0, loc)
+		end   = first.AddDate(0, s.Period.months(), 0)
+		days  = daysBetween(dateOf(first), dateOf(end))
+		count = 0
+	)
+	for i := 0; i < days; i++ {
+		day := i
+		if s.N < 0 {
+			day = days - 1 - i
+		}
+		date := time.Date(first.Year(), first.Month(), 1+day, s.Hour, s.Minute, s.Second, 0, loc)
+		if s.excludes(date) {
+			continue
+		}
+		if count++; count == s.N || count == -s.N {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// excludes returns true if the day of the given time is not a business day.
+func (s BusinessDaySchedule) excludes(t time.Time) bool {
+	if s.Calendar == nil {
+		return t.Weekday() == time.Saturday || t.Weekday() == time.Sunday
+	}
+	return s.Calendar.Excludes(t)
+}
+
+// String returns the "@bday" descriptor for the schedule, e.g.
+// "@bday -1 quarter at 18:00".  The calendar is not part of it.
+func (s BusinessDaySchedule) String() string {
+	spec := "@bday " + strconv.Itoa(s.N)
+	if s.Period == PerQuarter {
+		spec += " quarter"
+	}
+	if s.Hour != 0 || s.Minute != 0 || s.Second != 0 {
+		spec += " at " + s.timeOfDay()
+	}
+	if s.Location != nil && s.Location != time.Local {
+		spec = "TZ=" + s.Location.String() + " " + spec
+	}
+	return spec
+}
+
+// MarshalText implements encoding.TextMarshaler, returning the descriptor.
+func (s BusinessDaySchedule) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s BusinessDaySchedule) timeOfDay() string {
+	if s.Second != 0 {
+		return fmt.Sprintf("%02d:%02d:%02d", s.Hour, s.Minute, s.Second)
+	}
+	return fmt.Sprintf("%02d:%02d", s.Hour, s.Minute)
+}
+
+// Describe describes the schedule, e.g. "At 09:00 on the 3rd business day of
+// the month".
+func (s BusinessDaySchedule) Describe(lang Language) string {
+	n := s.N
+	if n < 0 {
+		n = -n
+	}
+	if lang == Chinese {
+		period := "每月"
+		if s.Period == PerQuarter {
+			period = "每季度"
+		}
+		day := fmt.Sprintf("第%d个工作日", n)
+		if s.N == -1 {
+			day = "最后一个工作日"
+		} else if s.N < 0 {
+			day = fmt.Sprintf("倒数第%d个工作日", n)
+		}
+		return period + day + " " + s.timeOfDay() + lang.location(s.Location)
+	}
+
+	day := "the " + ordinal(n) + " business day"
+	if s.N == -1 {
+		day = "the last business day"
+	} else if s.N < 0 {
+		day = "the " + ordinal(n) + " last business day"
+	}
+	period := "of the month"
+	if s.Period == PerQuarter {
+		period = "of the quarter"
+	}
+	return lang.sentence("at "+s.timeOfDay(), "on", day, period, lang.location(s.Location))
+}
+
+// parseBusinessDay returns the schedule for the "@bday" descriptor given the
+// fields following it:
+//   number [ "month" | "quarter" ] [ "at" hh:mm[:ss] ]
+func parseBusinessDay(fields []string, descriptor string, loc *time.Location) (Schedule, error) {
+	if len(fields) == 0 {
+		return nil, parseErrorf(ReasonSyntax, "", "missing business day: %s", descriptor)
+	}
+	number, fields := fields[0], fields[1:]
+	n, err := strconv.Atoi(number)
+	if err != nil {
+		return nil, parseErrorf(ReasonNumber, number, "failed to parse business day %s: %s", number, descriptor)
+	}
+	if n == 0 {
+		return nil, parseErrorf(ReasonNumber, number, "business day must not be zero: %s", descriptor)
+	}
+	s := BusinessDaySchedule{N: n, Location: loc}
+
+	if len(fields) > 0 {
+		switch strings.ToLower(fields[0]) {
+		case "month":
+			fields = fields[1:]
+		case "quarter":
+			s.Period = PerQuarter
+			fields = fields[1:]
+		}
+	}
+	if len(fields) > 0 && strings.ToLower(fields[0]) == "at" {
+		if len(fields) < 2 {
+			return nil, parseErrorf(ReasonSyntax, "", "missing time after at: %s", descriptor)
+		}
+		if s.Hour, s.Minute, s.Second, err = parseClock(fields[1]); err != nil {
+			return nil, parseErrorf(ReasonSyntax, fields[1], "failed to parse time %s: %s", fields[1], descriptor)
+		}
+		fields = fields[2:]
+	}
+	if len(fields) > 0 {
+		return nil, parseErrorf(ReasonUnexpected, fields[0], "unexpected %q after business day: %s", fields[0], descriptor)
+	}
+	if max := maxBusinessDays[s.Period]; n > max || n < -max {
+		reason := ReasonAboveMaximum
+		if n < 0 {
+			reason = ReasonBelowMinimum
+		}
+		return nil, parseErrorf(reason, number, "business day (%d) out of range (%d-%d, or -%d to -1): %s", n, 1, max, max, descriptor)
+	}
+	return s, nil
+}
+
+// parseClock parses a time of day, "15:04" or "15:04:05".
+func parseClock(value string) (hour, minute, second int, err error) {
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		if t, perr := time.Parse(layout, value); perr == nil {
+			return t.Hour(), t.Minute(), t.Second(), nil
+		}
+	}
+	return 0, 0, 0, fmt.Errorf("invalid time of day: %s", value)
+}
@@ -0,0 +1,152 @@
+package cron
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBusinessDayNext(t *testing.T) {
+	holidays := NewHolidayCalendar(getTime("Wed Jul 4 00:00 2012"))
+	holidays.Weekends = true
+
+	tests := []struct {
+		spec     string
+		calendar Calendar
+		time     string
+		expected []string
+	}{
+		{"@bday 3", nil, "Sun Jul 1 00:00 2012",
+			[]string{"Wed Jul 4 00:00 2012", "Fri Aug 3 00:00 2012", "Wed Sep 5 00:00 2012"}},
+		{"@bday 3 at 09:30", holidays, "Wed Jul 4 12:00 2012",
+			[]string{"Thu Jul 5 09:30 2012", "Fri Aug 3 09:30 2012"}},
+		{"@bday -1 at 18:00", nil, "Tue Jul 31 18:00 2012",
+			[]string{"Fri Aug 31 18:00 2012", "Fri Sep 28 18:00 2012"}},
+		{"@bday -2", nil, "Sun Jul 1 00:00 2012",
+			[]string{"Mon Jul 30 00:00 2012", "Thu Aug 30 00:00 2012", "Thu Sep 27 00:00 2012"}},
+		{"@bday -1 quarter", nil, "Sun Jul 1 00:00 2012",
+			[]string{"Fri Sep 28 00:00 2012", "Mon Dec 31 00:00 2012", "Fri Mar 29 00:00 2013"}},
+		{"@bday 1 quarter at 08:00:30", nil, "Mon Jan 2 09:00 2012",
+			[]string{"Mon Apr 2 08:00:30 2012", "Mon Jul 2 08:00:30 2012"}},
+		{"TZ=UTC @bday 1", nil, "2012-07-01T23:00:00-0200",
+			[]string{"2012-08-01T00:00:00+0000"}},
+	}
+	for _, c := range tests {
+		sched, err := ParseStandard(c.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		s := sched.(BusinessDaySchedule)
+		s.Calendar = c.calendar
+		assertTimes(t, c.spec, NextN(s, getTime(c.time), len(c.expected)), c.expected)
+	}
+
+	// A calendar excluding every day has no business days.
+	s := BusinessDay(1, PerMonth, excludeAll{})
+	if next := s.Next(getTime("Sun Jul 1 00:00 2012")); !next.IsZero() {
+		t.Errorf("expected no activation, got %v", next)
+	}
+}
+
+// excludeAll is a calendar without business days.
+type excludeAll struct{}
+
+func (excludeAll) Excludes(time.Time) bool { return true }
+
+func TestBusinessDayPrev(t *testing.T) {
+	tests := []struct {
+		spec     string
+		time     string
+		expected string
+	}{
+		{"@bday 3", "Tue Jul 10 00:00 2012", "Wed Jul 4 00:00 2012"},
+		{"@bday 3", "Wed Jul 4 00:00 2012", "Tue Jun 5 00:00 2012"},
+		{"@bday -1 quarter", "Mon Dec 31 00:00 2012", "Fri Sep 28 00:00 2012"},
+	}
+	for _, c := range tests {
+		sched, err := ParseStandard(c.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		prev := sched.(PrevScheduler).Prev(getTime(c.time))
+		if !prev.Equal(getTime(c.expected)) {
+			t.Errorf("%s: expected %v, got %v", c.spec, getTime(c.expected), prev)
+		}
+	}
+}
+
+func TestBusinessDayParseErrors(t *testing.T) {
+	tests := []struct {
+		spec   string
+		reason ParseErrorReason
+	}{
+		{"@bday", ReasonSyntax},
+		{"@bday x", ReasonNumber},
+		{"@bday 0", ReasonNumber},
+		{"@bday 24", ReasonAboveMaximum},
+		{"@bday -24", ReasonBelowMinimum},
+		{"@bday 67 quarter", ReasonAboveMaximum},
+		{"@bday 3 at", ReasonSyntax},
+		{"@bday 3 at 25:00", ReasonSyntax},
+		{"@bday 3 year", ReasonUnexpected},
+	}
+	for _, c := range tests {
+		_, err := ParseStandard(c.spec)
+		perr, ok := err.(*ParseError)
+		if !ok {
+			t.Errorf("%s: expected a parse error, got %v", c.spec, err)
+			continue
+		}
+		if perr.Reason != c.reason {
+			t.Errorf("%s: expected reason %s, got %s", c.spec, c.reason, perr.Reason)
+		}
+	}
+	if _, err := ParseStandard("@bday 30 quarter"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBusinessDayString(t *testing.T) {
+	tests := []struct {
+		spec, en, zh string
+	}{
+		{"@bday 3", "At 00:00 on the 3rd business day of the month", "每月第3个工作日 00:00"},
+		{"@bday -1 quarter at 18:00", "At 18:00 on the last business day of the quarter", "每季度最后一个工作日 18:00"},
+		{"@bday -2 at 09:30", "At 09:30 on the 2nd last business day of the month", "每月倒数第2个工作日 09:30"},
+		{"TZ=Asia/Shanghai @bday 1 at 08:00:30", "At 08:00:30 on the 1st business day of the month in Asia/Shanghai", "每月第1个工作日 08:00:30（Asia/Shanghai）"},
+	}
+	for _, c := range tests {
+		sched, err := ParseStandard(c.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual := sched.(BusinessDaySchedule).String(); actual != c.spec {
+			t.Errorf("expected %q, got %q", c.spec, actual)
+		}
+		if actual := Describe(sched); actual != c.en {
+			t.Errorf("expected %q, got %q", c.en, actual)
+		}
+		if actual := Chinese.Describe(sched); actual != c.zh {
+			t.Errorf("expected %q, got %q", c.zh, actual)
+		}
+		if parsed, err := parseText(c.spec); err != nil || parsed.(BusinessDaySchedule).String() != c.spec {
+			t.Errorf("%s: expected to parse back, got %v (%v)", c.spec, parsed, err)
+		}
+	}
+}
+
+func TestAddCalendarFuncBusinessDay(t *testing.T) {
+	cron := New()
+	holidays := NewHolidayCalendar(getTime("Wed Jul 4 00:00 2012"))
+	holidays.Weekends = true
+	id, err := cron.AddCalendarFunc("billing", "@bday 3", holidays, SkipHolidays, func(context.Context) error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, ok := cron.Entry(id).Schedule.(BusinessDaySchedule)
+	if !ok || s.Calendar != Calendar(holidays) {
+		t.Fatalf("expected a business-day schedule on the calendar, got %#v", cron.Entry(id).Schedule)
+	}
+	assertTimes(t, "@bday 3", NextN(s, getTime("Sun Jul 1 00:00 2012"), 1), []string{"Thu Jul 5 00:00 2012"})
+}
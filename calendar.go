@@ -0,0 +1,364 @@
+package cron
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Calendar excludes days from schedules, such as public holidays.  It is
+// consulted by CalendarSchedule, which is attached to entries by
+// Cron.AddCalendarJob.
+type Calendar interface {
+	// Excludes returns true if the day of the given time is excluded.
+	Excludes(t time.Time) bool
+}
+
+// HolidayCalendar is a Calendar excluding whole days, like Quartz's
+// HolidayCalendar.  It may be built from dates, or loaded from an iCalendar
+// (.ics) file or a list of dates by LoadCalendar.
+type HolidayCalendar struct {
+	// Location in which days begin and end.  As with SpecSchedule,
+	// time.Local means the location of the time given to Excludes.
+	Location *time.Location
+
+	// Weekends, if set, also excludes every Saturday and Sunday.
+	Weekends bool
+
+	dates       map[civilDate]bool
+	recurrences []holidayRecurrence
+}
+
+// civilDate is a day of the calendar, independent of any location.
+type civilDate struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+// dateOf returns the day of the given time, in its location.
+func dateOf(t time.Time) civilDate {
+	year, month, day := t.Date()
+	return civilDate{year, month, day}
+}
+
+// holidayRecurrence is a recurring event of an iCalendar file, lasting for
+// the given number of days from each occurrence.
+type holidayRecurrence struct {
+	schedule *RecurrenceSchedule
+	days     int
+}
+
+// NewHolidayCalendar returns a calendar excluding the days of the given
+// times, in their own locations.
+func NewHolidayCalendar(dates ...time.Time) *HolidayCalendar {
+	c := &HolidayCalendar{Location: time.Local}
+	for _, date := range dates {
+		c.Add(date)
+	}
+	return c
+}
+
+// Add excludes the day of the given time, in its own location.
+func (c *HolidayCalendar) Add(date time.Time) {
+	if c.dates == nil {
+		c.dates = make(map[civilDate]bool)
+	}
+	c.dates[dateOf(date)] = true
+}
+
+// Excludes returns true if the day of the given time is one of the dates of
+// the calendar, falls within one of its events, or is a weekend day while
+// Weekends is set.
+func (c *HolidayCalendar) Excludes(t time.Time) bool {
+	if c.Location != nil && c.Location != time.Local {
+		t = t.In(c.Location)
+	}
+	if c.Weekends && (t.Weekday() == time.Saturday || t.Weekday() == time.Sunday) {
+		return true
+	}
+	if c.dates[dateOf(t)] {
+		return true
+	}
+	for _, r := range c.recurrences {
+		// The event covers the day if it occurs on it or, for events lasting
+		// several days, on one of the days before.
+		loc := r.schedule.Start.Location()
+		for i := 0; i < r.days; i++ {
+			begin := time.Date(t.Year(), t.Month(), t.Day()-i, 0, 0, 0, 0, loc)
+			next := r.schedule.Next(begin.Add(-time.Second))
+			if !next.IsZero() && next.Before(begin.AddDate(0, 0, 1)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// LoadCalendar loads a HolidayCalendar from the file at the given path: an
+// iCalendar file if its name ends in ".ics", and otherwise a list of dates as
+// read by ParseDateList.
+func LoadCalendar(path string) (*HolidayCalendar, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if strings.EqualFold(filepath.Ext(path), ".ics") {
+		return ParseICS(f)
+	}
+	return ParseDateList(f)
+}
+
+// ParseDateList reads a calendar from a list of dates, one per line, in the
+// form "2006-01-02".  Anything after the date, such as the name of the
+// holiday, is ignored, as are blank lines and lines starting with "#".
+func ParseDateList(r io.Reader) (*HolidayCalendar, error) {
+	var (
+		c       = NewHolidayCalendar()
+		scanner = bufio.NewScanner(r)
+	)
+	for n := 1; scanner.Scan(); n++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: failed to parse date %s: %v", n, fields[0], err)
+		}
+		c.Add(date)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// ParseICS reads a calendar from an iCalendar (RFC 5545) file, such as the
+// public holiday calendars published by calendar applications.  Each VEVENT
+// excludes the days from its DTSTART up to its DTEND, or only the day of
+// DTSTART if it has no DTEND.  Events repeat as given by their RRULE, RDATE
+// and EXDATE properties.
+//
+// Dates are read as days independent of any location, and times without a
+// TZID in time.Local.
+func ParseICS(r io.Reader) (*HolidayCalendar, error) {
+	var (
+		c       = NewHolidayCalendar()
+		scanner = bufio.NewScanner(r)
+		lines   []string
+		numbers []int
+	)
+	// Unfold the lines: those starting with whitespace continue the last.
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+		numbers = append(numbers, n)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var event []string
+	for i, line := range lines {
+		switch upper := strings.ToUpper(line); {
+		case upper == "BEGIN:VEVENT":
+			event = []string{}
+		case upper == "END:VEVENT" && event != nil:
+			if err := c.addEvent(event); err != nil {
+				return nil, fmt.Errorf("line %d: %v", numbers[i], err)
+			}
+			event = nil
+		case event != nil:
+			event = append(event, line)
+		}
+	}
+	return c, nil
+}
+
+// addEvent excludes the days of the event with the given properties.
+func (c *HolidayCalendar) addEvent(props []string) error {
+	var (
+		start, end time.Time
+		recurrence []string
+		repeats    bool
+		err        error
+	)
+	for _, prop := range props {
+		name, params, value, perr := splitProperty(prop)
+		if perr != nil {
+			// Other properties, such as descriptions, may be of any form.
+			continue
+		}
+		switch name {
+		case "DTSTART":
+			if start, err = parseDateTime(prop, value, params, time.Local); err != nil {
+				return err
+			}
+			recurrence = append(recurrence, prop)
+		case "DTEND":
+			if end, err = parseDateTime(prop, value, params, time.Local); err != nil {
+				return err
+			}
+		case "RRULE", "RDATE", "EXDATE":
+			recurrence = append(recurrence, strings.Join(strings.Fields(prop), ""))
+			repeats = repeats || name != "EXDATE"
+		}
+	}
+	if start.IsZero() {
+		return fmt.Errorf("event has no DTSTART")
+	}
+
+	// The event lasts until the day before DTEND if it ends at midnight, as
+	// all-day events do, and otherwise until the day of DTEND.
+	days := 1
+	if end.After(start) {
+		end = end.Add(-time.Nanosecond).In(start.Location())
+		days += daysBetween(dateOf(start), dateOf(end))
+	}
+	if !repeats {
+		for i := 0; i < days; i++ {
+			c.Add(start.AddDate(0, 0, i))
+		}
+		return nil
+	}
+	schedule, err := parseRecurrence(strings.Join(recurrence, " "), time.Local)
+	if err != nil {
+		return err
+	}
+	c.recurrences = append(c.recurrences, holidayRecurrence{schedule: schedule, days: days})
+	return nil
+}
+
+// daysBetween returns the number of days from one date to another.
+func daysBetween(from, to civilDate) int {
+	var (
+		a = time.Date(from.year, from.month, from.day, 0, 0, 0, 0, time.UTC)
+		b = time.Date(to.year, to.month, to.day, 0, 0, 0, 0, time.UTC)
+	)
+	return int(b.Sub(a) / (24 * time.Hour))
+}
+
+// HolidayPolicy says what a CalendarSchedule does with activations on days
+// its calendar excludes.
+type HolidayPolicy int
+
+const (
+	// SkipHolidays drops activations on excluded days.
+	SkipHolidays HolidayPolicy = iota
+
+	// NextBusinessDay moves activations on excluded days to the same time on
+	// the next day which is not excluded.
+	NextBusinessDay
+
+	// PreviousBusinessDay moves activations on excluded days to the same time
+	// on the last day before them which is not excluded.
+	PreviousBusinessDay
+)
+
+// maxHolidays is the longest run of excluded days over which activations
+// are moved.  Activations which cannot be moved are dropped.
+const maxHolidays = 366
+
+// CalendarSchedule is a schedule whose activations on the days excluded by a
+// calendar are dropped or moved, as given by its policy.
+type CalendarSchedule struct {
+	Schedule Schedule
+	Calendar Calendar
+	Policy   HolidayPolicy
+}
+
+// AvoidHolidays returns the schedule, with its activations on the days the
+// calendar excludes dropped or moved as given by the policy.
+func AvoidHolidays(s Schedule, calendar Calendar, policy HolidayPolicy) CalendarSchedule {
+	return CalendarSchedule{Schedule: s, Calendar: calendar, Policy: policy}
+}
+
+// Next returns the first activation after the given time, once those on
+// excluded days have been dropped or moved, or the zero time if there is none
+// within five years.
+func (s CalendarSchedule) Next(t time.Time) time.Time {
+	var (
+		next  time.Time
+		after = t
+		limit = t.AddDate(5, 0, 0)
+	)
+	if s.Policy == NextBusinessDay {
+		// Activations on the excluded days just before the given time may be
+		// moved after it, so start from the first of them.
+		for i := 0; i <= maxHolidays; i++ {
+			day := time.Date(t.Year(), t.Month(), t.Day()-i, 0, 0, 0, 0, t.Location())
+			if s.Calendar.Excludes(day) {
+				after = day.Add(-time.Second)
+			} else if i > 0 {
+				break
+			}
+		}
+	}
+	for {
+		activation := s.Schedule.Next(after)
+		if activation.IsZero() || activation.After(limit) {
+			break
+		}
+		moved, ok := s.move(activation)
+
+		// Moved activations may come before earlier ones, so look on until no
+		// later activation could.
+		earliest := activation
+		if ok && s.Policy == PreviousBusinessDay {
+			earliest = time.Date(moved.Year(), moved.Month(), moved.Day(), 0, 0, 0, 0, moved.Location())
+		}
+		if !next.IsZero() && earliest.After(next) {
+			break
+		}
+		if ok && moved.After(t) && (next.IsZero() || moved.Before(next)) {
+			next = moved
+		}
+		after = activation
+	}
+	return next
+}
+
+// move returns the activation, moved off an excluded day as given by the
+// policy, or false if it is dropped.
+func (s CalendarSchedule) move(t time.Time) (time.Time, bool) {
+	if !s.Calendar.Excludes(t) {
+		return t, true
+	}
+	step := 1
+	switch s.Policy {
+	case NextBusinessDay:
+	case PreviousBusinessDay:
+		step = -1
+	default:
+		return time.Time{}, false
+	}
+	for i := 1; i <= maxHolidays; i++ {
+		moved := time.Date(t.Year(), t.Month(), t.Day()+i*step, t.Hour(), t.Minute(), t.Second(), 0, t.Location())
+		if !s.Calendar.Excludes(moved) {
+			return moved, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Describe describes the schedule and what it does on holidays.
+func (s CalendarSchedule) Describe(lang Language) string {
+	description := lang.describeOperand(s.Schedule, false)
+	switch s.Policy {
+	case NextBusinessDay:
+		return description + lang.pick(", moved to the next business day on holidays", "，遇节假日顺延至下一个工作日")
+	case PreviousBusinessDay:
+		return description + lang.pick(", moved to the previous business day on holidays", "，遇节假日提前至上一个工作日")
+	}
+	return description + lang.pick(", except on holidays", "，节假日除外")
+}
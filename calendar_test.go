@@ -0,0 +1,204 @@
+package cron
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const holidaysICS = `BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//Example//Holidays//EN
+BEGIN:VEVENT
+UID:christmas@example.com
+SUMMARY:Christmas Day
+DTSTART;VALUE=DATE:20101225
+DTEND;VALUE=DATE:20101226
+RRULE:FREQ=YEARLY
+END:VEVENT
+BEGIN:VEVENT
+UID:golden-week@example.com
+SUMMARY:National Day
+DESCRIPTION:Offices are closed for the National Day holiday, which lasts
+  for two days this year.
+DTSTART;VALUE=DATE:20121001
+DTEND;VALUE=DATE:20121003
+END:VEVENT
+BEGIN:VEVENT
+UID:outage@example.com
+SUMMARY:Data centre move
+DTSTART;TZID=Asia/Shanghai:20120714T220000
+DTEND;TZID=Asia/Shanghai:20120715T020000
+END:VEVENT
+END:VCALENDAR
+`
+
+func TestParseICS(t *testing.T) {
+	c, err := ParseICS(strings.NewReader(holidaysICS))
+	if err != nil {
+		t.Fatal(err)
+	}
+	shanghai, _ := time.LoadLocation("Asia/Shanghai")
+	tests := []struct {
+		time     time.Time
+		excluded bool
+	}{
+		{time.Date(2012, time.December, 25, 9, 0, 0, 0, time.UTC), true},
+		{time.Date(2031, time.December, 25, 0, 0, 0, 0, time.UTC), true},
+		{time.Date(2012, time.December, 26, 0, 0, 0, 0, time.UTC), false},
+		{time.Date(2009, time.December, 25, 0, 0, 0, 0, time.UTC), false},
+		{time.Date(2012, time.October, 1, 0, 0, 0, 0, time.UTC), true},
+		{time.Date(2012, time.October, 2, 23, 59, 0, 0, time.UTC), true},
+		{time.Date(2012, time.October, 3, 0, 0, 0, 0, time.UTC), false},
+		{time.Date(2012, time.July, 14, 9, 0, 0, 0, shanghai), true},
+		{time.Date(2012, time.July, 15, 9, 0, 0, 0, shanghai), true},
+		{time.Date(2012, time.July, 16, 9, 0, 0, 0, shanghai), false},
+	}
+	for _, c2 := range tests {
+		if actual := c.Excludes(c2.time); actual != c2.excluded {
+			t.Errorf("%v: expected %v, got %v", c2.time, c2.excluded, actual)
+		}
+	}
+
+	if _, err := ParseICS(strings.NewReader("BEGIN:VEVENT\nDTSTART:2012\nEND:VEVENT\n")); err == nil {
+		t.Error("expected an error for a bad DTSTART")
+	}
+	if _, err := ParseICS(strings.NewReader("BEGIN:VEVENT\nSUMMARY:Nothing\nEND:VEVENT\n")); err == nil {
+		t.Error("expected an error for an event without DTSTART")
+	}
+}
+
+func TestLoadCalendar(t *testing.T) {
+	dir, err := ioutil.TempDir("", "calendar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"holidays.ics": holidaysICS,
+		"holidays.txt": "# Public holidays\n2012-10-01 National Day\n\n2012-12-25 Christmas Day\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		c, err := LoadCalendar(path)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		for _, date := range []string{"Mon Oct 1 09:00 2012", "Tue Dec 25 00:00 2012"} {
+			if !c.Excludes(getTime(date)) {
+				t.Errorf("%s: expected %s to be excluded", name, date)
+			}
+		}
+		if c.Excludes(getTime("Tue Dec 18 00:00 2012")) {
+			t.Errorf("%s: expected Tue Dec 18 2012 not to be excluded", name)
+		}
+	}
+
+	if _, err := ParseDateList(strings.NewReader("2012-13-01\n")); err == nil {
+		t.Error("expected an error for a bad date")
+	}
+	if _, err := LoadCalendar(filepath.Join(dir, "missing.ics")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestCalendarScheduleNext(t *testing.T) {
+	holidays := NewHolidayCalendar(getTime("Wed Jul 11 00:00 2012"), getTime("Thu Jul 12 00:00 2012"))
+	businessDays := NewHolidayCalendar(getTime("Mon Jul 16 00:00 2012"))
+	businessDays.Weekends = true
+
+	tests := []struct {
+		spec     string
+		calendar Calendar
+		policy   HolidayPolicy
+		time     string
+		expected []string
+	}{
+		{"0 9 * * *", holidays, SkipHolidays, "Tue Jul 10 08:00 2012",
+			[]string{"Tue Jul 10 09:00 2012", "Fri Jul 13 09:00 2012", "Sat Jul 14 09:00 2012"}},
+		{"0 9 * * *", holidays, NextBusinessDay, "Tue Jul 10 08:00 2012",
+			[]string{"Tue Jul 10 09:00 2012", "Fri Jul 13 09:00 2012", "Sat Jul 14 09:00 2012"}},
+		{"0 9 * * *", holidays, PreviousBusinessDay, "Tue Jul 10 10:00 2012",
+			[]string{"Fri Jul 13 09:00 2012", "Sat Jul 14 09:00 2012"}},
+		{"0 9 11 * *", holidays, NextBusinessDay, "Mon Jul 9 00:00 2012",
+			[]string{"Fri Jul 13 09:00 2012", "Sat Aug 11 09:00 2012"}},
+		{"0 9 12 * *", holidays, PreviousBusinessDay, "Mon Jul 9 00:00 2012",
+			[]string{"Tue Jul 10 09:00 2012", "Sun Aug 12 09:00 2012"}},
+		{"0 9 14 * *", businessDays, NextBusinessDay, "Mon Jul 9 00:00 2012",
+			[]string{"Tue Jul 17 09:00 2012", "Tue Aug 14 09:00 2012"}},
+		{"0 9 * * 1-5", businessDays, SkipHolidays, "Fri Jul 13 10:00 2012",
+			[]string{"Tue Jul 17 09:00 2012"}},
+
+		// Moved activations come in order.
+		{"0 10 * * 2 | 0 9 * * 3", holidays, PreviousBusinessDay, "Mon Jul 9 00:00 2012",
+			[]string{"Tue Jul 10 09:00 2012", "Tue Jul 10 10:00 2012", "Tue Jul 17 10:00 2012"}},
+		{"0 10 * * 4 | 0 9 * * 5", holidays, NextBusinessDay, "Mon Jul 9 00:00 2012",
+			[]string{"Fri Jul 13 09:00 2012", "Fri Jul 13 10:00 2012", "Thu Jul 19 10:00 2012"}},
+		{"0 9,11 * * *", holidays, NextBusinessDay, "Wed Jul 11 10:00 2012",
+			[]string{"Fri Jul 13 09:00 2012", "Fri Jul 13 11:00 2012"}},
+
+		// Every day excluded
+		{"0 9 * * 0,6", businessDays, SkipHolidays, "Mon Jul 9 00:00 2012", nil},
+	}
+
+	for _, c := range tests {
+		sched, err := ParseStandard(c.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := AvoidHolidays(sched, c.calendar, c.policy)
+		n := len(c.expected)
+		if n == 0 {
+			n = 1
+		}
+		assertTimes(t, c.spec, NextN(s, getTime(c.time), n), c.expected)
+	}
+}
+
+func TestCalendarScheduleDescribe(t *testing.T) {
+	sched, _ := ParseStandard("0 9 * * 1-5")
+	tests := []struct {
+		policy HolidayPolicy
+		en, zh string
+	}{
+		{SkipHolidays, "At 09:00 on Monday through Friday, except on holidays", "每周一至周五 09:00，节假日除外"},
+		{NextBusinessDay, "At 09:00 on Monday through Friday, moved to the next business day on holidays", "每周一至周五 09:00，遇节假日顺延至下一个工作日"},
+	}
+	for _, c := range tests {
+		s := AvoidHolidays(sched, NewHolidayCalendar(), c.policy)
+		if actual := Describe(s); actual != c.en {
+			t.Errorf("expected %q, got %q", c.en, actual)
+		}
+		if actual := Chinese.Describe(s); actual != c.zh {
+			t.Errorf("expected %q, got %q", c.zh, actual)
+		}
+	}
+}
+
+func TestAddCalendarFunc(t *testing.T) {
+	cron := New()
+	holidays := NewHolidayCalendar(time.Now())
+	id, err := cron.AddCalendarFunc("report", "0 9 * * *", holidays, SkipHolidays, func(context.Context) error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := cron.Entry(id)
+	if entry.Spec != "0 9 * * *" {
+		t.Errorf("expected spec %q, got %q", "0 9 * * *", entry.Spec)
+	}
+	s, ok := entry.Schedule.(CalendarSchedule)
+	if !ok || s.Calendar != Calendar(holidays) || s.Policy != SkipHolidays {
+		t.Errorf("expected a calendar schedule, got %#v", entry.Schedule)
+	}
+	if _, err := cron.AddCalendarFunc("report", "0 9 * *", holidays, SkipHolidays, nil); err == nil {
+		t.Error("expected an error for a bad spec")
+	}
+}
@@ -0,0 +1,382 @@
+package cron
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// UnionSchedule activates whenever any of its schedules does, e.g. at 9:00 on
+// weekdays and at 12:00 on Saturdays.  It backs the "|" operator in specs.
+type UnionSchedule struct {
+	Schedules []Schedule
+}
+
+// Union returns a schedule which activates whenever any of the given
+// schedules does.
+func Union(schedules ...Schedule) UnionSchedule {
+	return UnionSchedule{Schedules: schedules}
+}
+
+// Next returns the earliest of the next activations of the schedules, or the
+// zero time if none of them activates again.
+func (schedule UnionSchedule) Next(t time.Time) time.Time {
+	var next time.Time
+	for _, s := range schedule.Schedules {
+		next = earliest(next, s.Next(t))
+	}
+	return next
+}
+
+// Start returns the earliest of the first activations of the schedules, so
+// that a union may include "@reboot".
+func (schedule UnionSchedule) Start(t time.Time) time.Time {
+	var next time.Time
+	for _, s := range schedule.Schedules {
+		next = earliest(next, first(s, t))
+	}
+	return next
+}
+
+// earliest returns the earlier of the two times, ignoring zero times.
+func earliest(a, b time.Time) time.Time {
+	if a.IsZero() || !b.IsZero() && b.Before(a) {
+		return b
+	}
+	return a
+}
+
+// String returns the specs of the schedules joined by "|".
+func (schedule UnionSchedule) String() string {
+	specs := make([]string, len(schedule.Schedules))
+	for i, s := range schedule.Schedules {
+		specs[i] = fmt.Sprint(s)
+	}
+	return strings.Join(specs, " | ")
+}
+
+// Describe describes the schedules, e.g. "At 09:00 on Monday through Friday,
+// or at 12:00 on Saturday".
+func (schedule UnionSchedule) Describe(lang Language) string {
+	descriptions := make([]string, len(schedule.Schedules))
+	for i, s := range schedule.Schedules {
+		descriptions[i] = lang.describeOperand(s, i > 0)
+	}
+	return strings.Join(descriptions, lang.pick(", or ", "，或"))
+}
+
+// MarshalText implements encoding.TextMarshaler, returning the spec.
+func (schedule UnionSchedule) MarshalText() ([]byte, error) {
+	return []byte(schedule.String()), nil
+}
+
+// IntersectSchedule activates only at the times at which both of its
+// schedules activate, e.g. at 9:00 on weekdays, but only in the first week of
+// the month, or at which one activates within an activation of the other.  It
+// backs the "&" operator in specs.
+type IntersectSchedule struct {
+	A, B Schedule
+}
+
+// Intersect returns a schedule which activates only when both of the given
+// schedules do.
+func Intersect(a, b Schedule) IntersectSchedule {
+	return IntersectSchedule{A: a, B: b}
+}
+
+// compositeSteps is how many activations of their operands IntersectSchedule
+// and ExceptSchedule look through for one of their own, before giving up.
+const compositeSteps = 100000
+
+// Next returns the next time at which one schedule activates within an
+// activation of the other, or the zero time if there is none within
+// compositeSteps activations.  Schedules coincide as covers describes, so
+// "@every 10m & * 2 * * *" activates every 10 minutes between 02:00 and
+// 02:59.
+func (schedule IntersectSchedule) Next(t time.Time) time.Time {
+	for i := 0; i < compositeSteps; i++ {
+		a, b := schedule.A.Next(t), schedule.B.Next(t)
+		if a.IsZero() || b.IsZero() {
+			break
+		}
+		coveredA, coveredB := covers(schedule.B, a), covers(schedule.A, b)
+		switch {
+		case coveredA && (!coveredB || !b.Before(a)):
+			return a
+		case coveredB:
+			return b
+		}
+		// Neither schedule activates within the other before the later of
+		// the two activations, from which the search continues.
+		if a.After(b) {
+			b = a
+		}
+		t = b
+	}
+	return time.Time{}
+}
+
+// String returns the specs of the schedules joined by "&".
+func (schedule IntersectSchedule) String() string {
+	return operandString(schedule.A, false) + " & " + operandString(schedule.B, true)
+}
+
+// Describe describes both schedules, e.g. "At 09:00 on Monday through
+// Friday, if also on days 1 through 7 of the month".
+func (schedule IntersectSchedule) Describe(lang Language) string {
+	return lang.describeOperand(schedule.A, false) + lang.pick(", if also ", "，且") +
+		lang.describeOperand(schedule.B, true)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning the spec.
+func (schedule IntersectSchedule) MarshalText() ([]byte, error) {
+	return []byte(schedule.String()), nil
+}
+
+// ExceptSchedule activates whenever its Schedule does, except within the
+// activations of Blackout.  It backs the "&^" operator in specs.
+//
+// A blackout window is given as a schedule activating throughout it, e.g.
+// "* 2 * * *" for the hour from 02:00, which covers any time within each of
+// its minutes.
+type ExceptSchedule struct {
+	Schedule Schedule
+	Blackout Schedule
+}
+
+// Except returns a schedule which activates whenever the given schedule does,
+// except at times at which the blackout schedule also activates.
+func Except(s, blackout Schedule) ExceptSchedule {
+	return ExceptSchedule{Schedule: s, Blackout: blackout}
+}
+
+// Next returns the next activation of the schedule not covered by the
+// blackout, or the zero time if there is none within compositeSteps
+// activations.
+func (schedule ExceptSchedule) Next(t time.Time) time.Time {
+	for i := 0; i < compositeSteps; i++ {
+		next := schedule.Schedule.Next(t)
+		if next.IsZero() {
+			break
+		}
+		if !covers(schedule.Blackout, next) {
+			return next
+		}
+		t = next
+	}
+	return time.Time{}
+}
+
+// covers returns true if the schedule activates within its own granularity
+// of the given time, so that an activation of another schedule falling there
+// coincides with it.  A SpecSchedule covers each of its minutes, or of its
+// seconds or milliseconds if it has any but the first, and a union covers
+// the times any of its schedules do.  Other schedules only cover the instants
+// at which they activate.
+func covers(s Schedule, t time.Time) bool {
+	switch s := s.(type) {
+	case *SpecSchedule:
+		granule := s.granule()
+		start := t.Truncate(granule)
+		next := s.Next(start.Add(-time.Nanosecond))
+		return !next.IsZero() && next.Before(start.Add(granule))
+	case UnionSchedule:
+		for _, operand := range s.Schedules {
+			if covers(operand, t) {
+				return true
+			}
+		}
+		return false
+	case IntersectSchedule:
+		return covers(s.A, t) && covers(s.B, t)
+	}
+	return s.Next(t.Add(-time.Nanosecond)).Equal(t)
+}
+
+// String returns the specs of the schedule and the blackout joined by "&^".
+func (schedule ExceptSchedule) String() string {
+	return operandString(schedule.Schedule, false) + " &^ " + operandString(schedule.Blackout, true)
+}
+
+// Describe describes the schedule and the blackout, e.g. "Every 10 minutes,
+// except every minute, between 02:00 and 02:59".
+func (schedule ExceptSchedule) Describe(lang Language) string {
+	return lang.describeOperand(schedule.Schedule, false) + lang.pick(", except ", "，但不包括") +
+		lang.describeOperand(schedule.Blackout, true)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning the spec.
+func (schedule ExceptSchedule) MarshalText() ([]byte, error) {
+	return []byte(schedule.String()), nil
+}
+
+// operandString returns the spec of an operand of "&" or "&^", in
+// parentheses if it would otherwise be split differently.  Unions bind less
+// tightly than the other operators, which group to the left.
+func operandString(s Schedule, right bool) string {
+	switch s.(type) {
+	case UnionSchedule:
+		return "(" + fmt.Sprint(s) + ")"
+	case IntersectSchedule, ExceptSchedule:
+		if right {
+			return "(" + fmt.Sprint(s) + ")"
+		}
+	}
+	return fmt.Sprint(s)
+}
+
+// describeOperand describes a schedule combined with others, or returns its
+// spec if the language cannot describe it.  English descriptions following
+// another are not capitalized.
+func (lang Language) describeOperand(s Schedule, following bool) string {
+	description := lang.Describe(s)
+	if description == "" {
+		description = fmt.Sprint(s)
+	}
+	if lang == English && following && len(description) > 1 && strings.ToUpper(description[1:2]) != description[1:2] {
+		description = strings.ToLower(description[:1]) + description[1:]
+	}
+	return description
+}
+
+// isComposite returns true if the spec combines schedules with operators, or
+// groups them in parentheses.
+func isComposite(spec string) bool {
+	return strings.ContainsAny(spec, "|&") || strings.HasPrefix(strings.TrimSpace(spec), "(")
+}
+
+// parseComposite returns the schedule for a spec combining the specs of
+// other schedules, each parsed by the given function:
+//
+//	union     = intersect { "|" intersect }
+//	intersect = operand { ("&" | "&^") operand }
+//	operand   = "(" union ")" | spec
+func parseComposite(spec string, parse func(string) (Schedule, error)) (Schedule, error) {
+	p := compositeParser{spec: spec, parse: parse}
+	s, err := p.union()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); p.pos < len(spec) {
+		return nil, p.errorf("unexpected %q in spec: %s", spec[p.pos], spec)
+	}
+	return s, nil
+}
+
+// compositeParser parses a composite spec from left to right.
+type compositeParser struct {
+	spec  string
+	pos   int
+	parse func(string) (Schedule, error)
+}
+
+func (p *compositeParser) union() (Schedule, error) {
+	s, err := p.intersect()
+	if err != nil {
+		return nil, err
+	}
+	schedules := []Schedule{s}
+	for p.skipSpace(); p.consume("|"); p.skipSpace() {
+		if s, err = p.intersect(); err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, s)
+	}
+	if len(schedules) == 1 {
+		return s, nil
+	}
+	return Union(schedules...), nil
+}
+
+func (p *compositeParser) intersect() (Schedule, error) {
+	s, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		switch {
+		case p.consume("&^"):
+			blackout, err := p.operand()
+			if err != nil {
+				return nil, err
+			}
+			s = Except(s, blackout)
+		case p.consume("&"):
+			other, err := p.operand()
+			if err != nil {
+				return nil, err
+			}
+			s = Intersect(s, other)
+		default:
+			return s, nil
+		}
+	}
+}
+
+func (p *compositeParser) operand() (Schedule, error) {
+	p.skipSpace()
+	if p.consume("(") {
+		s, err := p.union()
+		if err != nil {
+			return nil, err
+		}
+		if p.skipSpace(); !p.consume(")") {
+			return nil, p.errorf("missing closing parenthesis: %s", p.spec)
+		}
+		return s, nil
+	}
+
+	// The operand ends at the next operator, or at the parenthesis closing a
+	// group.  Parentheses within it, as in "H(0-29)", are its own.
+	start, nested := p.pos, 0
+SCAN:
+	for ; p.pos < len(p.spec); p.pos++ {
+		switch p.spec[p.pos] {
+		case '|', '&':
+			break SCAN
+		case '(':
+			nested++
+		case ')':
+			if nested == 0 {
+				break SCAN
+			}
+			nested--
+		}
+	}
+	operand := strings.TrimSpace(p.spec[start:p.pos])
+	if operand == "" {
+		p.pos = start
+		return nil, p.errorf("expected a schedule: %s", p.spec)
+	}
+	s, err := p.parse(operand)
+	if perr, ok := err.(*ParseError); ok {
+		// Locate the error within the whole spec.
+		perr.Spec = p.spec
+		perr.Offset += start
+	}
+	return s, err
+}
+
+// skipSpace advances past any whitespace.
+func (p *compositeParser) skipSpace() {
+	p.pos = len(p.spec) - len(strings.TrimLeft(p.spec[p.pos:], " \t\n"))
+}
+
+// consume advances past the given operator, if it is next.
+func (p *compositeParser) consume(op string) bool {
+	if !strings.HasPrefix(p.spec[p.pos:], op) {
+		return false
+	}
+	p.pos += len(op)
+	return true
+}
+
+// errorf returns a ParseError for the character at the current position.
+func (p *compositeParser) errorf(format string, args ...interface{}) error {
+	end := p.pos + 1
+	if end > len(p.spec) {
+		end = len(p.spec)
+	}
+	return locate(parseErrorf(ReasonSyntax, "", format, args...), p.spec, FieldSpec, p.pos, end)
+}
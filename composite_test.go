@@ -0,0 +1,233 @@
+package cron
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCompositeNext(t *testing.T) {
+	tests := []struct {
+		spec, time string
+		expected   []string
+	}{
+		{"0 9 * * 1-5 | 0 12 * * 6", "Fri Jul 13 10:00 2012",
+			[]string{"Sat Jul 14 12:00 2012", "Mon Jul 16 09:00 2012", "Tue Jul 17 09:00 2012"}},
+		{"*/20 * * * * &^ * 2 * * *", "Tue Jul 10 01:30 2012",
+			[]string{"Tue Jul 10 01:40 2012", "Tue Jul 10 03:00 2012", "Tue Jul 10 03:20 2012"}},
+		{"(H(0-0) 9 * * 1-5) | 0 12 * * 6", "Fri Jul 13 10:00 2012",
+			[]string{"Sat Jul 14 12:00 2012", "Mon Jul 16 09:00 2012", "Tue Jul 17 09:00 2012"}},
+		{"0 9 * * 1-5 & 0 9 1-7 * *", "Mon Jul 9 00:00 2012",
+			[]string{"Wed Aug 1 09:00 2012", "Thu Aug 2 09:00 2012", "Fri Aug 3 09:00 2012", "Mon Aug 6 09:00 2012"}},
+		{"(0 9 * * 1 | 0 9 * * 5) &^ 0 9 13 * *", "Thu Jul 12 00:00 2012",
+			[]string{"Mon Jul 16 09:00 2012", "Fri Jul 20 09:00 2012"}},
+		{"0 9 * * 1 | 0 9 * * 5 &^ 0 9 13 * *", "Thu Jul 12 00:00 2012",
+			[]string{"Mon Jul 16 09:00 2012", "Fri Jul 20 09:00 2012"}},
+		{"@hourly | @every 1h from 2012-07-09T00:30:00Z", "TZ=UTC 2012-07-09T00:00:00-0000",
+			[]string{"2012-07-09T00:30:00-0000", "2012-07-09T01:00:00-0000", "2012-07-09T01:30:00-0000"}},
+
+		// A leading time zone applies to each operand without its own.
+		{"TZ=Asia/Tokyo 0 9 * * 1-5 | 0 12 * * 6", "TZ=UTC 2012-07-13T12:00:00-0000",
+			[]string{"2012-07-14T03:00:00-0000", "2012-07-16T00:00:00-0000"}},
+		{"TZ=Asia/Tokyo (0 9 * * 1 | 0 12 * * 6) &^ TZ=UTC 0 0 16 * *", "TZ=UTC 2012-07-13T12:00:00-0000",
+			[]string{"2012-07-14T03:00:00-0000", "2012-07-21T03:00:00-0000", "2012-07-23T00:00:00-0000"}},
+
+		// Activations within those of a spec coincide with them.
+		{"@every 10m &^ * 2 * * *", "Tue Jul 10 01:53:07 2012",
+			[]string{"Tue Jul 10 03:03:07 2012", "Tue Jul 10 03:13:07 2012"}},
+		{"@every 10m &^ (* 2 * * * | * 4 * * *)", "Tue Jul 10 01:53:07 2012",
+			[]string{"Tue Jul 10 03:03:07 2012", "Tue Jul 10 03:13:07 2012"}},
+		{"@every 10m & * 2 * * *", "Tue Jul 10 01:53:07 2012",
+			[]string{"Tue Jul 10 02:03:07 2012", "Tue Jul 10 02:13:07 2012"}},
+		{"* 2 * * * & @every 10m", "Tue Jul 10 01:53:07 2012",
+			[]string{"Tue Jul 10 02:03:07 2012", "Tue Jul 10 02:13:07 2012"}},
+		{"* * * * * & 0 9 1 1 *", "Mon Jul 9 00:00 2012",
+			[]string{"Tue Jan 1 09:00 2013", "Wed Jan 1 09:00 2014"}},
+
+		// Unsatisfiable
+		{"0 9 * * 1 & 0 9 * * 2", "Mon Jul 9 00:00 2012", nil},
+		{"0 9 * * * &^ * * * * *", "Mon Jul 9 00:00 2012", nil},
+	}
+
+	for _, c := range tests {
+		sched, err := ParseStandard(c.spec)
+		if err != nil {
+			t.Errorf("%s: %v", c.spec, err)
+			continue
+		}
+		n := len(c.expected)
+		if n == 0 {
+			n = 1
+		}
+		assertTimes(t, c.spec, NextN(sched, getTime(c.time), n), c.expected)
+	}
+}
+
+func TestCompositeSubSecond(t *testing.T) {
+	milliseconds := NewParser(Millisecond | Second | Minute | Hour | Dom | Month | Dow)
+	halfSeconds, _ := milliseconds.Parse("0,500 * * * * * *")
+	minutely, _ := ParseStandard("* * * * *")
+	jittered := HashedJitter(minutely, 30*time.Second, "report")
+
+	tests := []struct {
+		name     string
+		schedule Schedule
+		expected []string
+	}{
+		{"Intervals", Intersect(Interval(300*time.Millisecond), Interval(500*time.Millisecond)),
+			[]string{"Mon Jul 9 14:45:01.5 2012", "Mon Jul 9 14:45:03 2012", "Mon Jul 9 14:45:04.5 2012"}},
+		{"Milliseconds", Except(Interval(250*time.Millisecond), halfSeconds),
+			[]string{"Mon Jul 9 14:45:00.25 2012", "Mon Jul 9 14:45:00.75 2012", "Mon Jul 9 14:45:01.25 2012", "Mon Jul 9 14:45:01.75 2012"}},
+		{"Jitter", Intersect(Interval(500*time.Millisecond), jittered),
+			[]string{"Mon Jul 9 14:46:02 2012", "Mon Jul 9 14:47:22 2012", "Mon Jul 9 14:48:26 2012"}},
+	}
+
+	for _, c := range tests {
+		n := len(c.expected)
+		if n == 0 {
+			n = 1
+		}
+		assertTimes(t, c.name, NextN(c.schedule, getTime("Mon Jul 9 14:45 2012"), n), c.expected)
+	}
+}
+
+func TestCompositeSteps(t *testing.T) {
+	sched, err := secondParser.Parse("* * * * * * &^ * * * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	if next := sched.Next(getTime("Mon Jul 9 14:45 2012")); !next.IsZero() {
+		t.Errorf("expected no activation, got %v", next)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected to give up within a second, took %v", elapsed)
+	}
+}
+
+func TestCompositeConstructors(t *testing.T) {
+	weekdays, _ := ParseStandard("0 9 * * 1-5")
+	saturdays, _ := ParseStandard("0 12 * * 6")
+	window, _ := ParseStandard("* 2 * * *")
+
+	var (
+		now      = getTime("Mon Jul 9 01:55 2012")
+		union    = Union(weekdays, saturdays)
+		except   = Except(Every(10*time.Minute), window)
+		expected = []string{"Mon Jul 9 03:05 2012", "Mon Jul 9 03:15 2012"}
+	)
+	if next := union.Next(now); !next.Equal(getTime("Mon Jul 9 09:00 2012")) {
+		t.Errorf("expected Mon Jul 9 09:00 2012, got %v", next)
+	}
+	assertTimes(t, "except", NextN(except, now, 2), expected)
+
+	// Unions of @reboot start with it.
+	reboot := Union(RebootSchedule{Delay: time.Minute}, weekdays)
+	if start := first(reboot, now); !start.Equal(now.Add(time.Minute)) {
+		t.Errorf("expected %v, got %v", now.Add(time.Minute), start)
+	}
+	if next := reboot.Next(now); !next.Equal(getTime("Mon Jul 9 09:00 2012")) {
+		t.Errorf("expected Mon Jul 9 09:00 2012, got %v", next)
+	}
+}
+
+func TestCompositeString(t *testing.T) {
+	tests := []struct {
+		spec, expected string
+	}{
+		{"0 9 * * 1-5 | 0 12 * * 6", "0 9 * * mon-fri | 0 12 * * sat"},
+		{"*/10 * * * * &^ * 2 * * *", "*/10 * * * * &^ * 2 * * *"},
+		{"(0 9 * * 1|0 9 * * 5)&(0 9 1-7 * *)", "(0 9 * * mon | 0 9 * * fri) & 0 9 1-7 * *"},
+		{"0 9 * * * &^ (0 9 * * 6 & 0 9 1-7 * *)", "0 9 * * * &^ (0 9 * * sat & 0 9 1-7 * *)"},
+		{"0 9 * * * & 0 9 * * 1-5 &^ 0 9 25 12 *", "0 9 * * * & 0 9 * * mon-fri &^ 0 9 25 dec *"},
+		{"TZ=UTC 0 9 * * * | @reboot", "TZ=UTC 0 9 * * * | @reboot"},
+		{"TZ=Asia/Tokyo 0 9 * * 1-5 | 0 12 * * 6", "TZ=Asia/Tokyo 0 9 * * mon-fri | TZ=Asia/Tokyo 0 12 * * sat"},
+		{"CRON_TZ=UTC 0 9 * * * | TZ=Asia/Tokyo 0 12 * * *", "TZ=UTC 0 9 * * * | TZ=Asia/Tokyo 0 12 * * *"},
+	}
+
+	for _, c := range tests {
+		sched, err := ParseStandard(c.spec)
+		if err != nil {
+			t.Errorf("%s: %v", c.spec, err)
+			continue
+		}
+		if actual := sched.(interface{ String() string }).String(); actual != c.expected {
+			t.Errorf("%s: expected %q, got %q", c.spec, c.expected, actual)
+		}
+
+		// Entries encode the combination, and decode it again.
+		data, err := json.Marshal(Entry{Schedule: sched})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var entry Entry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			t.Errorf("%s: %v", data, err)
+			continue
+		}
+		if !reflect.DeepEqual(entry.Schedule, sched) {
+			t.Errorf("%s: expected %v, got %v", data, sched, entry.Schedule)
+		}
+	}
+}
+
+func TestCompositeDescribe(t *testing.T) {
+	tests := []struct {
+		spec, en, zh string
+	}{
+		{"0 9 * * 1-5 | 0 12 * * 6",
+			"At 09:00 on Monday through Friday, or at 12:00 on Saturday",
+			"每周一至周五 09:00，或每周六 12:00"},
+		{"0 9 * * 1-5 & 0 9 1-7 * *",
+			"At 09:00 on Monday through Friday, if also at 09:00 on days 1 through 7 of the month",
+			"每周一至周五 09:00，且每月1日至7日 09:00"},
+	}
+
+	for _, c := range tests {
+		s, err := ParseStandard(c.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual := Describe(s); actual != c.en {
+			t.Errorf("%s: expected %q, got %q", c.spec, c.en, actual)
+		}
+		if actual := Chinese.Describe(s); actual != c.zh {
+			t.Errorf("%s: expected %q, got %q", c.spec, c.zh, actual)
+		}
+	}
+}
+
+func TestCompositeParseErrors(t *testing.T) {
+	tests := []struct {
+		spec           string
+		field          SpecField
+		offset, length int
+		reason         ParseErrorReason
+	}{
+		{"0 9 * * * |", FieldSpec, 11, 0, ReasonSyntax},
+		{"| 0 9 * * *", FieldSpec, 0, 1, ReasonSyntax},
+		{"(0 9 * * *", FieldSpec, 10, 0, ReasonSyntax},
+		{"0 9 * * * | 0 9 * * *)", FieldSpec, 21, 1, ReasonSyntax},
+		{"0 9 * * * | 0 25 * * *", FieldHour, 14, 2, ReasonAboveMaximum},
+		{"0 9 * * * & (0 9 * * 1 | 0 9 * *)", FieldSpec, 25, 7, ReasonFieldCount},
+		{"0 9 * * * &^ TZ=Mars/Olympus 0 9 * * *", FieldTimezone, 16, 12, ReasonLocation},
+		{"TZ=Mars/Olympus 0 9 * * * | 0 12 * * *", FieldTimezone, 3, 12, ReasonLocation},
+		{"TZ=UTC  0 9 * * * | 0 25 * * *", FieldHour, 22, 2, ReasonAboveMaximum},
+		{"TZ=UTC 0 9 * * * | (0 9 * * *", FieldSpec, 29, 0, ReasonSyntax},
+	}
+
+	for _, c := range tests {
+		_, err := ParseStandard(c.spec)
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("%q: expected a ParseError, got %v", c.spec, err)
+			continue
+		}
+		if perr.Spec != c.spec || perr.Field != c.field || perr.Offset != c.offset || perr.Length != c.length || perr.Reason != c.reason {
+			t.Errorf("%q: expected %s at %d+%d for %s, got %q %s at %d+%d for %s: %v", c.spec,
+				c.field, c.offset, c.length, c.reason, perr.Spec, perr.Field, perr.Offset, perr.Length, perr.Reason, perr)
+		}
+	}
+}
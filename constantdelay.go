@@ -1,16 +1,23 @@
 package cron
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 // ConstantDelaySchedule represents a simple recurring duty cycle, e.g. "Every 5 minutes".
-// It does not support jobs more frequent than once a second.
+// It does not support jobs more frequent than once a second.  Its activations
+// are counted from the start of each run; see FixedDelaySchedule for delays
+// counted from their completion.
 type ConstantDelaySchedule struct {
 	Delay time.Duration
 }
 
-// Every returns a crontab Schedule that activates once every duration.
-// Delays of less than a second are not supported (will round up to 1 second).
-// Any fields less than a Second are truncated.
+// Every returns a crontab Schedule that activates once every duration, as the
+// "@every" descriptor does.  Delays of less than a second are not supported
+// (will round up to 1 second).  Any fields less than a Second are truncated;
+// see Interval for schedules with fractions of a second.
 func Every(duration time.Duration) ConstantDelaySchedule {
 	if duration < time.Second {
 		duration = time.Second
@@ -25,3 +32,295 @@ func Every(duration time.Duration) ConstantDelaySchedule {
 func (schedule ConstantDelaySchedule) Next(t time.Time) time.Time {
 	return t.Add(schedule.Delay - time.Duration(t.Nanosecond())*time.Nanosecond)
 }
+
+// Prev returns the time this would have run before, so that Next of it is
+// the given time.  This rounds so that the activation time will be on the
+// second.
+func (schedule ConstantDelaySchedule) Prev(t time.Time) time.Time {
+	return t.Add(-schedule.Delay - time.Duration(t.Nanosecond())*time.Nanosecond)
+}
+
+// String returns the "@every" descriptor for the schedule, e.g. "@every 5m".
+func (schedule ConstantDelaySchedule) String() string {
+	return "@every " + shortDuration(schedule.Delay)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning the descriptor.
+func (schedule ConstantDelaySchedule) MarshalText() ([]byte, error) {
+	return []byte(schedule.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, accepting "@every"
+// descriptors without options.
+func (schedule *ConstantDelaySchedule) UnmarshalText(text []byte) error {
+	s, err := parseText(string(text))
+	if err != nil {
+		return err
+	}
+	delay, ok := s.(ConstantDelaySchedule)
+	if !ok {
+		return fmt.Errorf("not a constant delay: %s", text)
+	}
+	*schedule = delay
+	return nil
+}
+
+// FixedDelaySchedule activates once every Delay, counted from the completion
+// of the previous run rather than from its start, e.g. "5 minutes after the
+// last run finished".  Unlike ConstantDelaySchedule its runs never overlap,
+// however long they take.  Cron asks it for the next activation only once a
+// run has completed.
+type FixedDelaySchedule struct {
+	Delay time.Duration
+}
+
+// FixedDelay returns a schedule that activates the given duration after each
+// run completes.  Delays of less than a second are not supported (will round
+// up to 1 second).
+func FixedDelay(duration time.Duration) FixedDelaySchedule {
+	if duration < time.Second {
+		duration = time.Second
+	}
+	return FixedDelaySchedule{Delay: duration}
+}
+
+// Next returns the activation following a run completed at the given time.
+func (schedule FixedDelaySchedule) Next(t time.Time) time.Time {
+	return t.Add(schedule.Delay)
+}
+
+// AfterCompletion returns true: the delay counts from the completion of each
+// run.
+func (schedule FixedDelaySchedule) AfterCompletion() bool {
+	return true
+}
+
+// String returns the "@every ... after-completion" descriptor for the
+// schedule, e.g. "@every 5m after-completion".
+func (schedule FixedDelaySchedule) String() string {
+	return "@every " + shortDuration(schedule.Delay) + " after-completion"
+}
+
+// MarshalText implements encoding.TextMarshaler, returning the descriptor.
+func (schedule FixedDelaySchedule) MarshalText() ([]byte, error) {
+	return []byte(schedule.String()), nil
+}
+
+// IntervalSchedule activates at every multiple of Interval since the Unix
+// epoch, e.g. at .000, .250, .500 and .750 of each second for an interval of
+// 250ms.  Unlike ConstantDelaySchedule it supports intervals of less than a
+// second, and its activations do not drift with the time the scheduler takes
+// to wake up.
+type IntervalSchedule struct {
+	Interval time.Duration
+}
+
+// Interval returns a schedule that activates at every multiple of the given
+// interval, as the "@interval" descriptor does.  Intervals of less than a
+// millisecond are not supported (will round up to 1 millisecond).
+func Interval(interval time.Duration) IntervalSchedule {
+	if interval < time.Millisecond {
+		interval = time.Millisecond
+	}
+	return IntervalSchedule{Interval: interval}
+}
+
+// Next returns the first multiple of the interval after the given time.
+func (schedule IntervalSchedule) Next(t time.Time) time.Time {
+	n := t.UnixNano()/int64(schedule.Interval) + 1
+	return time.Unix(0, n*int64(schedule.Interval)).In(t.Location())
+}
+
+// Prev returns the last multiple of the interval before the given time.
+func (schedule IntervalSchedule) Prev(t time.Time) time.Time {
+	n := (t.UnixNano() - 1) / int64(schedule.Interval)
+	return time.Unix(0, n*int64(schedule.Interval)).In(t.Location())
+}
+
+// String returns the "@interval" descriptor for the schedule, e.g.
+// "@interval 250ms".
+func (schedule IntervalSchedule) String() string {
+	return "@interval " + shortDuration(schedule.Interval)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning the descriptor.
+func (schedule IntervalSchedule) MarshalText() ([]byte, error) {
+	return []byte(schedule.String()), nil
+}
+
+// shortDuration formats the duration without trailing zero units, e.g. "1h"
+// rather than "1h0m0s".
+func shortDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = s[:len(s)-2]
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = s[:len(s)-2]
+	}
+	return s
+}
+
+// AlignedSchedule activates once every Interval, aligned to the wall clock of
+// its location, e.g. every 15 minutes at :00, :15, :30 and :45.  The interval
+// must divide a day evenly; the alignment restarts at each midnight.
+type AlignedSchedule struct {
+	Interval time.Duration
+
+	// Location in which the wall clock is read.  As with SpecSchedule,
+	// time.Local means the location of the time given to Next.
+	Location *time.Location
+}
+
+// Next returns the first aligned time after the given time.
+func (schedule AlignedSchedule) Next(t time.Time) time.Time {
+	origLocation := t.Location()
+	loc := schedule.Location
+	if loc == time.Local {
+		loc = t.Location()
+	}
+	t = t.In(loc)
+
+	var (
+		year, month, day = t.Date()
+		elapsed          = time.Duration(t.Hour())*time.Hour +
+			time.Duration(t.Minute())*time.Minute +
+			time.Duration(t.Second())*time.Second +
+			time.Duration(t.Nanosecond())
+	)
+	for offset := (elapsed/schedule.Interval + 1) * schedule.Interval; ; offset += schedule.Interval {
+		if offset >= 24*time.Hour {
+			day++
+			offset = 0
+		}
+		// Build the time from the wall clock, so that days with daylight
+		// savings transitions stay aligned.
+		next := time.Date(year, month, day, 0, 0, 0, int(offset), loc)
+		if next.After(t) {
+			return next.In(origLocation)
+		}
+	}
+}
+
+// Prev returns the last aligned time before the given time.
+func (schedule AlignedSchedule) Prev(t time.Time) time.Time {
+	origLocation := t.Location()
+	loc := schedule.Location
+	if loc == time.Local {
+		loc = t.Location()
+	}
+	t = t.In(loc)
+
+	var (
+		year, month, day = t.Date()
+		elapsed          = time.Duration(t.Hour())*time.Hour +
+			time.Duration(t.Minute())*time.Minute +
+			time.Duration(t.Second())*time.Second +
+			time.Duration(t.Nanosecond())
+	)
+	for offset := (elapsed+schedule.Interval-1)/schedule.Interval*schedule.Interval - schedule.Interval; ; offset -= schedule.Interval {
+		if offset < 0 {
+			day--
+			offset = 24*time.Hour - schedule.Interval
+		}
+		prev := time.Date(year, month, day, 0, 0, 0, int(offset), loc)
+		if prev.Before(t) {
+			return prev.In(origLocation)
+		}
+	}
+}
+
+// String returns the "@every ... aligned" descriptor for the schedule.
+func (schedule AlignedSchedule) String() string {
+	return locationPrefix(schedule.Location) + "@every " + shortDuration(schedule.Interval) + " aligned"
+}
+
+// MarshalText implements encoding.TextMarshaler, returning the descriptor.
+func (schedule AlignedSchedule) MarshalText() ([]byte, error) {
+	return []byte(schedule.String()), nil
+}
+
+// AnchoredSchedule activates every Interval, at Anchor plus a whole number of
+// intervals, so that its activations do not depend on when it is started.
+type AnchoredSchedule struct {
+	Interval time.Duration
+	Anchor   time.Time
+
+	// Location in which the wall clock of Anchor is read, if not nil, e.g.
+	// for an anchor given without an offset.  As with SpecSchedule,
+	// time.Local means the location of the time given to Next, which for
+	// Cron is its location.  If nil, Anchor is an instant.
+	Location *time.Location
+}
+
+// Next returns the first activation after the given time, which is the
+// anchor itself if the given time is before it.
+func (schedule AnchoredSchedule) Next(t time.Time) time.Time {
+	anchor := schedule.anchor(t)
+	if t.Before(anchor) {
+		return anchor.In(t.Location())
+	}
+	n := t.Sub(anchor)/schedule.Interval + 1
+	return anchor.Add(n * schedule.Interval).In(t.Location())
+}
+
+// Prev returns the last activation before the given time, or the zero time
+// if the given time is not after the anchor.
+func (schedule AnchoredSchedule) Prev(t time.Time) time.Time {
+	anchor := schedule.anchor(t)
+	if !t.After(anchor) {
+		return time.Time{}
+	}
+	n := (t.Sub(anchor) - 1) / schedule.Interval
+	return anchor.Add(n * schedule.Interval).In(t.Location())
+}
+
+// anchor returns the anchor as an instant, for the given time.
+func (schedule AnchoredSchedule) anchor(t time.Time) time.Time {
+	return wallTime(schedule.Anchor, schedule.Location, t)
+}
+
+// wallTime returns the instant at which the wall clock of the given time
+// reads the same in the given location, or in that of t if it is
+// time.Local.  If the location is nil, the given time is returned as is.
+func wallTime(wall time.Time, loc *time.Location, t time.Time) time.Time {
+	if loc == nil {
+		return wall
+	}
+	if loc == time.Local {
+		loc = t.Location()
+	}
+	year, month, day := wall.Date()
+	hour, min, sec := wall.Clock()
+	return time.Date(year, month, day, hour, min, sec, wall.Nanosecond(), loc)
+}
+
+// String returns the "@every ... from" descriptor for the schedule, with the
+// anchor in RFC 3339 format, or as a wall clock time if it has a location.
+func (schedule AnchoredSchedule) String() string {
+	return locationPrefix(schedule.Location) + "@every " + shortDuration(schedule.Interval) +
+		" from " + wallString(schedule.Anchor, schedule.Location)
+}
+
+// wallString formats the given time in RFC 3339 format, or without an offset
+// if it is a wall clock time in the given location.
+func wallString(t time.Time, loc *time.Location) string {
+	if loc != nil {
+		return t.Format("2006-01-02T15:04:05")
+	}
+	return t.Format(time.RFC3339)
+}
+
+// locationPrefix returns the "TZ=" prefix of the spec of a schedule in the
+// given location, if it is neither nil nor time.Local.
+func locationPrefix(loc *time.Location) string {
+	if loc == nil || loc == time.Local {
+		return ""
+	}
+	return "TZ=" + loc.String() + " "
+}
+
+// MarshalText implements encoding.TextMarshaler, returning the descriptor.
+func (schedule AnchoredSchedule) MarshalText() ([]byte, error) {
+	return []byte(schedule.String()), nil
+}
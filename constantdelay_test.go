@@ -1,6 +1,9 @@
 package cron
 
 import (
+	"encoding/json"
+	"fmt"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -52,3 +55,239 @@ func TestConstantDelayNext(t *testing.T) {
 		}
 	}
 }
+
+func TestConstantDelayString(t *testing.T) {
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	tests := []struct {
+		schedule Schedule
+		expected string
+	}{
+		{Every(5 * time.Minute), "@every 5m"},
+		{Every(time.Hour), "@every 1h"},
+		{Every(90*time.Minute + 10*time.Second), "@every 1h30m10s"},
+		{Every(36 * time.Hour), "@every 36h"},
+		{FixedDelay(5 * time.Minute), "@every 5m after-completion"},
+		{AlignedSchedule{15 * time.Minute, time.Local}, "@every 15m aligned"},
+		{AlignedSchedule{15 * time.Minute, time.UTC}, "TZ=UTC @every 15m aligned"},
+		{AnchoredSchedule{36 * time.Hour, time.Date(2026, 1, 1, 9, 30, 0, 0, time.UTC), nil}, "@every 36h from 2026-01-01T09:30:00Z"},
+		{AnchoredSchedule{36 * time.Hour, time.Date(2026, 1, 1, 9, 30, 0, 0, time.UTC), time.Local}, "@every 36h from 2026-01-01T09:30:00"},
+		{AnchoredSchedule{36 * time.Hour, time.Date(2026, 1, 1, 9, 30, 0, 0, time.UTC), tokyo}, "TZ=Asia/Tokyo @every 36h from 2026-01-01T09:30:00"},
+	}
+
+	for _, c := range tests {
+		if actual := c.schedule.(fmt.Stringer).String(); actual != c.expected {
+			t.Errorf("expected %q, got %q", c.expected, actual)
+		}
+		again, err := parseText(c.expected)
+		if err != nil {
+			t.Errorf("%s: %v", c.expected, err)
+			continue
+		}
+		if anchored, ok := again.(AnchoredSchedule); ok {
+			expected := c.schedule.(AnchoredSchedule)
+			if !anchored.Anchor.Equal(expected.Anchor) || fmt.Sprint(anchored.Location) != fmt.Sprint(expected.Location) {
+				t.Errorf("%s: expected %v, got %v", c.expected, c.schedule, again)
+			}
+		} else if !reflect.DeepEqual(again, c.schedule) {
+			t.Errorf("%s: expected %v, got %v", c.expected, c.schedule, again)
+		}
+	}
+
+	var delay ConstantDelaySchedule
+	if err := json.Unmarshal([]byte(`"@every 1h30m"`), &delay); err != nil {
+		t.Error(err)
+	}
+	if delay.Delay != 90*time.Minute {
+		t.Errorf("expected 1h30m, got %v", delay.Delay)
+	}
+	if err := json.Unmarshal([]byte(`"@daily"`), &delay); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestConstantDelayPrev(t *testing.T) {
+	tests := []struct {
+		time     string
+		delay    time.Duration
+		expected string
+	}{
+		{"Mon Jul 9 14:45 2012", 15*time.Minute + 50*time.Nanosecond, "Mon Jul 9 14:30 2012"},
+		{"Mon Jul 9 00:10 2012", 15 * time.Minute, "Sun Jul 8 23:55 2012"},
+		{"Mon Jul 9 14:45:00.5 2012", 15 * time.Minute, "Mon Jul 9 14:30 2012"},
+	}
+
+	for _, c := range tests {
+		schedule := Every(c.delay)
+		actual := schedule.Prev(getTime(c.time))
+		expected := getTime(c.expected)
+		if !actual.Equal(expected) {
+			t.Errorf("%s, \"%s\": (expected) %v != %v (actual)", c.time, c.delay, expected, actual)
+		}
+		if next := schedule.Next(actual); !next.Equal(getTime(c.time).Truncate(time.Second)) {
+			t.Errorf("%s, \"%s\": expected Next to return to the given time, got %v", c.time, c.delay, next)
+		}
+	}
+}
+
+func TestIntervalNext(t *testing.T) {
+	tests := []struct {
+		time     string
+		interval time.Duration
+		expected string
+	}{
+		{"Mon Jul 9 14:45 2012", 250 * time.Millisecond, "Mon Jul 9 14:45:00.25 2012"},
+		{"Mon Jul 9 14:45:00.3 2012", 250 * time.Millisecond, "Mon Jul 9 14:45:00.5 2012"},
+		{"Mon Jul 9 14:45:00.999 2012", 100 * time.Millisecond, "Mon Jul 9 14:45:01 2012"},
+		{"Mon Jul 9 14:45:00.0005 2012", 0, "Mon Jul 9 14:45:00.001 2012"},
+		{"Mon Jul 9 14:45:01 2012", 1500 * time.Millisecond, "Mon Jul 9 14:45:01.5 2012"},
+	}
+
+	for _, c := range tests {
+		schedule := Interval(c.interval)
+		if actual, expected := schedule.Next(getTime(c.time)), getTime(c.expected); !actual.Equal(expected) {
+			t.Errorf("%s, %s: (expected) %v != %v (actual)", c.time, c.interval, expected, actual)
+		}
+		if actual := schedule.Prev(schedule.Next(getTime(c.time))); actual.After(getTime(c.time)) {
+			t.Errorf("%s, %s: expected Prev to go back before the given time, got %v", c.time, c.interval, actual)
+		}
+	}
+}
+
+func TestIntervalString(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected Schedule
+	}{
+		{"@interval 250ms", Interval(250 * time.Millisecond)},
+		{"@interval 1.5s", Interval(1500 * time.Millisecond)},
+		{"@interval 2s", Interval(2 * time.Second)},
+		{"@every 2s", Every(2 * time.Second)},
+	}
+	for _, c := range tests {
+		sched, err := ParseStandard(c.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sched != c.expected {
+			t.Errorf("%s: expected %#v, got %#v", c.spec, c.expected, sched)
+		}
+		if actual := fmt.Sprint(sched); actual != c.spec {
+			t.Errorf("%s: expected the spec back, got %q", c.spec, actual)
+		}
+	}
+	if actual := Describe(Interval(100 * time.Millisecond)); actual != "Every 100 milliseconds" {
+		t.Errorf("unexpected description %q", actual)
+	}
+}
+
+func TestAlignedNext(t *testing.T) {
+	tests := []struct {
+		time     string
+		interval time.Duration
+		expected string
+	}{
+		// Simple cases
+		{"Mon Jul 9 14:45 2012", 15 * time.Minute, "Mon Jul 9 15:00 2012"},
+		{"Mon Jul 9 14:59 2012", 15 * time.Minute, "Mon Jul 9 15:00 2012"},
+		{"Mon Jul 9 14:44:59.5 2012", 15 * time.Minute, "Mon Jul 9 14:45 2012"},
+		{"Mon Jul 9 14:07:21 2012", 5 * time.Second, "Mon Jul 9 14:07:25 2012"},
+		{"Mon Jul 9 14:07 2012", 8 * time.Hour, "Mon Jul 9 16:00 2012"},
+
+		// Wrap around days
+		{"Mon Jul 9 23:46 2012", 15 * time.Minute, "Tue Jul 10 00:00 2012"},
+		{"Mon Jul 9 16:00 2012", 8 * time.Hour, "Tue Jul 10 00:00 2012"},
+		{"Mon Jul 9 14:07 2012", 24 * time.Hour, "Tue Jul 10 00:00 2012"},
+
+		// Daylight savings time 2am EST (-5) -> 3am EDT (-4)
+		{"TZ=America/New_York 2012-03-11T01:00:00-0500", 3 * time.Hour, "TZ=America/New_York 2012-03-11T03:00:00-0400"},
+		{"TZ=America/New_York 2012-03-11T03:00:00-0400", 3 * time.Hour, "TZ=America/New_York 2012-03-11T06:00:00-0400"},
+	}
+
+	for _, c := range tests {
+		actual := AlignedSchedule{Interval: c.interval, Location: time.Local}.Next(getTime(c.time))
+		expected := getTime(c.expected)
+		if !actual.Equal(expected) {
+			t.Errorf("%s, \"%s\": (expected) %v != %v (actual)", c.time, c.interval, expected, actual)
+		}
+	}
+}
+
+func TestAlignedPrev(t *testing.T) {
+	tests := []struct {
+		time     string
+		interval time.Duration
+		expected string
+	}{
+		{"Mon Jul 9 14:45 2012", 15 * time.Minute, "Mon Jul 9 14:30 2012"},
+		{"Mon Jul 9 14:59 2012", 15 * time.Minute, "Mon Jul 9 14:45 2012"},
+		{"Mon Jul 9 14:45:00.5 2012", 15 * time.Minute, "Mon Jul 9 14:45 2012"},
+		{"Mon Jul 9 00:00 2012", 15 * time.Minute, "Sun Jul 8 23:45 2012"},
+		{"Mon Jul 9 07:59 2012", 8 * time.Hour, "Mon Jul 9 00:00 2012"},
+
+		// Daylight savings time 2am EST (-5) -> 3am EDT (-4)
+		{"TZ=America/New_York 2012-03-11T06:00:00-0400", 3 * time.Hour, "TZ=America/New_York 2012-03-11T03:00:00-0400"},
+		{"TZ=America/New_York 2012-03-11T03:00:00-0400", 3 * time.Hour, "TZ=America/New_York 2012-03-11T00:00:00-0500"},
+	}
+
+	for _, c := range tests {
+		actual := AlignedSchedule{Interval: c.interval, Location: time.Local}.Prev(getTime(c.time))
+		expected := getTime(c.expected)
+		if !actual.Equal(expected) {
+			t.Errorf("%s, \"%s\": (expected) %v != %v (actual)", c.time, c.interval, expected, actual)
+		}
+	}
+}
+
+func TestAnchoredNext(t *testing.T) {
+	anchor := getTime("2012-01-01T09:30:00+0000")
+	tests := []struct {
+		time     string
+		interval time.Duration
+		expected string
+	}{
+		// Before the anchor
+		{"2011-12-26T14:45:00+0000", 36 * time.Hour, "2012-01-01T09:30:00+0000"},
+
+		// On and after the anchor
+		{"2012-01-01T09:30:00+0000", 36 * time.Hour, "2012-01-02T21:30:00+0000"},
+		{"2012-01-02T21:29:59+0000", 36 * time.Hour, "2012-01-02T21:30:00+0000"},
+		{"2012-01-02T21:30:00+0000", 36 * time.Hour, "2012-01-04T09:30:00+0000"},
+		{"2012-07-09T14:45:00+0000", 15 * time.Minute, "2012-07-09T15:00:00+0000"},
+		{"2012-07-09T14:45:00+0000", 7 * time.Minute, "2012-07-09T14:47:00+0000"},
+
+		// The anchor is an instant, independent of the time zone.
+		{"2012-07-09T16:45:00+0200", 7 * time.Minute, "2012-07-09T14:47:00+0000"},
+	}
+
+	for _, c := range tests {
+		actual := AnchoredSchedule{Interval: c.interval, Anchor: anchor}.Next(getTime(c.time))
+		expected := getTime(c.expected)
+		if !actual.Equal(expected) {
+			t.Errorf("%s, \"%s\": (expected) %v != %v (actual)", c.time, c.interval, expected, actual)
+		}
+	}
+}
+
+func TestAnchoredPrev(t *testing.T) {
+	anchor := getTime("2012-01-01T09:30:00+0000")
+	tests := []struct {
+		time     string
+		interval time.Duration
+		expected string
+	}{
+		{"2011-12-26T14:45:00+0000", 36 * time.Hour, ""},
+		{"2012-01-01T09:30:00+0000", 36 * time.Hour, ""},
+		{"2012-01-01T09:30:01+0000", 36 * time.Hour, "2012-01-01T09:30:00+0000"},
+		{"2012-01-02T21:30:00+0000", 36 * time.Hour, "2012-01-01T09:30:00+0000"},
+		{"2012-01-02T21:30:01+0000", 36 * time.Hour, "2012-01-02T21:30:00+0000"},
+		{"2012-07-09T16:45:00+0200", 7 * time.Minute, "2012-07-09T14:40:00+0000"},
+	}
+
+	for _, c := range tests {
+		actual := AnchoredSchedule{Interval: c.interval, Anchor: anchor}.Prev(getTime(c.time))
+		expected := getTime(c.expected)
+		if !actual.Equal(expected) {
+			t.Errorf("%s, \"%s\": (expected) %v != %v (actual)", c.time, c.interval, expected, actual)
+		}
+	}
+}
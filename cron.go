@@ -2,6 +2,7 @@ package cron
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"sort"
@@ -29,6 +30,11 @@ type Cron struct {
 	start     chan EntryID
 	pause     chan EntryID
 	doJob     chan EntryID
+	done      chan runResult
+
+	removeCompleted bool
+	dst             DSTPolicy
+	coordinates     *Coordinates
 }
 
 // ScheduleParser is an interface for schedule spec parsers that return a Schedule
@@ -36,6 +42,13 @@ type ScheduleParser interface {
 	Parse(spec string) (Schedule, error)
 }
 
+// SeededScheduleParser is implemented by parsers whose schedules may depend on
+// a seed, such as Parser resolving hash ("H") tokens.  Cron passes the title of
+// each entry as the seed.
+type SeededScheduleParser interface {
+	ParseWithSeed(spec, seed string) (Schedule, error)
+}
+
 // Job is an interface for submitted cron jobs.
 type Job interface {
 	Run(ctx context.Context) error
@@ -45,9 +58,51 @@ type Job interface {
 type Schedule interface {
 	// Next returns the next activation time, later than the given time.
 	// Next is invoked initially, and then each time the job is run.
+	// The zero time means the schedule will not activate again; the entry
+	// is kept, with a zero Next.
 	Next(time.Time) time.Time
 }
 
+// StartSchedule is implemented by schedules whose first activation depends on
+// when the scheduler starts running them, such as RebootSchedule.
+type StartSchedule interface {
+	Schedule
+
+	// Start returns the first activation time, for a scheduler that starts
+	// running the schedule at the given time.  Start is invoked instead of
+	// Next when Cron starts, or when the entry is added while running.
+	Start(time.Time) time.Time
+}
+
+// PrevScheduler is implemented by schedules which can also find their
+// previous activation, such as SpecSchedule and ConstantDelaySchedule, e.g. to
+// check when a job should last have run.
+type PrevScheduler interface {
+	Schedule
+
+	// Prev returns the last activation time, earlier than the given time.
+	// The zero time means the schedule was not activated before it.
+	Prev(time.Time) time.Time
+}
+
+// CompletionSchedule is implemented by schedules whose activations count from
+// the completion of the previous run, such as FixedDelaySchedule.
+type CompletionSchedule interface {
+	Schedule
+
+	// AfterCompletion returns true if Next is to be invoked once each run has
+	// completed, with the time it completed, rather than when the run starts.
+	// The entry's Next time is zero while it runs.
+	AfterCompletion() bool
+}
+
+// afterCompletion returns true if the schedule counts its activations from the
+// completion of each run.
+func afterCompletion(s Schedule) bool {
+	c, ok := s.(CompletionSchedule)
+	return ok && c.AfterCompletion()
+}
+
 // EntryID identifies an entry within a Cron instance
 type EntryID int
 
@@ -64,8 +119,8 @@ type Entry struct {
 	// Schedule on which this job should be run.
 	Schedule Schedule
 
-	// Next time the job will run, or the zero time if Cron has not been
-	// started or this entry's schedule is unsatisfiable
+	// Next time the job will run, or the zero time if the entry is not
+	// scheduled to run (see State) or its schedule is unsatisfiable
 	Next time.Time
 
 	// Prev is the last time this job was run, or the zero time if never.
@@ -83,11 +138,108 @@ type Entry struct {
 	Done   time.Time
 	Fail   time.Time
 	Logs   []string
+
+	// Completed is set once the entry has run for the last time, as one-shot
+	// schedules such as "@at" and "@reboot" do.  Its Next time is then zero.
+	// Completed entries are removed instead with WithRemoveCompleted.
+	// Entries stopped by their bounds are not completed, but kept with their
+	// State saying why.
+	Completed bool
+
+	// NotBefore and NotAfter, if set, bound the activations of the entry.
+	NotBefore time.Time
+	NotAfter  time.Time
+
+	// MaxRuns, if positive, is the number of runs after which the entry
+	// stops, whether they succeeded or not.  Runs counts them.
+	MaxRuns int
+	Runs    int
+
+	// State says whether the entry is scheduled to run, or why it is not.
+	State EntryState
+
+	// running is set while a run of an entry whose schedule counts from the
+	// completion of its runs has not completed.
+	running bool
+}
+
+// EntryState says whether an entry is scheduled to run, or why it is not.
+type EntryState string
+
+const (
+	StateActive    EntryState = "active"    // scheduled to run
+	StateRunning   EntryState = "running"   // waiting for its run to complete, to be scheduled again
+	StatePending   EntryState = "pending"   // scheduled, but waiting for NotBefore
+	StatePaused    EntryState = "paused"    // disabled by PauseEntry or AddEntry
+	StateExpired   EntryState = "expired"   // no activation left before NotAfter
+	StateExhausted EntryState = "exhausted" // has run MaxRuns times
+	StateCompleted EntryState = "completed" // the schedule has no activation left
+)
+
+// reschedule sets the entry's next activation after now, within its bounds,
+// and its state.  If start is set, StartSchedules give their first activation,
+// as for a scheduler starting at now.
+func (e *Entry) reschedule(now time.Time, start bool) {
+	e.Next = time.Time{}
+	switch {
+	case !e.Enable:
+		e.State = StatePaused
+		return
+	case e.MaxRuns > 0 && e.Runs >= e.MaxRuns:
+		e.State = StateExhausted
+		return
+	}
+
+	var next time.Time
+	if start {
+		next = first(e.Schedule, now)
+	} else {
+		next = e.Schedule.Next(now)
+	}
+	if !next.IsZero() && next.Before(e.NotBefore) {
+		next = e.Schedule.Next(e.NotBefore.Add(-time.Nanosecond))
+	}
+	switch {
+	case !e.NotAfter.IsZero() && (next.After(e.NotAfter) || next.IsZero() && now.After(e.NotAfter)):
+		e.State = StateExpired
+	case next.IsZero():
+		e.State = StateCompleted
+	case now.Before(e.NotBefore):
+		e.State, e.Next = StatePending, next
+	default:
+		e.State, e.Next = StateActive, next
+	}
 }
 
 // Valid returns true if this is not the zero entry.
 func (e Entry) Valid() bool { return e.ID != 0 }
 
+// UnmarshalJSON decodes an entry encoded by encoding/json, such as those
+// listed by CronHTTP.  Schedules encoded as spec strings, as the schedules in
+// this package are, are parsed again; other schedules and the jobs are left
+// nil.
+func (e *Entry) UnmarshalJSON(data []byte) error {
+	type entry Entry
+	var aux struct {
+		*entry
+		Schedule json.RawMessage
+	}
+	aux.entry = (*entry)(e)
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	e.Schedule = nil
+	var spec string
+	if json.Unmarshal(aux.Schedule, &spec) == nil && spec != "" {
+		schedule, err := parseText(spec)
+		if err != nil {
+			return err
+		}
+		e.Schedule = schedule
+	}
+	return nil
+}
+
 // byTime is a wrapper for sorting the entry array by time
 // (with zero time at the end).
 type byTime []*Entry
@@ -135,6 +287,7 @@ func New(opts ...Option) *Cron {
 		start:     make(chan EntryID, 1),
 		pause:     make(chan EntryID, 1),
 		doJob:     make(chan EntryID, 1),
+		done:      make(chan runResult),
 		running:   false,
 		runningMu: sync.Mutex{},
 		logger:    DefaultLogger,
@@ -155,39 +308,93 @@ func (f FuncJob) Run(ctx context.Context) error { return f(ctx) }
 // AddFunc adds a func to the Cron to be run on the given schedule.
 // The spec is parsed using the time zone of this Cron instance as the default.
 // An opaque ID is returned that can be used to later remove it.
-func (c *Cron) AddFunc(title, spec string, cmd func(context.Context) error) (EntryID, error) {
-	return c.AddJob(title, spec, FuncJob(cmd))
+func (c *Cron) AddFunc(title, spec string, cmd func(context.Context) error, opts ...EntryOption) (EntryID, error) {
+	return c.AddJob(title, spec, FuncJob(cmd), opts...)
 }
 
 // AddJob adds a Job to the Cron to be run on the given schedule.
 // The spec is parsed using the time zone of this Cron instance as the default.
 // An opaque ID is returned that can be used to later remove it.
-func (c *Cron) AddJob(title, spec string, cmd Job) (EntryID, error) {
-	schedule, err := c.parser.Parse(spec)
+func (c *Cron) AddJob(title, spec string, cmd Job, opts ...EntryOption) (EntryID, error) {
+	schedule, err := c.parse(title, spec)
+	if err != nil {
+		return 0, err
+	}
+	return c.schedule(title, spec, schedule, cmd, true, opts), nil
+}
+
+// AddCalendarFunc is like AddFunc, but the entry's activations on the days
+// excluded by the calendar are dropped or moved, as given by the policy.
+func (c *Cron) AddCalendarFunc(title, spec string, calendar Calendar, policy HolidayPolicy, cmd func(context.Context) error, opts ...EntryOption) (EntryID, error) {
+	return c.AddCalendarJob(title, spec, calendar, policy, FuncJob(cmd), opts...)
+}
+
+// AddCalendarJob is like AddJob, but the entry's activations on the days
+// excluded by the calendar are dropped or moved, as given by the policy.
+// Business-day schedules ("@bday") count their business days on the calendar
+// instead.
+func (c *Cron) AddCalendarJob(title, spec string, calendar Calendar, policy HolidayPolicy, cmd Job, opts ...EntryOption) (EntryID, error) {
+	schedule, err := c.parse(title, spec)
 	if err != nil {
 		return 0, err
 	}
-	return c.schedule(title, spec, schedule, cmd, true), nil
+	if s, ok := schedule.(BusinessDaySchedule); ok && s.Calendar == nil {
+		s.Calendar = calendar
+		return c.schedule(title, spec, s, cmd, true, opts), nil
+	}
+	return c.schedule(title, spec, AvoidHolidays(schedule, calendar, policy), cmd, true, opts), nil
 }
 
 // Schedule adds a Job to the Cron to be run on the given schedule.
 // The job is wrapped with the configured Chain.
-func (c *Cron) Schedule(title string, schedule Schedule, cmd Job) EntryID {
-	return c.schedule(title, "", schedule, cmd, true)
+func (c *Cron) Schedule(title string, schedule Schedule, cmd Job, opts ...EntryOption) EntryID {
+	return c.schedule(title, "", schedule, cmd, true, opts)
 }
 
 // AddEntry 添加任务不一定执行
-func (c *Cron) AddEntry(title string, spec string, cmd Job, enable bool) (EntryID, error) {
-	schedule, err := c.parser.Parse(spec)
+func (c *Cron) AddEntry(title string, spec string, cmd Job, enable bool, opts ...EntryOption) (EntryID, error) {
+	schedule, err := c.parse(title, spec)
 	if err != nil {
 		return 0, err
 	}
-	return c.schedule(title, spec, schedule, cmd, enable), nil
+	return c.schedule(title, spec, schedule, cmd, enable, opts), nil
+}
+
+// parse parses the spec of the entry with the given title, seeding the
+// parser with the title if it supports it, and applies the DST policy and
+// coordinates.
+func (c *Cron) parse(title, spec string) (Schedule, error) {
+	var (
+		schedule Schedule
+		err      error
+	)
+	if p, ok := c.parser.(SeededScheduleParser); ok {
+		schedule, err = p.ParseWithSeed(spec, title)
+	} else {
+		schedule, err = c.parser.Parse(spec)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if c.dst != (DSTPolicy{}) {
+		setDSTPolicy(schedule, c.dst)
+	}
+	schedule, ok := locateSolar(schedule, c.coordinates)
+	if !ok {
+		return nil, &ParseError{
+			Spec:    spec,
+			Field:   FieldDescriptor,
+			Length:  len(spec),
+			Reason:  ReasonCoordinates,
+			Message: "missing coordinates, in the spec or by WithCoordinates: " + spec,
+		}
+	}
+	return schedule, nil
 }
 
 // schedule adds a Job to the Cron to be run on the given schedule.
 // The job is wrapped with the configured Chain.
-func (c *Cron) schedule(title string, spec string, schedule Schedule, cmd Job, enable bool) EntryID {
+func (c *Cron) schedule(title string, spec string, schedule Schedule, cmd Job, enable bool, opts []EntryOption) EntryID {
 	c.runningMu.Lock()
 	defer c.runningMu.Unlock()
 	c.nextID++
@@ -201,6 +408,10 @@ func (c *Cron) schedule(title string, spec string, schedule Schedule, cmd Job, e
 		Job:        cmd,
 		Logs:       []string{},
 	}
+	for _, opt := range opts {
+		opt(entry)
+	}
+	entry.reschedule(c.now(), true)
 	if !c.running {
 		c.entries = append(c.entries, entry)
 	} else {
@@ -284,10 +495,20 @@ func (c *Cron) Run(ctx context.Context) error {
 func (c *Cron) run(ctx context.Context) error {
 	c.logger.Info("start")
 
-	// Figure out the next activation times for each entry.
+	// stopped tells the jobs still running that completions are no longer
+	// awaited.
+	stopped := make(chan struct{})
+	defer close(stopped)
+
+	// Figure out the next activation times for each entry.  Completed entries,
+	// such as "@reboot" ones from an earlier start, do not run again.
 	now := c.now()
 	for _, entry := range c.entries {
-		entry.Next = entry.Schedule.Next(now)
+		entry.running = false
+		if entry.Completed {
+			continue
+		}
+		c.rescheduleEntry(entry, now, true)
 		c.logger.Info("schedule", "now", now, "entry", entry.ID, "title", entry.Title, "next", entry.Next)
 	}
 
@@ -301,7 +522,7 @@ func (c *Cron) run(ctx context.Context) error {
 			// and stop requests.
 			timer = time.NewTimer(100000 * time.Hour)
 		} else {
-			timer = time.NewTimer(c.entries[0].Next.Sub(now))
+			timer = time.NewTimer(c.entries[0].Next.Sub(c.now()))
 		}
 
 		for {
@@ -314,26 +535,40 @@ func (c *Cron) run(ctx context.Context) error {
 				c.logger.Info("wake", "now", now)
 
 				// Run every entry whose next time was less than now
+				var completed []EntryID
 				for _, e := range c.entries {
 					if !e.Enable {
 						e.Prev = time.Time{}
 						e.Next = time.Time{}
+						e.State = StatePaused
 						continue
 					}
 					if e.Next.After(now) || e.Next.IsZero() {
 						break
 					}
-					c.runEntry(ctx, e)
+					c.runEntry(ctx, e, stopped)
+					e.Runs++
 					e.Prev = e.Next
-					e.Next = e.Schedule.Next(now)
+					if afterCompletion(e.Schedule) {
+						e.Next, e.State, e.running = time.Time{}, StateRunning, true
+						c.logger.Info("run", "now", now, "entry", e.ID, "title", e.Title, "next", "after completion")
+						continue
+					}
+					e.reschedule(now, false)
 					c.logger.Info("run", "now", now, "entry", e.ID, "title", e.Title, "next", e.Next)
+					if e.State == StateCompleted {
+						completed = append(completed, e.ID)
+					}
+				}
+				for _, id := range completed {
+					c.completeEntry(id)
 				}
 
 			case newEntry := <-c.add:
 				timer.Stop()
 				now = c.now()
-				newEntry.Next = newEntry.Schedule.Next(now)
 				c.entries = append(c.entries, newEntry)
+				c.rescheduleEntry(newEntry, now, true)
 				c.logger.Info("added", "now", now, "entry", newEntry.ID, "title", newEntry.Title, "next", newEntry.Next)
 
 			case replyChan := <-c.snapshot:
@@ -359,14 +594,52 @@ func (c *Cron) run(ctx context.Context) error {
 				c.startEntry(id)
 				c.logger.Info("start", "entry", id)
 
+			case result := <-c.done:
+				timer.Stop()
+				now = c.now()
+				for _, e := range c.entries {
+					if e.ID != result.id {
+						continue
+					}
+					c.record(e, result)
+					if e.running {
+						e.running = false
+						if !e.Enable {
+							break
+						}
+						c.rescheduleEntry(e, now, false)
+						c.logger.Info("completed run", "now", now, "entry", e.ID, "title", e.Title, "next", e.Next)
+					}
+					break
+				}
+
 			case id := <-c.doJob:
 				for _, e := range c.entries {
 					if e.ID == id {
 						nw := c.now()
-						c.runEntry(ctx, e)
+						if e.running {
+							c.logger.Info("skip", "now", nw, "entry", e.ID, "title", e.Title, "reason", "still running")
+							break
+						}
+						if e.MaxRuns > 0 && e.Runs >= e.MaxRuns {
+							c.logger.Info("skip", "now", nw, "entry", e.ID, "title", e.Title, "reason", StateExhausted)
+							break
+						}
+						if !e.NotAfter.IsZero() && nw.After(e.NotAfter) {
+							c.logger.Info("skip", "now", nw, "entry", e.ID, "title", e.Title, "reason", StateExpired)
+							break
+						}
+						c.runEntry(ctx, e, stopped)
+						e.Runs++
 						e.Prev = nw
-						e.Next = e.Schedule.Next(nw)
-						c.logger.Info("run", "now", now, "entry", e.ID, "title", e.Title, "next", e.Next)
+						if afterCompletion(e.Schedule) {
+							e.Next, e.State, e.running = time.Time{}, StateRunning, true
+							c.logger.Info("run", "now", nw, "entry", e.ID, "title", e.Title, "next", "after completion")
+							break
+						}
+						c.rescheduleEntry(e, nw, false)
+						c.logger.Info("run", "now", nw, "entry", e.ID, "title", e.Title, "next", e.Next)
+						break
 					}
 				}
 
@@ -377,35 +650,63 @@ func (c *Cron) run(ctx context.Context) error {
 	}
 }
 
+// first returns the first activation time of the given schedule, for a
+// scheduler that starts running it at now.
+func first(s Schedule, now time.Time) time.Time {
+	if s, ok := s.(StartSchedule); ok {
+		return s.Start(now)
+	}
+	return s.Next(now)
+}
+
+// RunEntry runs the entry now, out of its schedule.  Entries which have run
+// MaxRuns times or are past NotAfter are not run, nor are entries whose
+// schedule counts from the completion of its runs while they run.
 func (c *Cron) RunEntry(id EntryID) {
 	c.doJob <- id
 }
 
 var maxLogs = 10
 
-// runEntry runs the given job in a new goroutine.
-func (c *Cron) runEntry(ctx context.Context, e *Entry) {
+// runResult is how a run of an entry's job ended, sent on c.done.
+type runResult struct {
+	id  EntryID
+	at  time.Time
+	err error
+}
+
+// runEntry runs the given job in a new goroutine.  Its result is sent on
+// c.done once the job returns, unless the scheduler has stopped by then.
+func (c *Cron) runEntry(ctx context.Context, e *Entry, stopped <-chan struct{}) {
 	c.jobWaiter.Add(1)
+	id, job := e.ID, e.WrappedJob
 	go func() {
 		defer c.jobWaiter.Done()
-		err := e.WrappedJob.Run(ctx)
-		now := c.now()
-		if err != nil && !errors.Is(err, context.Canceled) {
-			c.logger.Error(err, "job run err")
-			if len(e.Logs) >= maxLogs {
-				copy(e.Logs[0:], e.Logs[1:maxLogs])
-				e.Logs[maxLogs-1] = fmt.Sprintf("%v %v", now, err)
-				return
-			}
-
-			e.Logs = append(e.Logs, fmt.Sprintf("%v %v", now, err))
-			e.Fail = now
-		} else {
-			e.Done = now
+		err := job.Run(ctx)
+		select {
+		case c.done <- runResult{id: id, at: c.now(), err: err}:
+		case <-stopped:
 		}
 	}()
 }
 
+// record records the result of a run of the entry's job.
+func (c *Cron) record(e *Entry, result runResult) {
+	if result.err == nil || errors.Is(result.err, context.Canceled) {
+		e.Done = result.at
+		return
+	}
+	c.logger.Error(result.err, "job run err")
+	if len(e.Logs) >= maxLogs {
+		copy(e.Logs[0:], e.Logs[1:maxLogs])
+		e.Logs[maxLogs-1] = fmt.Sprintf("%v %v", result.at, result.err)
+		return
+	}
+
+	e.Logs = append(e.Logs, fmt.Sprintf("%v %v", result.at, result.err))
+	e.Fail = result.at
+}
+
 // // startJob runs the given job in a new goroutine.
 // func (c *Cron) startJob(j Job) {
 // 	c.jobWaiter.Add(1)
@@ -440,8 +741,15 @@ func (c *Cron) Stop(ctx context.Context) error {
 
 // entrySnapshot returns a copy of the current cron entry list.
 func (c *Cron) entrySnapshot() []Entry {
-	var entries = make([]Entry, len(c.entries))
+	var (
+		now     = c.now()
+		entries = make([]Entry, len(c.entries))
+	)
 	for i, e := range c.entries {
+		if e.State == StatePending && !now.Before(e.NotBefore) {
+			// NotBefore has passed, before the first run.
+			e.State = StateActive
+		}
 		entries[i] = *e
 	}
 	return entries
@@ -457,10 +765,37 @@ func (c *Cron) removeEntry(id EntryID) {
 	c.entries = entries
 }
 
+// rescheduleEntry reschedules the entry, and retires it if its schedule has
+// no activation left.  Entries which are expired or exhausted instead keep
+// their state.
+func (c *Cron) rescheduleEntry(e *Entry, now time.Time, start bool) {
+	e.reschedule(now, start)
+	if e.State == StateCompleted {
+		c.completeEntry(e.ID)
+	}
+}
+
+// completeEntry retires the entry after its last run, removing it or marking
+// it completed.
+func (c *Cron) completeEntry(id EntryID) {
+	if c.removeCompleted {
+		c.removeEntry(id)
+		c.logger.Info("completed", "entry", id, "removed", true)
+		return
+	}
+	for _, e := range c.entries {
+		if e.ID == id {
+			e.Completed = true
+		}
+	}
+	c.logger.Info("completed", "entry", id, "removed", false)
+}
+
 func (c *Cron) pauseEntry(id EntryID) {
 	for _, e := range c.entries {
 		if e.ID == id {
 			e.Enable = false
+			e.State = StatePaused
 			return
 		}
 	}
@@ -470,7 +805,12 @@ func (c *Cron) startEntry(id EntryID) {
 	for _, e := range c.entries {
 		if e.ID == id {
 			e.Enable = true
-			e.Next = e.Schedule.Next(c.now())
+			if e.running {
+				// Scheduled again once the run completes.
+				e.State = StateRunning
+				return
+			}
+			c.rescheduleEntry(e, c.now(), false)
 			return
 		}
 	}
@@ -3,8 +3,10 @@ package cron
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
+	"reflect"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -415,6 +417,38 @@ func TestNonLocalTimezone(t *testing.T) {
 	}
 }
 
+// Anchors and times without an offset are read in the location of the Cron,
+// whatever the local time zone.
+func TestWallClockInCronLocation(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	job := func(context.Context) error { return nil }
+	cron := New(WithLocation(tokyo))
+	anchored, err := cron.AddFunc("TestWallClockInCronLocation", "@every 24h from 2026-01-01T09:30", job)
+	if err != nil {
+		t.Fatal(err)
+	}
+	daily, _ := cron.AddFunc("TestWallClockInCronLocation", "30 9 * * *", job)
+
+	next := cron.Entry(anchored).Next.In(tokyo)
+	if next.Hour() != 9 || next.Minute() != 30 {
+		t.Errorf("expected the next run at 09:30 in Asia/Tokyo, got %v", next)
+	}
+	if expected := cron.Entry(daily).Next; !next.Equal(expected) {
+		t.Errorf("expected the next run at %v, with the spec, got %v", expected, next)
+	}
+
+	once, err := cron.AddFunc("TestWallClockInCronLocation", "@at 2099-11-01T08:00:00", job)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected, next := time.Date(2099, time.November, 1, 8, 0, 0, 0, tokyo), cron.Entry(once).Next; !next.Equal(expected) {
+		t.Errorf("expected the run at %v, got %v", expected, next)
+	}
+}
+
 // Test that calling stop before start silently returns without
 // blocking the stop channel.
 func TestStopWithoutStart(t *testing.T) {
@@ -602,6 +636,411 @@ func TestScheduleAfterRemoval(t *testing.T) {
 	}
 }
 
+// Hash tokens resolve from the title, and show up in the entry snapshot.
+func TestHashSeededByTitle(t *testing.T) {
+	cron := New()
+	idA, err := cron.AddFunc("job a", "H H * * *", func(context.Context) error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	idB, _ := cron.AddFunc("job b", "H H * * *", func(context.Context) error { return nil })
+	idA2, _ := cron.AddFunc("job a", "H H * * *", func(context.Context) error { return nil })
+
+	expected, _ := standardParser.ParseWithSeed("H H * * *", "job a")
+	if actual := cron.Entry(idA).Schedule; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+	if a, a2 := cron.Entry(idA).Schedule, cron.Entry(idA2).Schedule; !reflect.DeepEqual(a, a2) {
+		t.Errorf("expected entries with the same title to resolve identically, got %v and %v", a, a2)
+	}
+	if a, b := cron.Entry(idA).Schedule, cron.Entry(idB).Schedule; reflect.DeepEqual(a, b) {
+		t.Errorf("expected entries with different titles to resolve differently, got %v", a)
+	}
+}
+
+// @reboot entries run once when cron starts, and stay listed afterwards.
+func TestRebootRunsOnce(t *testing.T) {
+	var calls int64
+	cron := newWithSeconds()
+	id, err := cron.AddFunc("TestRebootRunsOnce", "@reboot", func(context.Context) error {
+		atomic.AddInt64(&calls, 1)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	delayed, _ := cron.AddFunc("TestRebootRunsOnce", "@reboot +500ms", func(context.Context) error {
+		atomic.AddInt64(&calls, 1)
+		return nil
+	})
+	cron.Start(context.TODO())
+	defer cron.Stop(context.TODO())
+
+	time.Sleep(250 * time.Millisecond)
+	if n := atomic.LoadInt64(&calls); n != 1 {
+		t.Errorf("expected the undelayed job to have run once, got %d runs", n)
+	}
+
+	time.Sleep(OneSecond)
+	if n := atomic.LoadInt64(&calls); n != 2 {
+		t.Errorf("expected each job to run once, got %d runs", n)
+	}
+	for _, id := range []EntryID{id, delayed} {
+		entry := cron.Entry(id)
+		if !entry.Valid() {
+			t.Fatalf("expected entry %d to stay listed", id)
+		}
+		if !entry.Next.IsZero() || entry.Prev.IsZero() {
+			t.Errorf("expected a cleared Next and a set Prev, got %v and %v", entry.Next, entry.Prev)
+		}
+		if !entry.Completed {
+			t.Errorf("expected entry %d to be completed", id)
+		}
+	}
+}
+
+// @reboot entries do not run again when cron is stopped and started again.
+func TestRebootStopStart(t *testing.T) {
+	var calls int64
+	cron := New()
+	id, _ := cron.AddFunc("TestRebootStopStart", "@reboot", func(context.Context) error {
+		atomic.AddInt64(&calls, 1)
+		return nil
+	})
+	cron.Start(context.TODO())
+	time.Sleep(250 * time.Millisecond)
+	cron.Stop(context.TODO())
+	cron.Start(context.TODO())
+	defer cron.Stop(context.TODO())
+
+	time.Sleep(250 * time.Millisecond)
+	if n := atomic.LoadInt64(&calls); n != 1 {
+		t.Errorf("expected the job to run once, got %d runs", n)
+	}
+	if entry := cron.Entry(id); !entry.Completed || entry.State != StateCompleted || !entry.Next.IsZero() {
+		t.Errorf("expected a completed entry, got %v at %v", entry.State, entry.Next)
+	}
+}
+
+// @at entries run once, and are then kept as completed or removed.
+func TestAtRunsOnce(t *testing.T) {
+	for _, remove := range []bool{false, true} {
+		var calls int64
+		var opts []Option
+		if remove {
+			opts = append(opts, WithRemoveCompleted())
+		}
+		cron := New(opts...)
+		at := At(time.Now().Add(OneSecond))
+		id := cron.Schedule("TestAtRunsOnce", at, FuncJob(func(context.Context) error {
+			atomic.AddInt64(&calls, 1)
+			return nil
+		}))
+		every, _ := cron.AddFunc("TestAtRunsOnce", "@every 1h", func(context.Context) error { return nil })
+		cron.Start(context.TODO())
+
+		time.Sleep(OneSecond + 100*time.Millisecond)
+		if n := atomic.LoadInt64(&calls); n != 1 {
+			t.Errorf("expected the job to have run once, got %d runs", n)
+		}
+		entry := cron.Entry(id)
+		switch {
+		case remove && entry.Valid():
+			t.Errorf("expected the entry to be removed, got %+v", entry)
+		case !remove && (!entry.Completed || !entry.Next.IsZero() || !entry.Prev.Equal(at.At)):
+			t.Errorf("expected a completed entry, got %+v", entry)
+		}
+		if entry := cron.Entry(every); !entry.Valid() || entry.Completed {
+			t.Errorf("expected the other entry to be kept, got %+v", entry)
+		}
+		cron.Stop(context.TODO())
+	}
+}
+
+// Sub-second intervals run on time, without waking the loop in between.
+func TestIntervalRunsSubSecond(t *testing.T) {
+	var calls int64
+	cron := New()
+	id := cron.Schedule("TestIntervalRunsSubSecond", Interval(100*time.Millisecond), FuncJob(func(context.Context) error {
+		atomic.AddInt64(&calls, 1)
+		return nil
+	}))
+	cron.Start(context.TODO())
+	defer cron.Stop(context.TODO())
+
+	time.Sleep(550 * time.Millisecond)
+	if n := atomic.LoadInt64(&calls); n < 4 || n > 6 {
+		t.Errorf("expected about 5 runs, got %d", n)
+	}
+	if next := cron.Entry(id).Next; next.Nanosecond()%int(100*time.Millisecond) != 0 {
+		t.Errorf("expected the next run on a multiple of 100ms, got %v", next)
+	}
+}
+
+// Fixed-delay entries are scheduled again only once their run completes, so
+// slow runs do not overlap.
+func TestFixedDelayWaitsForCompletion(t *testing.T) {
+	var running, overlaps, calls int64
+	cron := New()
+	id := cron.Schedule("TestFixedDelayWaitsForCompletion", FixedDelay(time.Second), FuncJob(func(context.Context) error {
+		if atomic.AddInt64(&running, 1) > 1 {
+			atomic.AddInt64(&overlaps, 1)
+		}
+		atomic.AddInt64(&calls, 1)
+		time.Sleep(1500 * time.Millisecond)
+		atomic.AddInt64(&running, -1)
+		return nil
+	}))
+	cron.Start(context.TODO())
+	defer cron.Stop(context.TODO())
+
+	time.Sleep(1500 * time.Millisecond)
+	if entry := cron.Entry(id); entry.State != StateRunning || !entry.Next.IsZero() {
+		t.Errorf("expected the entry to be running with no next time, got %v at %v", entry.State, entry.Next)
+	}
+	time.Sleep(1500 * time.Millisecond)
+	if entry := cron.Entry(id); entry.State != StateActive || entry.Next.IsZero() {
+		t.Errorf("expected the entry to be scheduled after its run, got %v at %v", entry.State, entry.Next)
+	}
+	time.Sleep(time.Second)
+	if n := atomic.LoadInt64(&calls); n != 2 {
+		t.Errorf("expected 2 runs, got %d", n)
+	}
+	if n := atomic.LoadInt64(&overlaps); n != 0 {
+		t.Errorf("expected no overlapping runs, got %d", n)
+	}
+}
+
+// Fixed-delay entries are not run or scheduled again by RunEntry or
+// StartEntry while their run is in progress.
+func TestFixedDelayRunEntryWhileRunning(t *testing.T) {
+	var running, overlaps, calls int64
+	started, release := make(chan struct{}, 10), make(chan struct{})
+	cron := New()
+	id := cron.Schedule("TestFixedDelayRunEntryWhileRunning", FixedDelay(time.Second), FuncJob(func(context.Context) error {
+		if atomic.AddInt64(&running, 1) > 1 {
+			atomic.AddInt64(&overlaps, 1)
+		}
+		atomic.AddInt64(&calls, 1)
+		started <- struct{}{}
+		<-release
+		atomic.AddInt64(&running, -1)
+		return nil
+	}))
+	cron.Start(context.TODO())
+	defer cron.Stop(context.TODO())
+
+	select {
+	case <-started:
+	case <-time.After(2 * OneSecond):
+		t.Fatal("expected the entry to run")
+	}
+	cron.RunEntry(id)
+	cron.StartEntry(id)
+	if entry := cron.Entry(id); entry.State != StateRunning || !entry.Next.IsZero() || entry.Runs != 1 {
+		t.Errorf("expected the entry to be running once with no next time, got %v at %v after %d runs", entry.State, entry.Next, entry.Runs)
+	}
+
+	// Complete the run, and wait for the entry to be scheduled again.
+	release <- struct{}{}
+	entry := cron.Entry(id)
+	for deadline := time.Now().Add(time.Second / 2); entry.State != StateActive && time.Now().Before(deadline); entry = cron.Entry(id) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if entry.State != StateActive || entry.Next.IsZero() {
+		t.Errorf("expected the entry to be scheduled after its run, got %v at %v", entry.State, entry.Next)
+	}
+	if n := atomic.LoadInt64(&calls); n != 1 {
+		t.Errorf("expected 1 run, got %d", n)
+	}
+	if n := atomic.LoadInt64(&overlaps); n != 0 {
+		t.Errorf("expected no overlapping runs, got %d", n)
+	}
+	close(release)
+}
+
+// Entries are not scheduled outside their bounds, and say why.
+func TestEntryReschedule(t *testing.T) {
+	daily, _ := ParseStandard("0 9 * * *")
+	at, _ := ParseStandard("@at 2012-07-01T09:00:00+00:00")
+	tests := []struct {
+		entry    Entry
+		next     string
+		expected EntryState
+	}{
+		{Entry{Schedule: daily}, "Tue Jul 10 09:00 2012", StateActive},
+		{Entry{Schedule: daily, NotBefore: getTime("Wed Jul 11 12:00 2012")}, "Thu Jul 12 09:00 2012", StatePending},
+		{Entry{Schedule: daily, NotBefore: getTime("Wed Jul 11 09:00 2012")}, "Wed Jul 11 09:00 2012", StatePending},
+		{Entry{Schedule: daily, NotAfter: getTime("Tue Jul 10 09:00 2012")}, "Tue Jul 10 09:00 2012", StateActive},
+		{Entry{Schedule: daily, NotAfter: getTime("Tue Jul 10 08:00 2012")}, "", StateExpired},
+		{Entry{Schedule: daily, MaxRuns: 2, Runs: 1}, "Tue Jul 10 09:00 2012", StateActive},
+		{Entry{Schedule: daily, MaxRuns: 2, Runs: 2}, "", StateExhausted},
+		{Entry{Schedule: at}, "", StateCompleted},
+		{Entry{Schedule: daily}, "", StatePaused},
+	}
+	for i, c := range tests {
+		e := c.entry
+		e.Enable = c.expected != StatePaused
+		e.reschedule(getTime("Mon Jul 9 10:00 2012"), false)
+		if e.State != c.expected {
+			t.Errorf("%d: expected state %s, got %s", i, c.expected, e.State)
+		}
+		if c.next == "" && !e.Next.IsZero() || c.next != "" && !e.Next.Equal(getTime(c.next)) {
+			t.Errorf("%d: expected next %q, got %v", i, c.next, e.Next)
+		}
+	}
+}
+
+// Entries stop after MaxRuns runs, and do not run after NotAfter.  They are
+// kept with their state, even with WithRemoveCompleted.
+func TestEntryBounds(t *testing.T) {
+	for _, remove := range []bool{false, true} {
+		var calls, late int64
+		opts := []Option{WithParser(secondParser), WithChain()}
+		if remove {
+			opts = append(opts, WithRemoveCompleted())
+		}
+		cron := New(opts...)
+		limited, _ := cron.AddFunc("TestEntryBounds", "* * * * * ?", func(context.Context) error {
+			atomic.AddInt64(&calls, 1)
+			return nil
+		}, MaxRuns(1))
+		expired, _ := cron.AddFunc("TestEntryBounds", "* * * * * ?", func(context.Context) error {
+			atomic.AddInt64(&late, 1)
+			return nil
+		}, NotAfter(time.Now().Add(-time.Second)))
+		cron.Start(context.TODO())
+
+		time.Sleep(2 * OneSecond)
+		if n := atomic.LoadInt64(&calls); n != 1 {
+			t.Errorf("expected the limited job to run once, got %d runs", n)
+		}
+		if n := atomic.LoadInt64(&late); n != 0 {
+			t.Errorf("expected the expired job not to run, got %d runs", n)
+		}
+		if e := cron.Entry(limited); e.State != StateExhausted || e.Runs != 1 || e.Completed || !e.Next.IsZero() {
+			t.Errorf("expected an exhausted entry, got %+v", e)
+		}
+		if e := cron.Entry(expired); e.State != StateExpired || e.Runs != 0 || e.Completed {
+			t.Errorf("expected an expired entry, got %+v", e)
+		}
+		cron.Stop(context.TODO())
+	}
+}
+
+// Entries waiting for NotBefore become active once it passes.
+func TestEntryPendingBecomesActive(t *testing.T) {
+	cron := New()
+	id, _ := cron.AddFunc("TestEntryPendingBecomesActive", "@every 1h", func(context.Context) error { return nil },
+		NotBefore(time.Now().Add(100*time.Millisecond)))
+	cron.Start(context.TODO())
+	defer cron.Stop(context.TODO())
+
+	if e := cron.Entry(id); e.State != StatePending {
+		t.Errorf("expected a pending entry, got %s", e.State)
+	}
+	time.Sleep(200 * time.Millisecond)
+	if e := cron.Entry(id); e.State != StateActive {
+		t.Errorf("expected an active entry, got %s", e.State)
+	}
+}
+
+// Entries report their state before the scheduler starts.
+func TestEntryStateBeforeStart(t *testing.T) {
+	cron := New()
+	job := FuncJob(func(context.Context) error { return nil })
+	pending, _ := cron.AddJob("TestEntryStateBeforeStart", "@every 1h", job, NotBefore(time.Now().Add(time.Hour)))
+	expired, _ := cron.AddJob("TestEntryStateBeforeStart", "@every 1h", job, NotAfter(time.Now().Add(-time.Hour)))
+	paused, _ := cron.AddEntry("TestEntryStateBeforeStart", "@every 1h", job, false)
+	for id, expected := range map[EntryID]EntryState{pending: StatePending, expired: StateExpired, paused: StatePaused} {
+		if e := cron.Entry(id); e.State != expected {
+			t.Errorf("entry %d: expected state %s, got %s", id, expected, e.State)
+		}
+	}
+}
+
+// Entries restarted with no activation left are retired.
+func TestStartEntryCompletes(t *testing.T) {
+	for _, remove := range []bool{false, true} {
+		var opts []Option
+		if remove {
+			opts = append(opts, WithRemoveCompleted())
+		}
+		cron := New(opts...)
+		id, _ := cron.AddEntry("TestStartEntryCompletes", "@at 2012-07-01T09:00:00Z", FuncJob(func(context.Context) error { return nil }), false)
+		cron.Start(context.TODO())
+		cron.StartEntry(id)
+		time.Sleep(100 * time.Millisecond)
+
+		entry := cron.Entry(id)
+		switch {
+		case remove && entry.Valid():
+			t.Errorf("expected the entry to be removed, got %+v", entry)
+		case !remove && (!entry.Completed || entry.State != StateCompleted):
+			t.Errorf("expected a completed entry, got %+v", entry)
+		}
+		cron.Stop(context.TODO())
+	}
+}
+
+// Entries which have run MaxRuns times are not run by RunEntry.
+func TestRunEntryExhausted(t *testing.T) {
+	var calls int64
+	cron := New()
+	id, _ := cron.AddFunc("TestRunEntryExhausted", "@every 1h", func(context.Context) error {
+		atomic.AddInt64(&calls, 1)
+		return nil
+	}, MaxRuns(1))
+	cron.Start(context.TODO())
+	defer cron.Stop(context.TODO())
+
+	cron.RunEntry(id)
+	cron.RunEntry(id)
+	time.Sleep(100 * time.Millisecond)
+	if n := atomic.LoadInt64(&calls); n != 1 {
+		t.Errorf("expected 1 run, got %d", n)
+	}
+	if e := cron.Entry(id); e.State != StateExhausted || e.Runs != 1 {
+		t.Errorf("expected an exhausted entry after 1 run, got %+v", e)
+	}
+}
+
+// Entries encode their schedules as specs, and decode them again.
+func TestEntryJSON(t *testing.T) {
+	cron := New()
+	for _, spec := range []string{"TZ=Asia/Tokyo */5 9-17 * * mon-fri", "@every 5m", "@reboot +30s", "@at 2026-11-01T08:00:00+08:00"} {
+		if _, err := cron.AddFunc(spec, spec, func(context.Context) error { return nil }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cron.Schedule("unknown", new(ZeroSchedule), FuncJob(func(context.Context) error { return nil }))
+
+	data, err := json.Marshal(cron.Entries())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		t.Fatal(err)
+	}
+	for i, expected := range cron.Entries()[:4] {
+		if actual := entries[i]; actual.ID != expected.ID || !reflect.DeepEqual(actual.Schedule, expected.Schedule) {
+			t.Errorf("expected %v, got %v", expected.Schedule, actual.Schedule)
+		}
+	}
+	if len(entries) != 5 || entries[4].Schedule != nil {
+		t.Errorf("expected the unknown schedule to decode as nil, got %+v", entries)
+	}
+
+	var entry Entry
+	if err := json.Unmarshal([]byte(`{"ID":1,"Schedule":"bogus"}`), &entry); err == nil {
+		t.Error("expected an error for a bad spec")
+	}
+	if err := json.Unmarshal([]byte(`{"ID":1,"Schedule":null}`), &entry); err != nil || entry.ID != 1 || entry.Schedule != nil {
+		t.Errorf("expected an entry without a schedule, got %+v, %v", entry, err)
+	}
+}
+
 type ZeroSchedule struct{}
 
 func (*ZeroSchedule) Next(time.Time) time.Time {
@@ -0,0 +1,705 @@
+package cron
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Language selects the language in which schedules are described.
+type Language int
+
+const (
+	English Language = iota
+	Chinese
+)
+
+// Describer is implemented by schedules that can describe themselves.
+// Describe uses it in preference to its own descriptions.
+type Describer interface {
+	Describe(lang Language) string
+}
+
+// Describe returns an English description of the given schedule, e.g.
+// "At 02:30 on Monday through Friday in Asia/Shanghai".
+func Describe(s Schedule) string {
+	return English.Describe(s)
+}
+
+// Describe returns a description of the given schedule in this language, or
+// the empty string if the schedule is of a type it does not know about.
+func (lang Language) Describe(s Schedule) string {
+	switch s := s.(type) {
+	case Describer:
+		return s.Describe(lang)
+	case *SpecSchedule:
+		d := specDescriber{
+			s:     s,
+			lang:  lang,
+			secs:  fieldValues(s.Second, seconds),
+			mins:  fieldValues(s.Minute, minutes),
+			hours: fieldValues(s.Hour, hours),
+		}
+		return d.describe() + d.milliseconds()
+	case ConstantDelaySchedule:
+		return lang.sentence(lang.every(s.Delay))
+	case FixedDelaySchedule:
+		if lang == Chinese {
+			return "每次运行完成后间隔" + lang.duration(s.Delay)
+		}
+		return lang.sentence(lang.duration(s.Delay), "after each run completes")
+	case IntervalSchedule:
+		return lang.sentence(lang.every(s.Interval))
+	case AlignedSchedule:
+		if lang == Chinese {
+			return "从零点起" + lang.every(s.Interval) + lang.location(s.Location)
+		}
+		return lang.sentence(lang.every(s.Interval), "from midnight", lang.location(s.Location))
+	case AnchoredSchedule:
+		anchor := s.Anchor.Format("2006-01-02 15:04:05 MST")
+		if s.Location != nil {
+			anchor = s.Anchor.Format("2006-01-02 15:04:05")
+		}
+		if lang == Chinese {
+			return "自" + anchor + "起" + lang.every(s.Interval) + lang.location(s.Location)
+		}
+		return lang.sentence(lang.every(s.Interval), "from", anchor, lang.location(s.Location))
+	case OnceSchedule:
+		at := s.At.Format("2006-01-02 15:04:05 MST")
+		if s.Location != nil {
+			at = s.At.Format("2006-01-02 15:04:05")
+		}
+		if lang == Chinese {
+			return "于" + at + "运行一次" + lang.location(s.Location)
+		}
+		return lang.sentence("once, at", at, lang.location(s.Location))
+	case RebootSchedule:
+		if lang == Chinese {
+			if s.Delay > 0 {
+				return "启动" + lang.duration(s.Delay) + "后运行一次"
+			}
+			return "启动时运行一次"
+		}
+		if s.Delay > 0 {
+			return "Once, " + lang.duration(s.Delay) + " after cron starts"
+		}
+		return "Once, when cron starts"
+	case fmt.Stringer:
+		return s.String()
+	}
+	return ""
+}
+
+// sentence joins the non-empty parts of an English description with spaces
+// and capitalizes it.  Chinese descriptions are assembled by their callers.
+func (lang Language) sentence(parts ...string) string {
+	var words []string
+	for _, p := range parts {
+		if p != "" {
+			words = append(words, p)
+		}
+	}
+	text := strings.Join(words, " ")
+	if lang == English && text != "" {
+		text = strings.ToUpper(text[:1]) + text[1:]
+	}
+	return text
+}
+
+// location describes a schedule's time zone, if it has one of its own.
+func (lang Language) location(loc *time.Location) string {
+	if loc == nil || loc == time.Local {
+		return ""
+	}
+	if lang == Chinese {
+		return "（" + loc.String() + "）"
+	}
+	return "in " + loc.String()
+}
+
+// durationUnits are the units used to describe durations, largest first.
+var durationUnits = []struct {
+	d        time.Duration
+	en, zh   string
+	singular string
+}{
+	{time.Hour, "hours", "小时", "hour"},
+	{time.Minute, "minutes", "分钟", "minute"},
+	{time.Second, "seconds", "秒", "second"},
+	{time.Millisecond, "milliseconds", "毫秒", "millisecond"},
+}
+
+// duration describes a duration, e.g. "1 hour 30 minutes".
+func (lang Language) duration(d time.Duration) string {
+	var parts []string
+	for _, u := range durationUnits {
+		n := d / u.d
+		d -= n * u.d
+		switch {
+		case n == 0:
+		case lang == Chinese:
+			parts = append(parts, fmt.Sprintf("%d%s", n, u.zh))
+		case n == 1:
+			parts = append(parts, "1 "+u.singular)
+		default:
+			parts = append(parts, fmt.Sprintf("%d %s", n, u.en))
+		}
+	}
+	if lang == Chinese {
+		return strings.Join(parts, "")
+	}
+	return strings.Join(parts, " ")
+}
+
+// every describes a recurring interval, e.g. "every hour" or "every 90 minutes".
+func (lang Language) every(d time.Duration) string {
+	for _, u := range durationUnits {
+		if d == u.d {
+			if lang == Chinese {
+				return "每" + u.zh
+			}
+			return "every " + u.singular
+		}
+	}
+	if lang == Chinese {
+		return "每" + lang.duration(d)
+	}
+	return "every " + lang.duration(d)
+}
+
+// pick returns the English or Chinese text, according to the language.
+func (lang Language) pick(en, zh string) string {
+	if lang == Chinese {
+		return zh
+	}
+	return en
+}
+
+// plural returns "1 <singular>" or "n <plural>".
+func plural(n int, singular, many string) string {
+	if n == 1 {
+		return "1 " + singular
+	}
+	return fmt.Sprintf("%d %s", n, many)
+}
+
+// join joins the items of a list, e.g. "a, b and c".
+func (lang Language) join(items []string) string {
+	sep, last := ", ", " and "
+	if lang == Chinese {
+		sep, last = "、", "和"
+	}
+	switch len(items) {
+	case 0:
+		return ""
+	case 1:
+		return items[0]
+	}
+	return strings.Join(items[:len(items)-1], sep) + last + items[len(items)-1]
+}
+
+// list describes a sorted list of values, collapsing runs of three or more
+// consecutive values into ranges, e.g. "1, 5 through 9 and 12".
+func (lang Language) list(values []int, format func(int) string) string {
+	to := " through "
+	if lang == Chinese {
+		to = "至"
+	}
+	var items []string
+	for i := 0; i < len(values); {
+		j := i
+		for j+1 < len(values) && values[j+1] == values[j]+1 {
+			j++
+		}
+		switch {
+		case j-i >= 2:
+			items = append(items, format(values[i])+to+format(values[j]))
+		case j > i:
+			items = append(items, format(values[i]), format(values[j]))
+		default:
+			items = append(items, format(values[i]))
+		}
+		i = j + 1
+	}
+	return lang.join(items)
+}
+
+// fieldValues returns the values set in the given field.
+func fieldValues(bits uint64, r bounds) []int {
+	var values []int
+	for i := r.min; i <= r.max; i++ {
+		if bits&(1<<i) > 0 {
+			values = append(values, int(i))
+		}
+	}
+	return values
+}
+
+// isFull returns true if the values cover the whole range of their field.
+func isFull(values []int, r bounds) bool {
+	return len(values) == int(r.max-r.min+1)
+}
+
+// stepFromMin returns the step of values such as "*/15", which start at the
+// minimum of their field and step through to its maximum, or 0 if the
+// values are not of that form.  The step must divide the field evenly, as
+// unevenStep has it: "*/7" hours run at 21:00 and then at 00:00, three hours
+// later, not every 7 hours.
+func stepFromMin(values []int, r bounds) int {
+	if !evenlySpaced(values, r) {
+		return 0
+	}
+	return stepThrough(values, r)
+}
+
+// evenlySpaced returns false if the values step through their field by a
+// step which does not divide it evenly, as unevenStep has it.
+func evenlySpaced(values []int, r bounds) bool {
+	step, _ := unevenStep(values, r)
+	return step == 0
+}
+
+// stepThrough is like stepFromMin, but allows steps which do not divide the
+// field evenly.
+func stepThrough(values []int, r bounds) int {
+	if len(values) < 2 || values[0] != int(r.min) {
+		return 0
+	}
+	step := progression(values)
+	if step < 2 || values[len(values)-1]+step <= int(r.max) {
+		return 0
+	}
+	return step
+}
+
+// progression returns the step between the values if there are at least
+// three of them, evenly spaced, or 0 otherwise.
+func progression(values []int) int {
+	if len(values) < 3 {
+		return 0
+	}
+	step := values[1] - values[0]
+	for i := 2; i < len(values); i++ {
+		if values[i]-values[i-1] != step {
+			return 0
+		}
+	}
+	return step
+}
+
+// ordinal returns the English ordinal of n, e.g. "2nd".
+func ordinal(n int) string {
+	suffix := "th"
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+	return fmt.Sprintf("%d%s", n, suffix)
+}
+
+var ordinalWords = []string{"zeroth", "first", "second", "third", "fourth", "fifth"}
+
+var chineseWeekdays = []string{"周日", "周一", "周二", "周三", "周四", "周五", "周六"}
+
+func clockTime(hour, minute, second int) string {
+	if second != 0 {
+		return fmt.Sprintf("%02d:%02d:%02d", hour, minute, second)
+	}
+	return fmt.Sprintf("%02d:%02d", hour, minute)
+}
+
+// specDescriber describes a SpecSchedule.
+type specDescriber struct {
+	s                 *SpecSchedule
+	lang              Language
+	secs, mins, hours []int
+}
+
+func (d specDescriber) describe() string {
+	var (
+		s                 = d.s
+		lang              = d.lang
+		times, daily      = d.timeOfDay()
+		domRestricted     = s.Dom&starBit == 0
+		dowRestricted     = s.Dow&starBit == 0
+		monthValues       = fieldValues(s.Month, months)
+		domValues         = fieldValues(s.Dom, dom)
+		date, inMonths    string
+		monthly, weekly   string
+		yearList, zone    string
+		hasMonths         = !isFull(monthValues, months)
+		singleDateInMonth = hasMonths && len(monthValues) == 1 && domRestricted && !dowRestricted &&
+			len(domValues) == 1 && len(s.DomRules) == 0
+	)
+
+	if singleDateInMonth {
+		if lang == Chinese {
+			date = fmt.Sprintf("%d月%d日", monthValues[0], domValues[0])
+		} else {
+			date = fmt.Sprintf("on %s %d", time.Month(monthValues[0]), domValues[0])
+		}
+	} else {
+		if hasMonths {
+			inMonths = d.months(monthValues)
+		}
+		if domRestricted {
+			monthly = d.monthlyDays(domValues, hasMonths)
+		} else if dowRestricted && len(s.DowRules) > 0 {
+			monthly = d.monthlyDays(nil, hasMonths)
+		}
+		if dowRestricted {
+			weekly = d.weeklyDays()
+		}
+	}
+	if s.Year != nil {
+		yearList = d.years()
+	}
+	zone = lang.location(s.Location)
+
+	days := monthly
+	if monthly != "" && weekly != "" {
+		if lang == Chinese {
+			days += "或" + weekly
+		} else {
+			days += " or " + weekly
+		}
+	} else if weekly != "" {
+		days = weekly
+	}
+
+	if lang == Chinese {
+		head := yearList + inMonths + date + days
+		if days == "" && date == "" && daily {
+			head += "每天"
+		}
+		if head != "" {
+			head += " "
+		}
+		return head + times + zone
+	}
+	return lang.sentence(times, date, days, inMonths, yearList, zone)
+}
+
+// timeOfDay describes the second, minute and hour fields.  It reports
+// whether they describe particular times of the day, as opposed to an
+// hourly or more frequent schedule.
+func (d specDescriber) timeOfDay() (string, bool) {
+	if len(d.secs) == 1 && len(d.mins) == 1 {
+		return d.fixedTimes(d.secs[0], d.mins[0])
+	}
+	return d.frequent(), false
+}
+
+// fixedTimes describes schedules that run at one second of one minute of
+// some hours.
+func (d specDescriber) fixedTimes(sec, min int) (string, bool) {
+	var (
+		lang      = d.lang
+		h         = d.hours
+		onTheHour = sec == 0 && min == 0
+		offset    string
+	)
+	if lang == Chinese {
+		offset = fmt.Sprintf("%d分", min)
+		if sec != 0 {
+			offset += fmt.Sprintf("%d秒", sec)
+		}
+	} else {
+		switch {
+		case min != 0 && sec != 0:
+			offset = plural(min, "minute", "minutes") + " and " + plural(sec, "second", "seconds")
+		case min != 0:
+			offset = plural(min, "minute", "minutes")
+		default:
+			offset = plural(sec, "second", "seconds")
+		}
+	}
+
+	if isFull(h, hours) {
+		switch {
+		case lang == Chinese && onTheHour:
+			return "每小时", false
+		case lang == Chinese:
+			return "每小时的" + offset, false
+		case onTheHour:
+			return "every hour", false
+		}
+		return "at " + offset + " past the hour", false
+	}
+	if step := stepFromMin(h, hours); step > 0 {
+		switch {
+		case lang == Chinese && onTheHour:
+			return fmt.Sprintf("每%d小时", step), false
+		case lang == Chinese:
+			return fmt.Sprintf("每%d小时的%s", step, offset), false
+		case onTheHour:
+			return fmt.Sprintf("every %d hours", step), false
+		}
+		return fmt.Sprintf("at %s past the hour, every %d hours", offset, step), false
+	}
+	if step := progression(h); step > 0 && evenlySpaced(h, hours) {
+		var (
+			from = clockTime(h[0], min, sec)
+			to   = clockTime(h[len(h)-1], min, sec)
+		)
+		switch {
+		case lang == Chinese && step == 1:
+			return from + "至" + to + "每小时", false
+		case lang == Chinese:
+			return fmt.Sprintf("%s至%s每%d小时", from, to, step), false
+		case step == 1:
+			return "every hour from " + from + " through " + to, false
+		}
+		return fmt.Sprintf("every %d hours from %s through %s", step, from, to), false
+	}
+
+	var times []string
+	for _, hour := range h {
+		times = append(times, clockTime(hour, min, sec))
+	}
+	if lang == Chinese {
+		return lang.join(times), true
+	}
+	return "at " + lang.join(times), true
+}
+
+// frequent describes schedules that run at several seconds or minutes of
+// each hour.
+func (d specDescriber) frequent() string {
+	var (
+		lang            = d.lang
+		secs, mins, hrs string
+		secsAt, minsAt  bool
+		number          = func(n int) string { return fmt.Sprint(n) }
+	)
+
+	switch step := stepFromMin(d.secs, seconds); {
+	case isFull(d.secs, seconds):
+		secs = lang.pick("every second", "每秒")
+	case step > 0:
+		secs = lang.pick("every %d seconds", "每%d秒")
+		secs = fmt.Sprintf(secs, step)
+	case len(d.secs) == 1 && d.secs[0] == 0:
+	case lang == Chinese:
+		secs, secsAt = "第"+lang.list(d.secs, number)+"秒", true
+	case len(d.secs) == 1:
+		secs = "at " + plural(d.secs[0], "second", "seconds") + " past the minute"
+	default:
+		secs = "at seconds " + lang.list(d.secs, number) + " past the minute"
+	}
+
+	switch step := stepFromMin(d.mins, minutes); {
+	case isFull(d.mins, minutes):
+		if secs == "" {
+			mins = lang.pick("every minute", "每分钟")
+		}
+	case step > 0:
+		mins = lang.pick("every %d minutes", "每%d分钟")
+		mins = fmt.Sprintf(mins, step)
+	case lang == Chinese:
+		mins, minsAt = "第"+lang.list(d.mins, number)+"分", true
+	case secs != "" && len(d.mins) == 1:
+		mins = fmt.Sprintf("during minute %d", d.mins[0])
+	case secs != "":
+		mins = "during minutes " + lang.list(d.mins, number)
+	case len(d.mins) == 1:
+		mins = "at " + plural(d.mins[0], "minute", "minutes") + " past the hour"
+	default:
+		mins = "at minutes " + lang.list(d.mins, number) + " past the hour"
+	}
+
+	h := d.hours
+	first, last := 0, 0
+	if len(h) > 0 {
+		first, last = h[0], h[len(h)-1]
+	}
+	switch step := stepFromMin(h, hours); {
+	case isFull(h, hours):
+	case step > 0:
+		hrs = lang.pick("every %d hours", "每%d小时")
+		hrs = fmt.Sprintf(hrs, step)
+	case last-first == len(h)-1:
+		hrs = lang.pick("between %02d:00 and %02d:59", "%02d:00至%02d:59之间")
+		hrs = fmt.Sprintf(hrs, first, last)
+	case progression(h) > 0 && evenlySpaced(h, hours):
+		hrs = lang.pick("every %[3]d hours between %02[1]d:00 and %02[2]d:59", "%02[1]d:00至%02[2]d:59之间每%[3]d小时")
+		hrs = fmt.Sprintf(hrs, first, last, progression(h))
+	case lang == Chinese:
+		hrs = lang.list(h, number) + "时"
+	default:
+		hrs = "during hours " + lang.list(h, number)
+	}
+
+	if lang == Chinese {
+		if minsAt && hrs == "" {
+			mins = "每小时的" + mins
+		}
+		if secsAt && mins == "" {
+			secs = "每分钟的" + secs
+		}
+		var parts []string
+		for _, p := range []string{hrs, mins, secs} {
+			if p != "" {
+				parts = append(parts, p)
+			}
+		}
+		return strings.Join(parts, "，")
+	}
+	var parts []string
+	for _, p := range []string{secs, mins, hrs} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// milliseconds describes the milliseconds of each second at which the
+// schedule is activated, if it has any.
+func (d specDescriber) milliseconds() string {
+	values := d.s.Millisecond
+	if values == nil {
+		return ""
+	}
+	if step := stepFromMin(values, milliseconds); step > 0 {
+		return d.lang.pick(fmt.Sprintf(", every %d milliseconds", step), fmt.Sprintf("，每%d毫秒", step))
+	}
+	list := d.lang.list(values, func(n int) string { return fmt.Sprint(n) })
+	if d.lang == Chinese {
+		return "，第" + list + "毫秒"
+	}
+	if len(values) == 1 {
+		return ", at millisecond " + list
+	}
+	return ", at milliseconds " + list
+}
+
+// monthlyDays describes the days of the month given by the day-of-month
+// field and any day rules.  In Chinese, the leading "每月" is left out if
+// the schedule is limited to some months.
+func (d specDescriber) monthlyDays(values []int, inMonths bool) string {
+	var (
+		lang  = d.lang
+		items []string
+	)
+	if len(values) == 0 && len(d.s.DowRules) == 0 && len(d.s.DomRules) == 1 && d.s.DomRules[0].Kind == Workday {
+		return lang.pick("on working days", "每个工作日")
+	}
+	// The days start again with each month, so they need not divide it.
+	if step := stepThrough(values, dom); step > 0 {
+		if lang == Chinese {
+			items = append(items, fmt.Sprintf("1日起每%d天", step))
+		} else {
+			items = append(items, "every "+ordinal(step)+" day")
+		}
+	} else if len(values) > 0 {
+		if lang == Chinese {
+			items = append(items, lang.list(values, func(n int) string { return fmt.Sprintf("%d日", n) }))
+		} else if len(values) == 1 {
+			items = append(items, fmt.Sprintf("day %d", values[0]))
+		} else {
+			items = append(items, "days "+lang.list(values, func(n int) string { return fmt.Sprint(n) }))
+		}
+	}
+	for _, r := range d.s.DomRules {
+		items = append(items, r.describe(lang))
+	}
+	for _, r := range d.s.DowRules {
+		items = append(items, r.describe(lang))
+	}
+
+	if lang == Chinese {
+		if inMonths {
+			return lang.join(items)
+		}
+		return "每月" + lang.join(items)
+	}
+	return "on " + lang.join(items) + " of the month"
+}
+
+// weeklyDays describes the days of the week set in the day-of-week field.
+func (d specDescriber) weeklyDays() string {
+	values := fieldValues(d.s.Dow, dow)
+	if len(values) == 0 {
+		return ""
+	}
+	if d.lang == Chinese {
+		return "每" + d.lang.list(values, func(n int) string { return chineseWeekdays[n] })
+	}
+	return "on " + d.lang.list(values, func(n int) string { return time.Weekday(n).String() })
+}
+
+func (d specDescriber) months(values []int) string {
+	if d.lang == Chinese {
+		return d.lang.list(values, func(n int) string { return fmt.Sprintf("%d月", n) })
+	}
+	return "in " + d.lang.list(values, func(n int) string { return time.Month(n).String() })
+}
+
+func (d specDescriber) years() string {
+	if d.lang == Chinese {
+		return d.lang.list(d.s.Year, func(n int) string { return fmt.Sprintf("%d年", n) })
+	}
+	return "in " + d.lang.list(d.s.Year, func(n int) string { return fmt.Sprint(n) })
+}
+
+// describe describes the day of the month matched by the rule, e.g. "the
+// last Friday".
+func (r DayRule) describe(lang Language) string {
+	if lang == Chinese {
+		switch r.Kind {
+		case LastDayOfMonth:
+			if r.N > 0 {
+				return fmt.Sprintf("倒数第%d天", r.N+1)
+			}
+			return "最后一天"
+		case NearestWeekday:
+			// "平日" is Monday through Friday, where "工作日" is a working
+			// day of the Chinese calendar.
+			return fmt.Sprintf("离%d日最近的平日", r.N)
+		case LastWeekdayOfMonth:
+			return "最后一个平日"
+		case NthDayOfWeek:
+			return fmt.Sprintf("第%d个%s", r.N, chineseWeekdays[r.Weekday])
+		case LastDayOfWeek:
+			return "最后一个" + chineseWeekdays[r.Weekday]
+		case Workday:
+			return "工作日"
+		case NthWorkday:
+			return fmt.Sprintf("第%d个工作日", r.N)
+		case LastWorkdayOfMonth:
+			return "最后一个工作日"
+		}
+		return ""
+	}
+	switch r.Kind {
+	case LastDayOfMonth:
+		if r.N > 0 {
+			return "the " + ordinal(r.N+1) + " to last day"
+		}
+		return "the last day"
+	case NearestWeekday:
+		return fmt.Sprintf("the weekday nearest day %d", r.N)
+	case LastWeekdayOfMonth:
+		return "the last weekday"
+	case NthDayOfWeek:
+		if r.N < len(ordinalWords) {
+			return "the " + ordinalWords[r.N] + " " + r.Weekday.String()
+		}
+		return "the " + ordinal(r.N) + " " + r.Weekday.String()
+	case LastDayOfWeek:
+		return "the last " + r.Weekday.String()
+	case Workday:
+		return "the working days"
+	case NthWorkday:
+		return "the " + ordinal(r.N) + " working day"
+	case LastWorkdayOfMonth:
+		return "the last working day"
+	}
+	return ""
+}
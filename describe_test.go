@@ -0,0 +1,107 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDescribeSpec(t *testing.T) {
+	parser := NewParser(Second | Minute | Hour | Dom | Month | Dow | YearOptional | Descriptor)
+	tests := []struct {
+		spec, en, zh string
+	}{
+		{"0 30 2 * * 1-5", "At 02:30 on Monday through Friday", "每周一至周五 02:30"},
+		{"TZ=Asia/Shanghai 0 30 2 * * 1-5", "At 02:30 on Monday through Friday in Asia/Shanghai", "每周一至周五 02:30（Asia/Shanghai）"},
+		{"15 30 2 * * *", "At 02:30:15", "每天 02:30:15"},
+		{"0 0 9,21 * * *", "At 09:00 and 21:00", "每天 09:00和21:00"},
+		{"* * * * * *", "Every second", "每秒"},
+		{"0 * * * * *", "Every minute", "每分钟"},
+		{"*/15 * * * * *", "Every 15 seconds", "每15秒"},
+		{"30 * * * * *", "At 30 seconds past the minute", "每分钟的第30秒"},
+		{"0 */5 9-17 * * *", "Every 5 minutes, between 09:00 and 17:59", "09:00至17:59之间，每5分钟"},
+		{"0 0,30 * * * *", "At minutes 0 and 30 past the hour", "每小时的第0和30分"},
+		{"0 10-20 * * * *", "At minutes 10 through 20 past the hour", "每小时的第10至20分"},
+		{"*/10 0 * * * *", "Every 10 seconds, during minute 0", "每小时的第0分，每10秒"},
+		{"0 0 * * * *", "Every hour", "每小时"},
+		{"15 30 * * * *", "At 30 minutes and 15 seconds past the hour", "每小时的30分15秒"},
+		{"0 15 */2 * * *", "At 15 minutes past the hour, every 2 hours", "每2小时的15分"},
+		{"0 0 8-18/2 * * *", "Every 2 hours from 08:00 through 18:00", "08:00至18:00每2小时"},
+		{"0 0 */7 * * *", "At 00:00, 07:00, 14:00 and 21:00", "每天 00:00、07:00、14:00和21:00"},
+		{"0 */7 * * * *", "At minutes 0, 7, 14, 21, 28, 35, 42, 49 and 56 past the hour", "每小时的第0、7、14、21、28、35、42、49和56分"},
+		{"0 */5 */7 * * *", "Every 5 minutes, during hours 0, 7, 14 and 21", "0、7、14和21时，每5分钟"},
+		{"0 0 0 1,15 * *", "At 00:00 on days 1 and 15 of the month", "每月1日和15日 00:00"},
+		{"0 0 0 */2 * *", "At 00:00 on every 2nd day of the month", "每月1日起每2天 00:00"},
+		{"0 0 0 L * *", "At 00:00 on the last day of the month", "每月最后一天 00:00"},
+		{"0 0 0 L-3 * *", "At 00:00 on the 4th to last day of the month", "每月倒数第4天 00:00"},
+		{"0 0 0 15W,LW * *", "At 00:00 on the weekday nearest day 15 and the last weekday of the month", "每月离15日最近的平日和最后一个平日 00:00"},
+		{"0 0 12 * * mon#1", "At 12:00 on the first Monday of the month", "每月第1个周一 12:00"},
+		{"0 0 17 * * 5L", "At 17:00 on the last Friday of the month", "每月最后一个周五 17:00"},
+		{"0 0 0 1,15 * mon", "At 00:00 on days 1 and 15 of the month or on Monday", "每月1日和15日或每周一 00:00"},
+		{"0 0 0 * 1-3 mon-fri", "At 00:00 on Monday through Friday in January through March", "1月至3月每周一至周五 00:00"},
+		{"0 30 9 * 1,7 *", "At 09:30 in January and July", "1月和7月每天 09:30"},
+		{"0 0 0 L 2 *", "At 00:00 on the last day of the month in February", "2月最后一天 00:00"},
+		{"0 0 0 1 * * 2027-2030", "At 00:00 on day 1 of the month in 2027 through 2030", "2027年至2030年每月1日 00:00"},
+		{"@hourly", "Every hour", "每小时"},
+		{"@daily", "At 00:00", "每天 00:00"},
+		{"@weekly", "At 00:00 on Sunday", "每周日 00:00"},
+		{"@monthly", "At 00:00 on day 1 of the month", "每月1日 00:00"},
+		{"@yearly", "At 00:00 on January 1", "1月1日 00:00"},
+	}
+
+	for _, c := range tests {
+		s, err := parser.Parse(c.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual := Describe(s); actual != c.en {
+			t.Errorf("%s: expected %q, got %q", c.spec, c.en, actual)
+		}
+		if actual := Chinese.Describe(s); actual != c.zh {
+			t.Errorf("%s: expected %q, got %q", c.spec, c.zh, actual)
+		}
+	}
+}
+
+func TestDescribeOther(t *testing.T) {
+	tests := []struct {
+		schedule Schedule
+		en, zh   string
+	}{
+		{Every(5 * time.Minute), "Every 5 minutes", "每5分钟"},
+		{Every(90 * time.Minute), "Every 1 hour 30 minutes", "每1小时30分钟"},
+		{Every(time.Hour), "Every hour", "每小时"},
+		{FixedDelay(5 * time.Minute), "5 minutes after each run completes", "每次运行完成后间隔5分钟"},
+		{AlignedSchedule{15 * time.Minute, time.UTC}, "Every 15 minutes from midnight in UTC", "从零点起每15分钟（UTC）"},
+		{AnchoredSchedule{36 * time.Hour, time.Date(2026, 1, 1, 9, 30, 0, 0, time.UTC), nil},
+			"Every 36 hours from 2026-01-01 09:30:00 UTC", "自2026-01-01 09:30:00 UTC起每36小时"},
+		{Solar(Sunrise, 52.52, 13.405), "At sunrise", "每天日出时"},
+		{SolarSchedule{Event: Sunset, Offset: -30 * time.Minute, Location: time.UTC}, "30 minutes before sunset in UTC", "每天日落前30分钟（UTC）"},
+		{SolarSchedule{Event: Dusk, Offset: time.Hour}, "1 hour after dusk", "每天黄昏后1小时"},
+		{RebootSchedule{}, "Once, when cron starts", "启动时运行一次"},
+		{RebootSchedule{30 * time.Second}, "Once, 30 seconds after cron starts", "启动30秒后运行一次"},
+		{describedSchedule{}, "described", "已描述"},
+		{unknownSchedule{}, "", ""},
+	}
+
+	for _, c := range tests {
+		if actual := Describe(c.schedule); actual != c.en {
+			t.Errorf("%#v: expected %q, got %q", c.schedule, c.en, actual)
+		}
+		if actual := Chinese.Describe(c.schedule); actual != c.zh {
+			t.Errorf("%#v: expected %q, got %q", c.schedule, c.zh, actual)
+		}
+	}
+}
+
+type unknownSchedule struct{}
+
+func (unknownSchedule) Next(t time.Time) time.Time { return t.Add(time.Minute) }
+
+type describedSchedule struct{ unknownSchedule }
+
+func (describedSchedule) Describe(lang Language) string {
+	if lang == Chinese {
+		return "已描述"
+	}
+	return "described"
+}
@@ -36,17 +36,26 @@ them in their own goroutines.
 	..
 	c.Stop()  // Stop the scheduler (does not stop any jobs already running).
 
+Entries may be bounded by options given when they are added: NotBefore and
+NotAfter limit them to a window of time, and MaxRuns stops them after a number
+of runs.  Entry.State says whether an entry is scheduled to run, or why it is
+not, e.g. "expired" once its window has passed.  Such entries are kept with
+their state, rather than marked Completed or removed by WithRemoveCompleted.
+
+	c.AddFunc("trial reminder", "0 9 * * *", remind,
+		cron.NotBefore(signup), cron.NotAfter(signup.AddDate(0, 0, 14)), cron.MaxRuns(3))
+
 # CRON Expression Format
 
 A cron expression represents a set of times, using 5 space-separated fields.
 
 	Field name   | Mandatory? | Allowed values  | Allowed special characters
 	----------   | ---------- | --------------  | --------------------------
-	Minutes      | Yes        | 0-59            | * / , -
-	Hours        | Yes        | 0-23            | * / , -
-	Day of month | Yes        | 1-31            | * / , - ?
-	Month        | Yes        | 1-12 or JAN-DEC | * / , -
-	Day of week  | Yes        | 0-6 or SUN-SAT  | * / , - ?
+	Minutes      | Yes        | 0-59            | * / , - H
+	Hours        | Yes        | 0-23            | * / , - H
+	Day of month | Yes        | 1-31            | * / , - ? L W H
+	Month        | Yes        | 1-12 or JAN-DEC | * / , - H
+	Day of week  | Yes        | 0-6 or SUN-SAT  | * / , - ? L # H
 
 Month and Day-of-week field values are case insensitive.  "SUN", "Sun", and
 "sun" are equally accepted.
@@ -73,6 +82,28 @@ parser you saw earlier, except that its seconds field is REQUIRED:
 That emulates Quartz, the most popular alternative Cron schedule format:
 http://www.quartz-scheduler.org/documentation/quartz-2.x/tutorials/crontrigger.html
 
+Quartz also allows a seventh field for the year, in the range 1970-2099, which
+may be enabled with the Year or YearOptional options:
+
+	cron.New(
+		cron.WithParser(
+			cron.NewParser(
+				cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.YearOptional)))
+
+A schedule restricted to some years, e.g. "0 0 0 1 1 * 2027-2030", stops
+firing after the last of them.
+
+For jobs which run several times a second, the Millisecond option adds a field
+for the milliseconds of each second, in the range 0-999, before all others:
+
+	cron.New(
+		cron.WithParser(
+			cron.NewParser(
+				cron.Millisecond | cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)))
+
+With it, "0-999/250 * * * * * *" runs four times a second, and
+"0,500 0 0 9 * * *" runs at 09:00:00 and 09:00:00.5 each day.
+
 # Special Characters
 
 Asterisk ( * )
@@ -105,6 +136,45 @@ Question mark ( ? )
 Question mark may be used instead of '*' for leaving either day-of-month or
 day-of-week blank.
 
+L
+
+In the day-of-month field, "L" means the last day of the month, and "L-3" the
+third day before it.  In the day-of-week field, a weekday followed by "L" means
+the last such weekday of the month, e.g. "5L" or "FRIL" for the last Friday.
+
+W
+
+In the day-of-month field, "15W" means the weekday (Monday to Friday) nearest
+to the 15th of the month, without crossing into another month: if the 15th is
+a Saturday the job runs on Friday the 14th.  "LW" means the last weekday of
+the month.
+
+WD
+
+In the day-of-month field, "WD" means the working days of mainland China,
+following the holidays and make-up working days (调休) of ChinaWorkdays.  "3WD"
+means the third working day of the month, and "LWD" the last one.
+
+Hash ( # )
+
+In the day-of-week field, "2#2" means the second Tuesday of the month, and
+"MON#1" the first Monday.
+
+These modifiers may be mixed with other values of their field, e.g. "1,L".
+
+H
+
+"H" stands for a value chosen by hashing the title of the entry, as in
+Jenkins.  It spreads out jobs which would otherwise all run at the same time,
+while keeping each job on a fixed schedule:
+
+	H * * * *        once an hour, at a minute chosen per job
+	H/15 * * * *     every fifteen minutes, starting within the first fifteen
+	H(0-29) 3 * * *  once between 3:00 and 3:29
+
+In the day-of-month field a plain "H" only picks days 1 to 28.  Cron seeds the
+hash with the entry's title; Parser.ParseWithSeed may be used directly.
+
 # Predefined schedules
 
 You may use one of several pre-defined schedules in place of a cron expression.
@@ -116,6 +186,21 @@ You may use one of several pre-defined schedules in place of a cron expression.
 	@weekly                | Run once a week, midnight between Sat/Sun  | 0 0 * * 0
 	@daily (or @midnight)  | Run once a day, midnight                   | 0 0 * * *
 	@hourly                | Run once an hour, beginning of hour        | 0 * * * *
+	@reboot (or @startup)  | Run once, when cron starts                 |
+
+The @reboot descriptor may be followed by a delay, e.g. "@reboot +30s" runs
+thirty seconds after cron starts.  Entries added while cron is running are
+treated as if it started at that moment.  Stopping and starting cron again
+does not run them again.
+
+The @at descriptor runs once at the given time, e.g.
+"@at 2026-11-01T08:00:00+08:00"; At builds the same schedule from a
+time.Time.  Times without an offset are read in the schedule's time zone,
+which without a "TZ=" prefix is the Cron's location.
+
+Once an entry has run for the last time, as these do, it is kept with a zero
+Next time and Completed set, or removed if cron was created with
+WithRemoveCompleted.
 
 # Intervals
 
@@ -130,10 +215,237 @@ where "duration" is a string accepted by time.ParseDuration
 For example, "@every 1h30m10s" would indicate a schedule that activates after
 1 hour, 30 minutes, 10 seconds, and then every interval after that.
 
+Intervals of less than a second are rounded up to a second, and fractions of a
+second are truncated.  For intervals with fractions of a second, use
+"@interval" instead:
+
+	@interval 250ms
+	@interval 1.5s
+
+These run at every multiple of the interval since the Unix epoch, rather than
+from the previous run, so that they keep to the millisecond.  Interval builds
+such schedules.
+
 Note: The interval does not take the job runtime into account.  For example,
 if a job takes 3 minutes to run, and it is scheduled to run every 5 minutes,
 it will have only 2 minutes of idle time between each run.
 
+To count the interval from the end of each run instead, add "after-completion":
+
+	@every 5m after-completion
+
+Such an entry is scheduled again only once its job returns, 5 minutes later,
+so that a slow job never overlaps or bunches up with its next run.  While the
+job runs, the entry is in StateRunning and its Next time is zero; RunEntry
+does not run it again, and StartEntry leaves it to be scheduled once the run
+completes.  FixedDelay builds such schedules.
+
+Plain intervals are counted from whenever cron computes the next run, so they
+shift when the process restarts.  Two forms give reproducible times instead:
+
+	@every 15m aligned
+	@every 36h from 2026-01-01T09:30
+
+An aligned interval follows the wall clock of the schedule's time zone,
+starting each day at midnight, so "@every 15m aligned" runs at :00, :15, :30
+and :45.  The interval must divide a day evenly.  An anchored interval runs at
+the given time plus any whole number of intervals.  The anchor is interpreted
+in the schedule's time zone, which without a "TZ=" prefix is the Cron's
+location, and may also be given as a date, or in RFC 3339 format.
+
+# Natural language
+
+NaturalParser accepts schedules written as short English phrases, and compiles
+them to the same schedules as the equivalent cron expressions:
+
+	every weekday at 9:30                     | 0 30 9 * * 1-5
+	every 2 hours between 8am and 6pm         | 0 0 8-18/2 * * *
+	on the first monday of each month at noon | 0 0 12 * * MON#1
+	on the last day of the month at 23:00     | 0 0 23 L * *
+	every day at 9:00 in Asia/Tokyo           | CRON_TZ=Asia/Tokyo 0 0 9 * * *
+
+Phrases that do not start with a known word are handed to its Fallback parser,
+so both forms may be used with the same Cron:
+
+	cron.New(cron.WithParser(cron.NaturalParser{
+		Fallback: cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor),
+	}))
+
+# Recurrence rules
+
+Parser also accepts iCalendar recurrences (RFC 5545), as exported by calendar
+applications: DTSTART, RRULE, EXRULE, RDATE and EXDATE properties, separated
+by spaces or newlines, e.g.
+
+	DTSTART;TZID=America/New_York:20260105T090000 RRULE:FREQ=MONTHLY;BYDAY=MO,TU;BYSETPOS=-1;COUNT=10
+	TZ=Asia/Shanghai RRULE:FREQ=WEEKLY;BYDAY=MO,WE,FR;BYHOUR=9;BYMINUTE=30
+
+These produce a RecurrenceSchedule.  Times without a TZID or "Z" are read in
+the location of the "TZ=" prefix, or else time.Local.  Without DTSTART, the
+recurrence starts at midnight on 1 January 1970, so rules should give the
+days and times they occur on.
+
+# Calendar events
+
+SystemdParser accepts the calendar events of systemd timer units (the
+OnCalendar setting), with the semantics systemd gives them:
+
+	Mon..Fri *-*-* 09:00:00
+	*-*-01 04:00
+	Sat,Sun 10:30 Europe/Berlin
+	*:0/15
+	weekly
+
+Its Fallback parser handles anything else, so timers and crontab specs may be
+used side by side.  Text unmarshaling of entries also accepts calendar events.
+
+# Combining schedules
+
+Union, Intersect and Except combine any schedules into one, which Parser also
+accepts in specs with the operators "|", "&" and "&^":
+
+	0 9 * * 1-5 | 0 12 * * 6                | 9:00 on weekdays, and 12:00 on Saturdays
+	0 9 * * 1-5 & 0 9 1-7 * *               | 9:00 on weekdays in the first week
+	0-59/10 * * * * &^ * 2 * * *            | every 10 minutes, except from 02:00 to 02:59
+	(0 9 * * * | 0 17 * * *) &^ * * 25 12 * | 9:00 and 17:00, except on Christmas
+
+As in Go, "&" and "&^" bind more tightly than "|".  Each operand is a spec of
+its own, with its own "TZ=" prefix if any.  A "TZ=" prefix leading the whole
+spec applies to each operand without one, as in
+"TZ=Asia/Tokyo 0 9 * * 1-5 | 0 12 * * 6".  An exception removes the
+activations which fall within an activation of the blackout schedule, and an
+intersection keeps those which fall within an activation of the other.  A
+spec's activations last a minute, or a second or millisecond if it has a
+seconds or milliseconds field matching more than the first, so
+"@every 10m &^ * 2 * * *" skips every run from 02:00 to 02:59.  Other
+schedules only activate at an instant.
+
+# Jitter
+
+A spec may end with "~" and a duration to delay each activation by up to that
+long, like systemd's RandomizedDelaySec, so that processes running the same
+schedule do not all start at once:
+
+	@hourly ~5m          up to five minutes past each hour, differing by process
+	0 3 * * * ~H30m      up to half past three, chosen by hashing the title
+
+The delays of "~" are chosen at random in each process, while those of "~H"
+are derived from the title of the entry, as "H" fields are.  Entry.Next is the
+delayed time.  Jitter and HashedJitter apply a delay to any Schedule.
+
+# Holiday calendars
+
+A Calendar excludes days, such as public holidays, from the schedule of an
+entry.  HolidayCalendar is loaded from an iCalendar file, as published by
+calendar applications, or from a list of dates:
+
+	holidays, err := cron.LoadCalendar("holidays.ics")
+	holidays.Weekends = true
+	c.AddCalendarFunc("report", "0 9 * * *", holidays, cron.NextBusinessDay, report)
+
+SkipHolidays drops the activations on excluded days, while NextBusinessDay and
+PreviousBusinessDay move them to the same time on the nearest day which is not
+excluded.  AvoidHolidays applies a calendar to any Schedule.
+
+ChinaWorkdays is a WorkdayCalendar of the working days of mainland China, with
+the official adjustments of recent years built in.  Those of later years may
+be loaded from a file, one day or range of days per line:
+
+	// 2027-02-06..2027-02-13 holiday 春节
+	// 2027-02-14 workday
+	err := cron.ChinaWorkdays.LoadFile("workdays.txt")
+	c.AddFunc("settlement", "0 10 WD * *", settle)
+
+# Business days
+
+The "@bday" descriptor runs on the Nth business day of each month, counting
+from the end of the month if N is negative, optionally of each quarter
+instead, and at a given time of day:
+
+	@bday 3                     the 3rd business day of the month, at midnight
+	@bday -2 at 18:00           the last business day but one
+	@bday -1 quarter at 09:30   the last business day of the quarter
+
+Business days are Monday to Friday.  Entries added by AddCalendarFunc count
+them on the given calendar instead, and BusinessDay builds such schedules on
+any Calendar.
+
+# Sunrise and sunset
+
+The "@sunrise", "@sunset", "@dawn" and "@dusk" descriptors run daily at that
+event of the sun, as seen from the given latitude and longitude, in degrees
+north and east, optionally moved by an offset:
+
+	@sunset 52.52,13.405        at sunset in Berlin
+	@sunset -30m 52.52,13.405   half an hour before it
+	@dawn +15m -33.87,151.21    a quarter of an hour after civil dawn in Sydney
+
+Dawn and dusk are when the sun is 6° below the horizon.  The times are
+computed offline, with NOAA's solar equations.  Specs may leave out the
+coordinates when the cron instance is given them instead:
+
+	c := cron.New(cron.WithCoordinates(52.52, 13.405))
+	c.AddFunc("lights on", "@sunset -30m", lightsOn)
+
+Beyond the polar circles, the sun does not rise or set on some days, and the
+schedule does not run on them.  Solar builds such schedules.
+
+# Descriptions
+
+Describe returns an English description of a schedule, for display to users:
+
+	s, _ := cron.ParseStandard("CRON_TZ=Asia/Shanghai 30 2 * * 1-5")
+	cron.Describe(s)         // At 02:30 on Monday through Friday in Asia/Shanghai
+	cron.Chinese.Describe(s) // 每周一至周五 02:30（Asia/Shanghai）
+
+Schedules of other types may implement Describer to describe themselves.  The
+/c/job/list endpoint of CronHTTP includes the description of each entry's
+schedule, in Chinese if it is given the parameter "lang=zh".
+
+The schedules in this package also implement fmt.Stringer, returning their
+canonical spec, e.g. "0 9-17 * * mon-fri", and encoding.TextMarshaler, so
+that entries encode their schedules as specs in JSON.  SpecSchedule,
+ConstantDelaySchedule and Entry decode them again.
+
+# Parse errors
+
+Parser returns a *ParseError for specs it cannot parse.  It gives the field
+in error, the byte offset and length of the offending token within the spec,
+the bounds of the field and a machine-readable Reason:
+
+	_, err := cron.ParseStandard("0 25 * * *")
+	var perr *cron.ParseError
+	if errors.As(err, &perr) {
+		fmt.Println(perr.Field, perr.Offset, perr.Length, perr.Reason) // hour 2 2 above_maximum
+	}
+
+The /c/spec/check endpoint of CronHTTP checks the "spec" parameter with the
+Cron's parser, returning its ParseError as JSON if it is invalid, or its
+description and any warnings from Lint otherwise.
+
+Lint flags specs which parse, but are most likely mistakes, such as days that
+never occur ("0 0 30 2 *"), restricting both the day of month and the day of
+week, steps which do not divide their field evenly, and times skipped
+by daylight saving time changes:
+
+	for _, w := range cron.Lint(spec, cron.Minute|cron.Hour|cron.Dom|cron.Month|cron.Dow) {
+		fmt.Println(w.Code, w.Message)
+	}
+
+# Listing activations
+
+NextN and Between list the activations of any schedule, following Next, e.g.
+to show upcoming runs or backfill those missed while the scheduler was down:
+
+	s, _ := cron.ParseStandard("0 9 * * mon-fri")
+	cron.NextN(s, time.Now(), 5)
+	cron.Between(s, lastRun, time.Now())
+
+Schedules which implement PrevScheduler, such as SpecSchedule and
+ConstantDelaySchedule, also find the activation before a time, e.g. to check
+when a job should last have run.  SpecSchedule.Prev treats daylight saving
+time changes as Next does, following the schedule's DSTPolicy.
+
 # Time zones
 
 By default, all interpretation and scheduling is done in the machine's local
@@ -166,8 +478,20 @@ For example:
 
 The prefix "TZ=(TIME ZONE)" is also supported for legacy compatibility.
 
-Be aware that jobs scheduled during daylight-savings leap-ahead transitions will
-not be run!
+Be aware that, by default, jobs scheduled during daylight-savings leap-ahead
+transitions will not be run, and those scheduled during fall-back transitions
+will be run twice!  A DSTPolicy changes this, for a whole cron instance or for
+a single SpecSchedule:
+
+	cron.New(cron.WithDSTPolicy(cron.DSTPolicy{
+		Gap:     cron.GapShift,     // run skipped jobs when the clocks go forward
+		Overlap: cron.OverlapFirst, // run repeated jobs before the clocks go back
+	}))
+
+With GapShift, a job set for 02:30 runs once at 03:00 on the night the clocks
+go from 02:00 to 03:00.  With OverlapFirst or OverlapSecond, a job set for
+01:30 runs once on the night the clocks go from 02:00 back to 01:00, before or
+after the change.
 
 # Job Wrappers
 
@@ -0,0 +1,198 @@
+package cron
+
+import (
+	"time"
+)
+
+// DSTPolicy says how a SpecSchedule treats the wall clock times which a
+// daylight saving time change skips or repeats in its location.  The zero
+// policy skips them, and runs repeated ones twice.
+type DSTPolicy struct {
+	Gap     GapPolicy
+	Overlap OverlapPolicy
+}
+
+// GapPolicy says what becomes of activations at wall clock times skipped
+// when the clocks go forward, e.g. 02:30 on the night the clocks go from 02:00
+// to 03:00.
+type GapPolicy int
+
+const (
+	// GapSkip drops the activations in the gap.
+	GapSkip GapPolicy = iota
+
+	// GapShift runs once at the end of the gap, when the clocks go forward,
+	// if any activation falls in it, as cron(8) does.
+	GapShift
+)
+
+// OverlapPolicy says which activations at wall clock times repeated when the
+// clocks go back are kept, e.g. 01:30 on the night the clocks go from 02:00
+// back to 01:00.
+type OverlapPolicy int
+
+const (
+	// OverlapBoth keeps the activations in both occurrences of the overlap.
+	OverlapBoth OverlapPolicy = iota
+
+	// OverlapFirst keeps those in the first occurrence, before the change.
+	OverlapFirst
+
+	// OverlapSecond keeps those in the second occurrence, after the change.
+	OverlapSecond
+)
+
+// setDSTPolicy sets the DST policy of the SpecSchedules making up the
+// schedule, including those within composite, jittered and calendar schedules.
+func setDSTPolicy(s Schedule, policy DSTPolicy) {
+	switch s := s.(type) {
+	case *SpecSchedule:
+		s.DST = policy
+	case UnionSchedule:
+		for _, schedule := range s.Schedules {
+			setDSTPolicy(schedule, policy)
+		}
+	case IntersectSchedule:
+		setDSTPolicy(s.A, policy)
+		setDSTPolicy(s.B, policy)
+	case ExceptSchedule:
+		setDSTPolicy(s.Schedule, policy)
+		setDSTPolicy(s.Blackout, policy)
+	case JitterSchedule:
+		setDSTPolicy(s.Schedule, policy)
+	case CalendarSchedule:
+		setDSTPolicy(s.Schedule, policy)
+	}
+}
+
+// nextSecond returns the next whole second at which this schedule is
+// activated, after the second of the given time, as its DST policy has it.
+func (s *SpecSchedule) nextSecond(t time.Time) time.Time {
+	loc := s.location(t)
+	next := s.nextMatch(t)
+	for !next.IsZero() && s.DST.drops(next.In(loc)) {
+		next = s.nextMatch(next)
+	}
+	if s.DST.Gap == GapShift {
+		to := next
+		if to.IsZero() {
+			limit, ok := s.nextLimit(t, s.lastYear(t.In(loc)))
+			if !ok {
+				return next
+			}
+			to = time.Date(limit.Year(), time.January, 1, 0, 0, 0, 0, loc)
+		}
+		for _, gap := range dstGaps(loc, t, to) {
+			if gap.at.After(t) && s.inGap(gap) {
+				return gap.at.In(t.Location())
+			}
+		}
+	}
+	return next
+}
+
+// prevSecond returns the last whole second at which this schedule is
+// activated, before the given time, as its DST policy has it.
+func (s *SpecSchedule) prevSecond(t time.Time) time.Time {
+	loc := s.location(t)
+	prev := s.prevMatch(t)
+	for !prev.IsZero() && s.DST.drops(prev.In(loc)) {
+		prev = s.prevMatch(prev)
+	}
+	if s.DST.Gap == GapShift {
+		from := prev
+		if from.IsZero() {
+			from = time.Date(s.firstYear(t.In(loc)), time.January, 1, 0, 0, 0, 0, loc)
+		}
+		gaps := dstGaps(loc, from, t)
+		for i := len(gaps) - 1; i >= 0; i-- {
+			if gap := gaps[i]; gap.at.Before(t) && gap.at.After(prev) && s.inGap(gap) {
+				return gap.at.In(t.Location())
+			}
+		}
+	}
+	return prev
+}
+
+// location returns the location in which the schedule reads the wall clock
+// of the given time.
+func (s *SpecSchedule) location(t time.Time) *time.Location {
+	if s.Location == time.Local {
+		return t.Location()
+	}
+	return s.Location
+}
+
+// inGap returns true if the schedule would be activated at one of the wall
+// clock times skipped by the gap.
+func (s *SpecSchedule) inGap(gap dstGap) bool {
+	_, ok := firstMatch(s, gap.start, gap.length)
+	return ok
+}
+
+// drops returns true if the policy drops the activation at the given time,
+// as one of the two occurrences of a repeated wall clock time.
+func (p DSTPolicy) drops(t time.Time) bool {
+	switch p.Overlap {
+	case OverlapFirst:
+		return occurrence(t) == 2
+	case OverlapSecond:
+		return occurrence(t) == 1
+	}
+	return false
+}
+
+// occurrence returns 1 or 2 if the wall clock of the given time is repeated by
+// a daylight saving time change in its location, and the time is its first or
+// second occurrence, or 0 if the wall clock occurs once.
+func occurrence(t time.Time) int {
+	start, end := zoneBounds(t)
+	_, offset := t.Zone()
+	if !start.IsZero() {
+		_, before := start.Add(-time.Second).Zone()
+		if before > offset && t.Before(start.Add(time.Duration(before-offset)*time.Second)) {
+			return 2
+		}
+	}
+	if !end.IsZero() {
+		_, after := end.Zone()
+		if after < offset && !t.Before(end.Add(-time.Duration(offset-after)*time.Second)) {
+			return 1
+		}
+	}
+	return 0
+}
+
+// dstGap is a range of wall clock times skipped by a daylight saving time
+// change.  The start is given as a time in UTC with the skipped wall clock.
+type dstGap struct {
+	start  time.Time
+	length time.Duration
+
+	// at is the instant of the change, at which the gap ends.
+	at time.Time
+}
+
+// dstGaps returns the wall clock times skipped in loc between from and to.
+func dstGaps(loc *time.Location, from, to time.Time) []dstGap {
+	var gaps []dstGap
+	for t := from.In(loc); t.Before(to); {
+		_, end := zoneBounds(t)
+		if end.IsZero() || !end.Before(to) {
+			break
+		}
+		_, before := t.Zone()
+		_, after := end.Zone()
+		if after > before {
+			wall := end.In(loc)
+			gaps = append(gaps, dstGap{
+				start: time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), 0, time.UTC).
+					Add(-time.Duration(after-before) * time.Second),
+				length: time.Duration(after-before) * time.Second,
+				at:     end,
+			})
+		}
+		t = end
+	}
+	return gaps
+}
@@ -0,0 +1,142 @@
+package cron
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDSTPolicyNext(t *testing.T) {
+	shift := DSTPolicy{Gap: GapShift}
+	first := DSTPolicy{Overlap: OverlapFirst}
+	second := DSTPolicy{Overlap: OverlapSecond}
+	runs := []struct {
+		policy     DSTPolicy
+		time, spec string
+		expected   string
+	}{
+		// Skipped times are dropped, or run when the clocks go forward.
+		{DSTPolicy{}, "2012-03-11T00:00:00-0500", "TZ=America/New_York 0 30 2 * * ?", "2012-03-12T02:30:00-0400"},
+		{shift, "2012-03-11T00:00:00-0500", "TZ=America/New_York 0 30 2 * * ?", "2012-03-11T03:00:00-0400"},
+		{shift, "2012-03-11T03:00:00-0400", "TZ=America/New_York 0 30 2 * * ?", "2012-03-12T02:30:00-0400"},
+		{shift, "2012-03-11T01:59:59-0500", "TZ=America/New_York 0 */15 2 * * ?", "2012-03-11T03:00:00-0400"},
+		{shift, "2012-03-11T00:00:00-0500", "TZ=America/New_York 0 0 * * * ?", "2012-03-11T01:00:00-0500"},
+		{shift, "2012-03-11T01:00:00-0500", "TZ=America/New_York 0 0 * * * ?", "2012-03-11T03:00:00-0400"},
+		{shift, "2012-03-11T03:00:00-0400", "TZ=America/New_York 0 0 * * * ?", "2012-03-11T04:00:00-0400"},
+		{shift, "2012-03-10T00:00:00-0500", "TZ=America/New_York 0 30 2 11 Mar ?", "2012-03-11T03:00:00-0400"},
+
+		// Repeated times run twice, or once.
+		{DSTPolicy{}, "2012-11-04T01:30:00-0400", "TZ=America/New_York 0 30 1 * * ?", "2012-11-04T01:30:00-0500"},
+		{first, "2012-11-04T00:00:00-0400", "TZ=America/New_York 0 30 1 * * ?", "2012-11-04T01:30:00-0400"},
+		{first, "2012-11-04T01:30:00-0400", "TZ=America/New_York 0 30 1 * * ?", "2012-11-05T01:30:00-0500"},
+		{second, "2012-11-04T00:00:00-0400", "TZ=America/New_York 0 30 1 * * ?", "2012-11-04T01:30:00-0500"},
+		{second, "2012-11-04T01:30:00-0500", "TZ=America/New_York 0 30 1 * * ?", "2012-11-05T01:30:00-0500"},
+		{first, "2012-11-04T01:00:00-0400", "TZ=America/New_York 0 0 * * * ?", "2012-11-04T02:00:00-0500"},
+		{second, "2012-11-04T00:00:00-0400", "TZ=America/New_York 0 0 * * * ?", "2012-11-04T01:00:00-0500"},
+		{second, "2012-11-04T00:59:59-0400", "TZ=America/New_York 0 */30 * * * ?", "2012-11-04T01:00:00-0500"},
+
+		// Schedules in time.Local read the location of the given time.
+		{shift, "TZ=America/New_York 2012-03-11T00:00:00-0500", "0 30 2 * * ?", "2012-03-11T03:00:00-0400"},
+		{first, "TZ=America/New_York 2012-11-04T01:30:00-0400", "0 30 1 * * ?", "2012-11-05T01:30:00-0500"},
+	}
+
+	for _, c := range runs {
+		sched, err := secondParser.Parse(c.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		sched.(*SpecSchedule).DST = c.policy
+		actual := sched.Next(getTime(c.time))
+		expected := getTime(c.expected)
+		if !actual.Equal(expected) {
+			t.Errorf("%+v %s, \"%s\": (expected) %v != %v (actual)", c.policy, c.time, c.spec, expected, actual)
+		}
+	}
+}
+
+func TestDSTPolicyPrev(t *testing.T) {
+	shift := DSTPolicy{Gap: GapShift}
+	first := DSTPolicy{Overlap: OverlapFirst}
+	second := DSTPolicy{Overlap: OverlapSecond}
+	runs := []struct {
+		policy     DSTPolicy
+		time, spec string
+		expected   string
+	}{
+		{DSTPolicy{}, "2012-03-11T12:00:00-0400", "TZ=America/New_York 0 30 2 * * ?", "2012-03-10T02:30:00-0500"},
+		{shift, "2012-03-11T12:00:00-0400", "TZ=America/New_York 0 30 2 * * ?", "2012-03-11T03:00:00-0400"},
+		{shift, "2012-03-11T03:00:00-0400", "TZ=America/New_York 0 30 2 * * ?", "2012-03-10T02:30:00-0500"},
+		{shift, "2012-03-11T03:30:00-0400", "TZ=America/New_York 0 0 * * * ?", "2012-03-11T03:00:00-0400"},
+		{first, "2012-11-04T12:00:00-0500", "TZ=America/New_York 0 30 1 * * ?", "2012-11-04T01:30:00-0400"},
+		{second, "2012-11-04T12:00:00-0500", "TZ=America/New_York 0 30 1 * * ?", "2012-11-04T01:30:00-0500"},
+		{second, "2012-11-04T01:30:00-0500", "TZ=America/New_York 0 30 1 * * ?", "2012-11-03T01:30:00-0400"},
+	}
+
+	for _, c := range runs {
+		sched, err := secondParser.Parse(c.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		sched.(*SpecSchedule).DST = c.policy
+		actual := sched.(PrevScheduler).Prev(getTime(c.time))
+		expected := getTime(c.expected)
+		if !actual.Equal(expected) {
+			t.Errorf("%+v %s, \"%s\": (expected) %v != %v (actual)", c.policy, c.time, c.spec, expected, actual)
+		}
+	}
+}
+
+// The policy of a Cron applies to the schedules it parses, however they are
+// combined.
+func TestWithDSTPolicy(t *testing.T) {
+	policy := DSTPolicy{Gap: GapShift, Overlap: OverlapFirst}
+	cron := New(WithDSTPolicy(policy))
+	for _, spec := range []string{
+		"30 2 * * *",
+		"30 2 * * * ~1m",
+		"30 2 * * mon-fri | 0 9 * * sat",
+	} {
+		id, err := cron.AddFunc(spec, spec, func(context.Context) error { return nil })
+		if err != nil {
+			t.Fatal(err)
+		}
+		var specs []*SpecSchedule
+		var walk func(Schedule)
+		walk = func(s Schedule) {
+			switch s := s.(type) {
+			case *SpecSchedule:
+				specs = append(specs, s)
+			case JitterSchedule:
+				walk(s.Schedule)
+			case UnionSchedule:
+				for _, s := range s.Schedules {
+					walk(s)
+				}
+			}
+		}
+		walk(cron.Entry(id).Schedule)
+		if len(specs) == 0 {
+			t.Errorf("%s: no spec schedules in %v", spec, cron.Entry(id).Schedule)
+		}
+		for _, s := range specs {
+			if s.DST != policy {
+				t.Errorf("%s: expected %+v, got %+v", spec, policy, s.DST)
+			}
+		}
+	}
+}
+
+func TestLintGapShift(t *testing.T) {
+	ny, _ := time.LoadLocation("America/New_York")
+	now := time.Date(2012, 1, 1, 0, 0, 0, 0, ny)
+	s, _ := ParseStandard("TZ=America/New_York 30 2 * * *")
+	if warnings := lintSchedule(s, now); len(warnings) != 1 || warnings[0].Code != WarningDSTGap {
+		t.Errorf("expected a DST gap warning, got %v", warnings)
+	}
+	s.(*SpecSchedule).DST.Gap = GapShift
+	if warnings := lintSchedule(s, now); len(warnings) != 0 {
+		t.Errorf("expected no warnings, got %v", warnings)
+	}
+}
@@ -2,8 +2,10 @@ package cron
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
 )
@@ -12,6 +14,13 @@ type CronHTTP struct {
 	c *Cron
 }
 
+// jobListItem is an entry as listed by /c/job/list, with a description of
+// its schedule in the language given by the "lang" parameter ("en" or "zh").
+type jobListItem struct {
+	Entry
+	Description string
+}
+
 func NewCronHTTP(c *Cron) *CronHTTP {
 	return &CronHTTP{c: c}
 }
@@ -21,7 +30,12 @@ func (p *CronHTTP) Handler() http.Handler {
 
 	r.HandleFunc("/c/job/list", func(w http.ResponseWriter, r *http.Request) {
 
-		entries := p.c.Entries()
+		lang := requestLanguage(r)
+		snapshot := p.c.Entries()
+		entries := make([]jobListItem, 0, len(snapshot))
+		for _, e := range snapshot {
+			entries = append(entries, jobListItem{e, lang.Describe(e.Schedule)})
+		}
 
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(200)
@@ -120,5 +134,45 @@ func (p *CronHTTP) Handler() http.Handler {
 
 	}).Methods("POST")
 
+	r.HandleFunc("/c/spec/check", func(w http.ResponseWriter, r *http.Request) {
+		spec := r.FormValue("spec")
+		schedule, err := p.c.parse(r.FormValue("title"), spec)
+
+		w.Header().Set("Content-Type", "application/json")
+		if err != nil {
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				perr = &ParseError{Spec: spec, Field: FieldSpec, Length: len(spec), Message: err.Error()}
+			}
+			w.WriteHeader(400)
+			json.NewEncoder(w).Encode(perr)
+			return
+		}
+
+		w.WriteHeader(200)
+		json.NewEncoder(w).Encode(specCheck{
+			Spec:        spec,
+			Description: requestLanguage(r).Describe(schedule),
+			Warnings:    lintSchedule(schedule, p.c.now()),
+		})
+	}).Methods("GET")
+
 	return r
 }
+
+// specCheck is the response of /c/spec/check for a valid spec, with any
+// warnings from Lint.  Invalid specs are answered with their ParseError.
+type specCheck struct {
+	Spec        string
+	Description string
+	Warnings    []Warning
+}
+
+// requestLanguage returns the language given by the "lang" parameter of the
+// request, "en" (the default) or "zh".
+func requestLanguage(r *http.Request) Language {
+	if strings.HasPrefix(r.FormValue("lang"), "zh") {
+		return Chinese
+	}
+	return English
+}
@@ -0,0 +1,170 @@
+package cron
+
+import (
+	cryptorand "crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
+)
+
+// JitterSchedule delays each activation of a schedule by a pseudo-random
+// duration of up to Max, in whole seconds, like systemd's RandomizedDelaySec.
+// It spreads out processes which run the same schedule, so that they do not
+// all start at once.
+//
+// The delay of each activation is derived from the seed and the time of the
+// activation, so that Next gives the same delayed times however often it is
+// called.
+type JitterSchedule struct {
+	Schedule Schedule
+	Max      time.Duration
+	Seed     uint64
+
+	// Hashed is set if the seed was derived from the title of the entry, as
+	// by the "~H" suffix, rather than chosen at random.
+	Hashed bool
+}
+
+// Jitter returns the schedule, with each activation delayed by up to max.
+// The delays are chosen at random for each process.
+func Jitter(s Schedule, max time.Duration) JitterSchedule {
+	return JitterSchedule{Schedule: s, Max: max, Seed: randomSeed()}
+}
+
+// HashedJitter is like Jitter, but the delays are derived from the given
+// seed, typically the title of the entry, so that they are the same in every
+// process.
+func HashedJitter(s Schedule, max time.Duration, seed string) JitterSchedule {
+	h := fnv.New64a()
+	h.Write([]byte(seed))
+	return JitterSchedule{Schedule: s, Max: max, Seed: h.Sum64(), Hashed: true}
+}
+
+// randomSeed returns a seed which differs between processes.
+func randomSeed() uint64 {
+	var b [8]byte
+	if _, err := cryptorand.Read(b[:]); err != nil {
+		return uint64(time.Now().UnixNano())
+	}
+	return binary.LittleEndian.Uint64(b[:])
+}
+
+// delay returns the delay of the activation at the given time.
+func (schedule JitterSchedule) delay(t time.Time) time.Duration {
+	n := uint64(schedule.Max / time.Second)
+	if n == 0 {
+		return 0
+	}
+	var b [16]byte
+	binary.LittleEndian.PutUint64(b[:8], schedule.Seed)
+	binary.LittleEndian.PutUint64(b[8:], uint64(t.Unix()))
+	h := fnv.New64a()
+	h.Write(b[:])
+	return time.Duration(h.Sum64()%(n+1)) * time.Second
+}
+
+// Start returns the first delayed activation, for a scheduler that starts
+// running the schedule at the given time.
+func (schedule JitterSchedule) Start(t time.Time) time.Time {
+	if s, ok := schedule.Schedule.(StartSchedule); ok {
+		if start := s.Start(t); !start.IsZero() {
+			return start.Add(schedule.delay(start))
+		}
+		return time.Time{}
+	}
+	return schedule.Next(t)
+}
+
+// Next returns the first delayed activation after the given time.
+func (schedule JitterSchedule) Next(t time.Time) time.Time {
+	switch schedule.Schedule.(type) {
+	case ConstantDelaySchedule, FixedDelaySchedule:
+		// Delays count from the given time, so only delay the next one.
+		next := schedule.Schedule.Next(t)
+		return next.Add(schedule.delay(next))
+	}
+
+	// Activations up to Max before the given time may be delayed after it,
+	// and later activations delayed less than earlier ones, so look on until
+	// no later activation could come first.
+	var next time.Time
+	for a := schedule.Schedule.Next(t.Add(-schedule.Max)); !a.IsZero() && (next.IsZero() || a.Before(next)); a = schedule.Schedule.Next(a) {
+		if delayed := a.Add(schedule.delay(a)); delayed.After(t) {
+			next = earliest(next, delayed)
+		}
+	}
+	return next
+}
+
+// AfterCompletion returns true if the jittered schedule counts its
+// activations from the completion of each run, as FixedDelaySchedule does.
+func (schedule JitterSchedule) AfterCompletion() bool {
+	s, ok := schedule.Schedule.(CompletionSchedule)
+	return ok && s.AfterCompletion()
+}
+
+// String returns the spec of the schedule followed by its jitter, e.g.
+// "@hourly ~5m".
+func (schedule JitterSchedule) String() string {
+	jitter := " ~"
+	if schedule.Hashed {
+		jitter += "H"
+	}
+	return fmt.Sprint(schedule.Schedule) + jitter + shortDuration(schedule.Max)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning the spec.
+func (schedule JitterSchedule) MarshalText() ([]byte, error) {
+	return []byte(schedule.String()), nil
+}
+
+// Describe describes the schedule and its jitter.
+func (schedule JitterSchedule) Describe(lang Language) string {
+	description := lang.describeOperand(schedule.Schedule, false)
+	return description + lang.pick(", delayed at random by up to ", "，随机延迟至多") + lang.duration(schedule.Max)
+}
+
+// splitJitter splits the "~" duration suffix from the spec, returning the
+// rest of the spec and the suffix without its "~", or false if there is none.
+func splitJitter(spec string) (string, string, bool) {
+	spec = strings.TrimRight(spec, " \t\n")
+	i := strings.LastIndexAny(spec, " \t\n")
+	if i < 0 || !strings.HasPrefix(spec[i+1:], "~") {
+		return spec, "", false
+	}
+	return strings.TrimRight(spec[:i], " \t\n"), spec[i+2:], true
+}
+
+// parseJitter parses the spec with its "~" duration suffix, given the
+// function parsing the rest of it.  A "~H" suffix derives the delays from the
+// seed.
+func parseJitter(spec, seed string, parse func(string) (Schedule, error)) (Schedule, error) {
+	rest, suffix, _ := splitJitter(spec)
+	s, err := parse(rest)
+	if err != nil {
+		if perr, ok := err.(*ParseError); ok {
+			perr.Spec = spec
+		}
+		return nil, err
+	}
+
+	hashed := strings.HasPrefix(suffix, "H")
+	value := strings.TrimPrefix(suffix, "H")
+	start := strings.LastIndex(spec, "~")
+	end := start + 1 + len(suffix)
+	max, err := time.ParseDuration(value)
+	if err != nil {
+		err = parseErrorf(ReasonDuration, "", "failed to parse jitter %s: %s", value, err)
+		return nil, locate(err, spec, FieldJitter, start, end)
+	}
+	if max < time.Second {
+		err = parseErrorf(ReasonDuration, "", "jitter must be at least a second: %s", spec)
+		return nil, locate(err, spec, FieldJitter, start, end)
+	}
+	if hashed {
+		return HashedJitter(s, max, seed), nil
+	}
+	return Jitter(s, max), nil
+}
@@ -0,0 +1,169 @@
+package cron
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestJitterNext(t *testing.T) {
+	tests := []struct {
+		spec string
+		max  time.Duration
+	}{
+		{"0 * * * *", 5 * time.Minute},
+		{"* * * * *", 5 * time.Minute},
+		{"0 9 * * 1-5", time.Hour},
+	}
+	from := getTime("Mon Jul 9 00:00 2012")
+	to := getTime("Mon Jul 16 00:00 2012")
+	for _, c := range tests {
+		sched, err := ParseStandard(c.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := HashedJitter(sched, c.max, c.spec)
+
+		// The delayed activations are those of the schedule, each delayed by
+		// up to max, in order.
+		var expected []time.Time
+		for _, a := range Between(sched, from.Add(-c.max), to) {
+			delay := s.delay(a)
+			if delay < 0 || delay > c.max || delay%time.Second != 0 {
+				t.Fatalf("%s: delay %v out of range", c.spec, delay)
+			}
+			if d := a.Add(delay); d.After(from) && !d.After(to) {
+				expected = append(expected, d)
+			}
+		}
+		sort.Slice(expected, func(i, j int) bool { return expected[i].Before(expected[j]) })
+
+		// Activations delayed to the same time run once.
+		for i := 1; i < len(expected); i++ {
+			if expected[i].Equal(expected[i-1]) {
+				expected = append(expected[:i], expected[i+1:]...)
+				i--
+			}
+		}
+		actual := Between(s, from, to)
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%s: expected %d activations from %v, got %d from %v", c.spec, len(expected), expected[0], len(actual), actual[0])
+		}
+		if next := s.Next(actual[1].Add(-time.Second)); !next.Equal(actual[1]) {
+			t.Errorf("%s: expected %v again, got %v", c.spec, actual[1], next)
+		}
+	}
+}
+
+func TestJitterSeeds(t *testing.T) {
+	hourly, _ := ParseStandard("@hourly")
+	from := getTime("Mon Jul 9 00:00 2012")
+	a := NextN(HashedJitter(hourly, 5*time.Minute, "a"), from, 24)
+	b := NextN(HashedJitter(hourly, 5*time.Minute, "b"), from, 24)
+	if reflect.DeepEqual(a, b) {
+		t.Error("expected different seeds to give different delays")
+	}
+	if again := NextN(HashedJitter(hourly, 5*time.Minute, "a"), from, 24); !reflect.DeepEqual(a, again) {
+		t.Error("expected the same seed to give the same delays")
+	}
+	if Jitter(hourly, time.Minute).Seed == Jitter(hourly, time.Minute).Seed {
+		t.Error("expected random seeds")
+	}
+}
+
+func TestJitterStartAndIntervals(t *testing.T) {
+	now := getTime("Mon Jul 9 14:45 2012")
+	reboot := HashedJitter(RebootSchedule{}, 30*time.Second, "reboot")
+	if start := reboot.Start(now); start.Before(now) || start.After(now.Add(30*time.Second)) {
+		t.Errorf("expected a start within 30s of %v, got %v", now, start)
+	}
+	every := HashedJitter(Every(time.Hour), 5*time.Minute, "every")
+	if next := every.Next(now); next.Before(now.Add(time.Hour)) || next.After(now.Add(65*time.Minute)) {
+		t.Errorf("expected the next run 60 to 65 minutes after %v, got %v", now, next)
+	}
+	hourly, _ := ParseStandard("@hourly")
+	if start, next := HashedJitter(hourly, time.Minute, "x").Start(now), HashedJitter(hourly, time.Minute, "x").Next(now); !start.Equal(next) {
+		t.Errorf("expected Start to be Next, got %v and %v", start, next)
+	}
+}
+
+func TestParseJitter(t *testing.T) {
+	sched, err := ParseStandard("@hourly ~5m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, ok := sched.(JitterSchedule)
+	if !ok || s.Max != 5*time.Minute || s.Hashed {
+		t.Fatalf("expected a random jitter of 5m, got %#v", sched)
+	}
+	if s.String() != "0 * * * * ~5m" {
+		t.Errorf("expected %q, got %q", "0 * * * * ~5m", s.String())
+	}
+
+	sched, err = standardParser.ParseWithSeed("TZ=UTC 0 9 * * 1-5 | 0 12 * * 6  ~H10m ", "report")
+	if err != nil {
+		t.Fatal(err)
+	}
+	inner, _ := ParseStandard("TZ=UTC 0 9 * * 1-5 | 0 12 * * 6")
+	if expected := HashedJitter(inner, 10*time.Minute, "report"); !reflect.DeepEqual(sched, expected) {
+		t.Errorf("expected %v, got %v", expected, sched)
+	}
+	if text := sched.(JitterSchedule).String(); text != "TZ=UTC 0 9 * * mon-fri | TZ=UTC 0 12 * * sat ~H10m" {
+		t.Errorf("unexpected spec %q", text)
+	}
+	if parsed, err := parseText(sched.(JitterSchedule).String()); err != nil || !parsed.(JitterSchedule).Hashed {
+		t.Errorf("expected the spec to parse back, got %v, %v", parsed, err)
+	}
+
+	tests := []struct {
+		spec   string
+		field  SpecField
+		reason ParseErrorReason
+		offset int
+	}{
+		{"@hourly ~5x", FieldJitter, ReasonDuration, 8},
+		{"@hourly ~500ms", FieldJitter, ReasonDuration, 8},
+		{"0 61 * * * ~5m", FieldHour, ReasonAboveMaximum, 2},
+	}
+	for _, c := range tests {
+		_, err := ParseStandard(c.spec)
+		perr, ok := err.(*ParseError)
+		if !ok {
+			t.Errorf("%s: expected a parse error, got %v", c.spec, err)
+			continue
+		}
+		if perr.Spec != c.spec || perr.Field != c.field || perr.Reason != c.reason || perr.Offset != c.offset {
+			t.Errorf("%s: unexpected error %+v", c.spec, perr)
+		}
+	}
+}
+
+func TestJitterDescribe(t *testing.T) {
+	hourly, _ := ParseStandard("0 * * * *")
+	s := HashedJitter(hourly, 5*time.Minute, "")
+	if actual, expected := Describe(s), "Every hour, delayed at random by up to 5 minutes"; actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+}
+
+// Entries show their delayed activations, seeded by their titles.
+func TestJitterEntry(t *testing.T) {
+	cron := New()
+	id, err := cron.AddFunc("report", "@hourly ~H5m", func(context.Context) error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	hourly, _ := ParseStandard("@hourly")
+	expected := HashedJitter(hourly, 5*time.Minute, "report")
+	if actual := cron.Entry(id).Schedule; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+	cron.Start(context.TODO())
+	defer cron.Stop(context.TODO())
+	entry := cron.Entry(id)
+	if next := expected.Next(entry.Next.Add(-time.Second)); !next.Equal(entry.Next) {
+		t.Errorf("expected Next to be a delayed activation, got %v", entry.Next)
+	}
+}
@@ -0,0 +1,202 @@
+package cron
+
+import (
+	"fmt"
+	"time"
+)
+
+// WarningCode is a machine-readable code for the problem found by Lint.
+type WarningCode string
+
+const (
+	WarningInvalid     WarningCode = "invalid"      // the spec does not parse
+	WarningNeverFires  WarningCode = "never_fires"  // no month has one of the days, e.g. "30 feb"
+	WarningSkipsMonths WarningCode = "skips_months" // some months do not have one of the days, e.g. "31"
+	WarningDomOrDow    WarningCode = "dom_or_dow"   // both day fields restricted: either may match
+	WarningEverySecond WarningCode = "every_second" // "*" in the seconds field
+	WarningEveryMinute WarningCode = "every_minute" // "*" in the minutes field with particular hours
+	WarningUnevenStep  WarningCode = "uneven_step"  // a step that does not divide its field
+	WarningDSTGap      WarningCode = "dst_gap"      // a time skipped by daylight saving time
+)
+
+// Warning is a problem found by Lint in a spec.
+type Warning struct {
+	Code    WarningCode
+	Field   SpecField
+	Message string
+}
+
+// String returns the message of the warning.
+func (w Warning) String() string {
+	return w.Message
+}
+
+// lintYears is how far ahead Lint looks for daylight saving time changes.
+const lintYears = 1
+
+// Lint parses the spec with a Parser configured with the given options, and
+// returns warnings for specs which parse but are most likely mistakes:
+//
+//   - days which never occur in the given months, e.g. "0 0 30 2 *"
+//   - restricting both the day of month and the day of week, which runs the
+//     job on days matching either of them
+//   - "*" in the seconds field, or in the minutes field with particular hours
+//   - steps which do not divide their field evenly, e.g. "*/7" minutes, which
+//     runs at :56 and then at :00
+//   - times skipped by daylight saving time changes in the schedule's
+//     location over the next year
+//
+// If the spec does not parse, Lint returns a single WarningInvalid.
+func Lint(spec string, opts ParseOption) []Warning {
+	schedule, err := NewParser(opts).Parse(spec)
+	if err != nil {
+		w := Warning{Code: WarningInvalid, Field: FieldSpec, Message: err.Error()}
+		if perr, ok := err.(*ParseError); ok {
+			w.Field = perr.Field
+		}
+		return []Warning{w}
+	}
+	return lintSchedule(schedule, time.Now())
+}
+
+// lintSchedule returns the warnings for the given schedule, looking for
+// daylight saving time changes in the year after now.
+func lintSchedule(schedule Schedule, now time.Time) []Warning {
+	s, ok := schedule.(*SpecSchedule)
+	if !ok {
+		return nil
+	}
+	var warnings []Warning
+	warn := func(code WarningCode, field SpecField, format string, args ...interface{}) {
+		warnings = append(warnings, Warning{code, field, fmt.Sprintf(format, args...)})
+	}
+
+	// Days which do not exist in some of the months.
+	if s.Dom&starBit == 0 && s.Dow&starBit > 0 {
+		var missing []string
+		monthValues := fieldValues(s.Month, months)
+		for _, m := range monthValues {
+			if !domOccurs(s, time.Month(m)) {
+				missing = append(missing, time.Month(m).String())
+			}
+		}
+		switch {
+		case len(missing) == len(monthValues):
+			warn(WarningNeverFires, FieldDom, "the day of month never occurs in %s, so the schedule never fires",
+				English.join(missing))
+		case len(missing) > 0:
+			warn(WarningSkipsMonths, FieldDom, "the day of month does not occur in %s, which are skipped; use L for the last day of the month",
+				English.join(missing))
+		}
+	}
+
+	// Both day fields restricted.
+	if s.Dom&starBit == 0 && s.Dow&starBit == 0 {
+		warn(WarningDomOrDow, FieldDow, "both the day of month and the day of week are restricted, so the job runs on days matching either of them, not both")
+	}
+
+	// Stars in the seconds and minutes fields.
+	if s.Second&starBit > 0 {
+		warn(WarningEverySecond, FieldSecond, "\"*\" in the seconds field runs the job every second; use 0 to run it once a minute")
+	}
+	if s.Minute&starBit > 0 && s.Hour&starBit == 0 {
+		warn(WarningEveryMinute, FieldMinute, "\"*\" in the minutes field runs the job every minute of the given hours; use 0 to run it once an hour")
+	}
+
+	// Uneven steps.
+	for _, f := range []struct {
+		field SpecField
+		bits  uint64
+		r     bounds
+	}{
+		{FieldSecond, s.Second, seconds},
+		{FieldMinute, s.Minute, minutes},
+		{FieldHour, s.Hour, hours},
+		{FieldDom, s.Dom, dom},
+		{FieldMonth, s.Month, months},
+		{FieldDow, s.Dow, dow},
+	} {
+		values := fieldValues(f.bits, f.r)
+		if step, gap := unevenStep(values, f.r); step > 0 {
+			warn(WarningUnevenStep, f.field, "the step %d does not divide the %s field (%d-%d) evenly: %d is followed by %d, %d later",
+				step, f.field, f.r.min, f.r.max, values[len(values)-1], values[0], gap)
+		}
+	}
+
+	// Times skipped by daylight saving time, unless the schedule runs them at
+	// the end of the gap.  As with Next, schedules in time.Local are read in
+	// the location of the given time.
+	loc := s.Location
+	if loc == nil || loc == time.Local {
+		loc = now.Location()
+	}
+	if s.DST.Gap == GapShift {
+		return warnings
+	}
+	for _, gap := range dstGaps(loc, now, now.AddDate(lintYears, 0, 0)) {
+		if t, ok := firstMatch(s, gap.start, gap.length); ok {
+			warn(WarningDSTGap, FieldHour, "the job would run at %s on %s, which is skipped by the daylight saving time change in %s",
+				t.Format("15:04:05"), t.Format("2006-01-02"), loc)
+		}
+	}
+	return warnings
+}
+
+// domOccurs returns true if any of the schedule's days of month occur in the
+// given month, in a leap year.
+func domOccurs(s *SpecSchedule, m time.Month) bool {
+	last := daysIn(m, 2000)
+	if s.Dom&getBits(dom.min, uint(last), 1) > 0 {
+		return true
+	}
+	for _, r := range s.DomRules {
+		switch {
+		case r.Kind == LastDayOfMonth && r.N >= last:
+		case r.Kind == NearestWeekday && r.N > last:
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// unevenStep returns the step of values such as "*/7" or "3/7", which step
+// from the start of their field to its end, if the step does not divide the
+// field evenly.  It also returns the gap from the last value to the first
+// after the field wraps around.
+func unevenStep(values []int, r bounds) (step, gap int) {
+	if len(values) < 2 {
+		return 0, 0
+	}
+	var (
+		first = values[0]
+		last  = values[len(values)-1]
+	)
+	step = values[1] - values[0]
+	for i := 2; i < len(values); i++ {
+		if values[i]-values[i-1] != step {
+			return 0, 0
+		}
+	}
+	gap = int(r.max) + 1 - last + first - int(r.min)
+	if step < 2 || last+step <= int(r.max) || first-int(r.min) >= step || gap == step {
+		return 0, 0
+	}
+	return step, gap
+}
+
+// firstMatch returns the first second within the given length of start, a
+// wall clock time in UTC, at which the schedule would run.
+func firstMatch(s *SpecSchedule, start time.Time, length time.Duration) (time.Time, bool) {
+	for t := start; t.Before(start.Add(length)); t = t.Add(time.Second) {
+		if yearMatches(s, t.Year()) &&
+			1<<uint(t.Month())&s.Month > 0 &&
+			dayMatches(s, t) &&
+			1<<uint(t.Hour())&s.Hour > 0 &&
+			1<<uint(t.Minute())&s.Minute > 0 &&
+			1<<uint(t.Second())&s.Second > 0 {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
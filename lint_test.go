@@ -0,0 +1,98 @@
+package cron
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		spec     string
+		opts     ParseOption
+		expected []WarningCode
+	}{
+		{"0 9 * * 1-5", standardParser.options, nil},
+		{"0 0 30 2 *", standardParser.options, []WarningCode{WarningNeverFires}},
+		{"0 0 30,31 feb *", standardParser.options, []WarningCode{WarningNeverFires}},
+		{"0 0 29 2 *", standardParser.options, nil},
+		{"0 0 31 * *", standardParser.options, []WarningCode{WarningSkipsMonths}},
+		{"0 0 L * *", standardParser.options, nil},
+		{"0 0 30W 2 *", standardParser.options, []WarningCode{WarningNeverFires}},
+		{"0 0 L-29 2 *", standardParser.options, []WarningCode{WarningNeverFires}},
+		{"0 0 1,15 * mon", standardParser.options, []WarningCode{WarningDomOrDow}},
+		{"0 0 ? * mon", standardParser.options, nil},
+		{"* 0 9 * * *", secondParser.options, []WarningCode{WarningEverySecond}},
+		{"0 * 9 * * *", secondParser.options, []WarningCode{WarningEveryMinute}},
+		{"* * * * *", standardParser.options, nil},
+		{"*/7 * * * *", standardParser.options, []WarningCode{WarningUnevenStep}},
+		{"3/7 * * * *", standardParser.options, []WarningCode{WarningUnevenStep}},
+		{"*/15 * * * *", standardParser.options, nil},
+		{"5/15 * * * *", standardParser.options, nil},
+		{"0 9-17/5 * * *", standardParser.options, nil},
+		{"0 0 */5 * *", standardParser.options, []WarningCode{WarningUnevenStep}},
+		{"0 */5 * * *", standardParser.options, []WarningCode{WarningUnevenStep}},
+		{"0 0 1 */5 *", standardParser.options, []WarningCode{WarningUnevenStep}},
+		{"@every 7m", standardParser.options, nil},
+		{"0 25 * * *", standardParser.options, []WarningCode{WarningInvalid}},
+	}
+
+	for _, c := range tests {
+		var actual []WarningCode
+		for _, w := range Lint(c.spec, c.opts) {
+			actual = append(actual, w.Code)
+		}
+		if len(actual) != len(c.expected) {
+			t.Errorf("%s: expected %v, got %v", c.spec, c.expected, actual)
+			continue
+		}
+		for i := range actual {
+			if actual[i] != c.expected[i] {
+				t.Errorf("%s: expected %v, got %v", c.spec, c.expected, actual)
+			}
+		}
+	}
+}
+
+func TestLintInvalidField(t *testing.T) {
+	warnings := Lint("0 25 * * *", standardParser.options)
+	if len(warnings) != 1 || warnings[0].Field != FieldHour || !strings.Contains(warnings[0].Message, "above maximum") {
+		t.Errorf("expected a warning for the hour field, got %v", warnings)
+	}
+}
+
+func TestLintDSTGap(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, time.October, 16, 0, 0, 0, 0, ny)
+	tests := []struct {
+		spec     string
+		expected string
+	}{
+		{"TZ=America/New_York 30 2 * * *", "02:30:00 on 2027-03-14"},
+		{"30 2 * * *", "02:30:00 on 2027-03-14"},
+		{"TZ=America/New_York 30 2 * * 1-5", ""},
+		{"TZ=America/New_York 30 2 8-14 3 0", "02:30:00 on 2027-03-14"},
+		{"TZ=America/New_York 30 1 * * *", ""},
+		{"TZ=UTC 30 2 * * *", ""},
+		{"TZ=America/New_York */5 * * * *", "02:00:00 on 2027-03-14"},
+	}
+
+	for _, c := range tests {
+		s, err := standardParser.Parse(c.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var actual string
+		for _, w := range lintSchedule(s, now) {
+			if w.Code == WarningDSTGap {
+				actual = w.Message
+			}
+		}
+		if c.expected == "" && actual != "" || !strings.Contains(actual, c.expected) {
+			t.Errorf("%s: expected a warning about %q, got %q", c.spec, c.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,646 @@
+package cron
+
+import (
+	"fmt"
+	"math/bits"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NaturalParser is a ScheduleParser for schedules written as English phrases,
+// such as:
+//
+//	every weekday at 9:30
+//	every 2 hours between 8am and 6pm
+//	on the first monday of each month at noon
+//	every 15 minutes on weekends in Asia/Shanghai
+//
+// A phrase is a sequence of clauses, in any order:
+//
+//	every [N] second|minute|hour|day|week|month|year   how often it runs
+//	every|on <days>                                    e.g. "monday and friday", "weekdays"
+//	on the <ordinal> [day|weekday|<day>] of the month  e.g. "the 1st and 15th", "the last friday"
+//	at <time> [and <time>...]                          e.g. "9:30", "5pm", "noon"
+//	between|from <time> and|to <time>                  limits an hourly or faster schedule
+//	in <months>|<time zone>                            e.g. "january and july", "Asia/Tokyo"
+//	hourly|daily|weekly|monthly|yearly
+//
+// Phrases compile to a SpecSchedule where possible, and to a
+// ConstantDelaySchedule for intervals that do not divide their unit, e.g.
+// "every 7 minutes".  The "TZ=" prefix is accepted as with Parser.
+type NaturalParser struct {
+	// Fallback, if set, parses specs that do not start like a phrase, such
+	// as crontab specs and descriptors.
+	Fallback ScheduleParser
+}
+
+// Parse returns the schedule described by the given phrase, or a *ParseError
+// locating the word which is not understood.
+func (p NaturalParser) Parse(spec string) (Schedule, error) {
+	return p.ParseWithSeed(spec, "")
+}
+
+// ParseWithSeed is like Parse, but passes the seed on to the Fallback parser
+// if it accepts one.
+func (p NaturalParser) ParseWithSeed(spec, seed string) (Schedule, error) {
+	spans := fieldSpans(strings.Replace(spec, ",", " ", -1))
+	if len(spans) == 0 {
+		return nil, &ParseError{Spec: spec, Field: FieldSpec, Reason: ReasonEmpty, Message: "empty spec string"}
+	}
+	loc, rest, err := parseTimezone(strings.TrimSpace(spec))
+	if err != nil {
+		return nil, locate(err, spec, FieldTimezone, spans[0].start, spans[0].end)
+	}
+	raw := strings.Fields(strings.Replace(rest, ",", " ", -1))
+	if len(raw) == 0 {
+		return nil, &ParseError{Spec: spec, Field: FieldSpec, Reason: ReasonEmpty, Message: "empty spec string"}
+	}
+	spans = spans[len(spans)-len(raw):]
+	if !naturalKeywords[strings.ToLower(raw[0])] && p.Fallback != nil {
+		if fallback, ok := p.Fallback.(SeededScheduleParser); ok {
+			return fallback.ParseWithSeed(spec, seed)
+		}
+		return p.Fallback.Parse(spec)
+	}
+
+	ph := &phrase{raw: raw, loc: loc}
+	for _, w := range raw {
+		ph.words = append(ph.words, strings.ToLower(w))
+	}
+	if err := ph.parse(); err != nil {
+		return nil, ph.locate(err, spec, rest, spans)
+	}
+	schedule, err := ph.schedule()
+	if err != nil {
+		return nil, ph.locate(err, spec, rest, spans)
+	}
+	return schedule, nil
+}
+
+// naturalKeywords are the words a phrase may start with.
+var naturalKeywords = map[string]bool{
+	"every": true, "each": true, "on": true, "at": true, "between": true, "from": true, "in": true,
+	"hourly": true, "daily": true, "weekly": true, "monthly": true, "yearly": true, "annually": true,
+}
+
+// naturalUnits maps the units of "every N <unit>" to their names.
+var naturalUnits = map[string]string{
+	"second": "second", "seconds": "second",
+	"minute": "minute", "minutes": "minute",
+	"hour": "hour", "hours": "hour",
+	"day": "day", "days": "day",
+	"week": "week", "weeks": "week",
+	"month": "month", "months": "month",
+	"year": "year", "years": "year",
+}
+
+// naturalAdverbs maps words such as "hourly" to their units.
+var naturalAdverbs = map[string]string{
+	"hourly": "hour", "daily": "day", "weekly": "week", "monthly": "month", "yearly": "year", "annually": "year",
+}
+
+// naturalNumbers are the numbers that may be spelled out.
+var naturalNumbers = map[string]int{
+	"one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6,
+	"seven": 7, "eight": 8, "nine": 9, "ten": 10, "eleven": 11, "twelve": 12,
+	"fifteen": 15, "twenty": 20, "thirty": 30, "other": 2,
+}
+
+// naturalOrdinals are the ordinals that may be spelled out; -1 is "last".
+var naturalOrdinals = map[string]int{
+	"first": 1, "second": 2, "third": 3, "fourth": 4, "fifth": 5, "last": -1,
+}
+
+// naturalWeekdays maps day names, abbreviations and plurals to days of the week.
+var naturalWeekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sundays": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mondays": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tuesdays": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wednesdays": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thursdays": time.Thursday, "thu": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fridays": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "saturdays": time.Saturday, "sat": time.Saturday,
+}
+
+// naturalMonths maps month names and abbreviations to months.
+var naturalMonths = map[string]time.Month{
+	"january": time.January, "february": time.February, "march": time.March,
+	"april": time.April, "may": time.May, "june": time.June,
+	"july": time.July, "august": time.August, "september": time.September,
+	"october": time.October, "november": time.November, "december": time.December,
+}
+
+var (
+	clockPattern   = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(?::(\d{2}))?(am|pm)?$`)
+	ordinalPattern = regexp.MustCompile(`^(\d{1,2})(st|nd|rd|th)$`)
+)
+
+// clock is a time of day.
+type clock struct {
+	hour, minute, second uint
+}
+
+// phrase holds the state of parsing a natural language schedule.
+type phrase struct {
+	raw, words []string
+	pos        int
+
+	unit   string // from "every N <unit>", or "" if not given
+	step   int
+	times  []clock
+	window []clock // start and end of "between", if given
+
+	dow, dom           uint64
+	dowRules, domRules []DayRule
+	months             uint64
+	loc                *time.Location
+
+	failed int // the word at which parsing failed, from 1, or 0 for the whole phrase
+}
+
+// errorf returns a ParseError for the word just read, or for the end of the
+// phrase if there is none left.
+func (p *phrase) errorf(reason ParseErrorReason, format string, args ...interface{}) *ParseError {
+	p.failed = p.pos
+	var token string
+	if p.pos > 0 && p.pos <= len(p.raw) {
+		token = p.raw[p.pos-1]
+	}
+	return parseErrorf(reason, token, format, args...)
+}
+
+// locate locates the error at the word where parsing failed, given the spans
+// of the words in the spec, and appends the phrase to its message.
+func (p *phrase) locate(err error, spec, phrase string, spans []span) error {
+	perr, ok := err.(*ParseError)
+	if !ok {
+		perr = parseErrorf(ReasonSyntax, "", "%v", err)
+	}
+	perr.Message += ": " + phrase
+	start, end := spans[0].start, spans[len(spans)-1].end
+	switch {
+	case p.failed > len(spans):
+		start = end
+	case p.failed > 0:
+		start, end = spans[p.failed-1].start, spans[p.failed-1].end
+	}
+	return locate(perr, spec, FieldSpec, start, end)
+}
+
+func (p *phrase) done() bool { return p.pos >= len(p.words) }
+
+func (p *phrase) peek() string {
+	if p.done() {
+		return ""
+	}
+	return p.words[p.pos]
+}
+
+func (p *phrase) next() string {
+	w := p.peek()
+	p.pos++
+	return w
+}
+
+// unexpected returns an error for the word just read.
+func (p *phrase) unexpected(what string) error {
+	if p.pos > len(p.words) {
+		return p.errorf(ReasonUnexpected, "expected %s at end of phrase", what)
+	}
+	return p.errorf(ReasonUnexpected, "expected %s, found %q (word %d)", what, p.raw[p.pos-1], p.pos)
+}
+
+// parse reads all the clauses of the phrase.
+func (p *phrase) parse() error {
+	for !p.done() {
+		var err error
+		switch w := p.next(); {
+		case w == "every" || w == "each":
+			err = p.parseEvery()
+		case w == "on":
+			err = p.parseOn()
+		case w == "at":
+			err = p.parseTimes()
+		case w == "between" || w == "from":
+			err = p.parseWindow(w)
+		case w == "in":
+			err = p.parseIn()
+		case naturalAdverbs[w] != "":
+			err = p.setUnit(naturalAdverbs[w], 1)
+		default:
+			err = p.unexpected(`"every", "on", "at", "between" or "in"`)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setUnit records how often the schedule runs.
+func (p *phrase) setUnit(unit string, step int) error {
+	if p.unit != "" {
+		return p.errorf(ReasonUnexpected, "frequency given more than once (word %d)", p.pos)
+	}
+	p.unit, p.step = unit, step
+	return nil
+}
+
+// parseEvery reads the clause after "every":
+//   [ number ] unit | days
+func (p *phrase) parseEvery() error {
+	w := p.next()
+	if n, ok := parseNaturalNumber(w); ok {
+		if n <= 0 {
+			return p.errorf(ReasonBelowMinimum, "frequency must be positive (word %d)", p.pos)
+		}
+		unit := naturalUnits[p.next()]
+		if unit == "" {
+			return p.unexpected("a unit such as \"minutes\" or \"hours\"")
+		}
+		return p.setUnit(unit, n)
+	}
+	if unit := naturalUnits[w]; unit != "" {
+		return p.setUnit(unit, 1)
+	}
+	p.pos--
+	return p.parseDays()
+}
+
+// parseDays reads a list of days of the week:
+//   ( weekday | "weekday" | "weekend" ) [ "and" ] ...
+func (p *phrase) parseDays() error {
+	for {
+		w := p.next()
+		switch wd, ok := naturalWeekdays[w]; {
+		case ok:
+			p.dow |= 1 << uint(wd)
+		case w == "weekday" || w == "weekdays":
+			p.dow |= getBits(1, 5, 1)
+		case w == "weekend" || w == "weekends":
+			p.dow |= 1<<0 | 1<<6
+		default:
+			return p.unexpected("a day of the week")
+		}
+		if p.peek() == "and" && p.pos+1 < len(p.words) && isNaturalDay(p.words[p.pos+1]) {
+			p.pos++
+		}
+		if !isNaturalDay(p.peek()) {
+			return nil
+		}
+	}
+}
+
+func isNaturalDay(w string) bool {
+	_, ok := naturalWeekdays[w]
+	return ok || w == "weekday" || w == "weekdays" || w == "weekend" || w == "weekends"
+}
+
+// parseOn reads the clause after "on":
+//   days | "the" ordinal [ "day" | "weekday" | weekday ] [ "and" ordinal ... ] [ "of" ( "the" | "each" | "every" ) "month" ]
+func (p *phrase) parseOn() error {
+	if p.peek() != "the" {
+		return p.parseDays()
+	}
+	p.pos++
+	for {
+		n, ok := parseNaturalOrdinal(p.next())
+		if !ok {
+			return p.unexpected("an ordinal such as \"first\" or \"15th\"")
+		}
+		w := p.peek()
+		switch wd, isWeekday := naturalWeekdays[w]; {
+		case isWeekday:
+			p.pos++
+			if n > 5 {
+				return p.errorf(ReasonAboveMaximum, "a month has at most 5 of each day of the week (word %d)", p.pos)
+			}
+			rule := DayRule{Kind: NthDayOfWeek, N: n, Weekday: wd}
+			if n < 0 {
+				rule = DayRule{Kind: LastDayOfWeek, Weekday: wd}
+			}
+			p.dowRules = append(p.dowRules, rule)
+		case w == "weekday":
+			p.pos++
+			switch n {
+			case 1:
+				p.domRules = append(p.domRules, DayRule{Kind: NearestWeekday, N: 1})
+			case -1:
+				p.domRules = append(p.domRules, DayRule{Kind: LastWeekdayOfMonth})
+			default:
+				return p.errorf(ReasonUnexpected, "only the first or last weekday of the month is supported (word %d)", p.pos)
+			}
+		default:
+			if w == "day" {
+				p.pos++
+			}
+			if n < 0 {
+				p.domRules = append(p.domRules, DayRule{Kind: LastDayOfMonth})
+			} else {
+				p.dom |= 1 << uint(n)
+			}
+		}
+		if p.peek() != "and" {
+			break
+		}
+		p.pos++
+	}
+	if p.peek() == "of" {
+		p.pos++
+		if w := p.next(); w != "the" && w != "each" && w != "every" {
+			return p.unexpected(`"the month" or "each month"`)
+		}
+		if p.next() != "month" {
+			return p.unexpected(`"month"`)
+		}
+	}
+	return nil
+}
+
+// parseTimes reads the clause after "at":
+//   time [ "and" time ... ]
+func (p *phrase) parseTimes() error {
+	for {
+		c, err := p.parseClock()
+		if err != nil {
+			return err
+		}
+		p.times = append(p.times, c)
+		if p.peek() != "and" || p.pos+1 >= len(p.words) || !isNaturalClock(p.words[p.pos+1]) {
+			return nil
+		}
+		p.pos++
+	}
+}
+
+// parseWindow reads the clause after "between" or "from":
+//   time ( "and" | "to" | "until" ) time
+func (p *phrase) parseWindow(keyword string) error {
+	if p.window != nil {
+		return p.errorf(ReasonUnexpected, "%q given more than once (word %d)", keyword, p.pos)
+	}
+	start, err := p.parseClock()
+	if err != nil {
+		return err
+	}
+	if w := p.next(); w != "and" && w != "to" && w != "until" {
+		return p.unexpected(`"and" or "to"`)
+	}
+	end, err := p.parseClock()
+	if err != nil {
+		return err
+	}
+	if start.minute != 0 || start.second != 0 || end.minute != 0 || end.second != 0 {
+		return p.errorf(ReasonSyntax, "%s ... must start and end on the hour (word %d)", keyword, p.pos)
+	}
+	if start.hour > end.hour {
+		return p.errorf(ReasonReversedRange, "%s ... must not cross midnight (word %d)", keyword, p.pos)
+	}
+	p.window = []clock{start, end}
+	return nil
+}
+
+// parseIn reads the clause after "in":
+//   month [ "and" month ... ] | time zone
+func (p *phrase) parseIn() error {
+	if _, ok := naturalMonths[p.peek()]; !ok {
+		if p.next() == "" {
+			return p.unexpected("a month or a time zone")
+		}
+		loc, err := time.LoadLocation(p.raw[p.pos-1])
+		if err != nil {
+			return p.unexpected("a month or a time zone")
+		}
+		p.loc = loc
+		return nil
+	}
+	for {
+		p.months |= 1 << uint(naturalMonths[p.next()])
+		if p.peek() == "and" && p.pos+1 < len(p.words) {
+			if _, ok := naturalMonths[p.words[p.pos+1]]; ok {
+				p.pos++
+			}
+		}
+		if _, ok := naturalMonths[p.peek()]; !ok {
+			return nil
+		}
+	}
+}
+
+// parseClock reads a time of day such as "9:30", "5pm", "5 pm" or "noon".
+func (p *phrase) parseClock() (clock, error) {
+	w := p.next()
+	switch w {
+	case "noon", "midday":
+		return clock{hour: 12}, nil
+	case "midnight":
+		return clock{}, nil
+	}
+	m := clockPattern.FindStringSubmatch(w)
+	if m == nil {
+		return clock{}, p.unexpected("a time such as \"9:30\" or \"5pm\"")
+	}
+	suffix := m[4]
+	if suffix == "" && (p.peek() == "am" || p.peek() == "pm") {
+		suffix = p.next()
+	}
+	var c clock
+	h, _ := strconv.Atoi(m[1])
+	c.hour = uint(h)
+	if m[2] != "" {
+		mm, _ := strconv.Atoi(m[2])
+		c.minute = uint(mm)
+	}
+	if m[3] != "" {
+		ss, _ := strconv.Atoi(m[3])
+		c.second = uint(ss)
+	}
+	switch {
+	case suffix != "" && (c.hour < 1 || c.hour > 12):
+		return clock{}, p.errorf(ReasonAboveMaximum, "hour %d out of range (1-12) for %s (word %d)", c.hour, suffix, p.pos)
+	case suffix == "am" && c.hour == 12:
+		c.hour = 0
+	case suffix == "pm" && c.hour != 12:
+		c.hour += 12
+	}
+	if c.hour > hours.max || c.minute > minutes.max || c.second > seconds.max {
+		return clock{}, p.errorf(ReasonAboveMaximum, "invalid time %q (word %d)", w, p.pos)
+	}
+	return c, nil
+}
+
+func isNaturalClock(w string) bool {
+	return w == "noon" || w == "midday" || w == "midnight" || clockPattern.MatchString(w)
+}
+
+// parseNaturalNumber parses a number of units, either as digits or spelled out.
+func parseNaturalNumber(w string) (int, bool) {
+	if n, ok := naturalNumbers[w]; ok {
+		return n, true
+	}
+	n, err := strconv.Atoi(w)
+	return n, err == nil
+}
+
+// parseNaturalOrdinal parses an ordinal such as "first", "2nd" or "last",
+// returning -1 for "last".
+func parseNaturalOrdinal(w string) (int, bool) {
+	if n, ok := naturalOrdinals[w]; ok {
+		return n, true
+	}
+	m := ordinalPattern.FindStringSubmatch(w)
+	if m == nil {
+		return 0, false
+	}
+	n, _ := strconv.Atoi(m[1])
+	return n, n >= int(dom.min) && n <= int(dom.max)
+}
+
+// schedule compiles the parsed phrase.
+func (p *phrase) schedule() (Schedule, error) {
+	var (
+		hasDom = p.dom != 0 || len(p.domRules) > 0
+		hasDow = p.dow != 0 || len(p.dowRules) > 0
+		unit   = p.unit
+	)
+	if hasDom && hasDow {
+		return nil, parseErrorf(ReasonSyntax, "", "cannot restrict both the days of the month and the days of the week")
+	}
+	if unit == "" {
+		if len(p.times) == 0 && !hasDom && !hasDow {
+			return nil, parseErrorf(ReasonSyntax, "", "missing a frequency such as \"every day\" or a time such as \"at 9:30\"")
+		}
+		unit, p.step = "day", 1
+	}
+
+	s := &SpecSchedule{
+		Second:   1 << seconds.min,
+		Minute:   1 << minutes.min,
+		Hour:     1 << hours.min,
+		Dom:      all(dom),
+		Month:    all(months),
+		Dow:      all(dow),
+		Location: p.loc,
+	}
+	if p.months != 0 {
+		s.Month = p.months
+	}
+	if hasDom {
+		s.Dom, s.DomRules = p.dom, p.domRules
+	}
+	if hasDow {
+		s.Dow, s.DowRules = p.dow, p.dowRules
+	}
+
+	switch unit {
+	case "second", "minute", "hour":
+		return p.interval(s, unit)
+	}
+
+	if p.step != 1 {
+		return nil, parseErrorf(ReasonSyntax, "", "every %d %ss cannot be expressed as a schedule; use \"@every\" instead", p.step, unit)
+	}
+	if p.window != nil {
+		return nil, parseErrorf(ReasonSyntax, "", "between ... only applies to hourly or more frequent schedules")
+	}
+	switch unit {
+	case "week":
+		if !hasDow && !hasDom {
+			s.Dow = 1 << dow.min
+		}
+	case "month":
+		if !hasDom && !hasDow {
+			s.Dom = 1 << dom.min
+		}
+	case "year":
+		if p.months == 0 {
+			s.Month = 1 << months.min
+		}
+		if !hasDom && !hasDow {
+			s.Dom = 1 << dom.min
+		}
+	}
+	if len(p.times) > 0 {
+		var err error
+		if s.Hour, s.Minute, s.Second, err = clockBits(p.times); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// interval compiles a phrase repeating every few seconds, minutes or hours.
+func (p *phrase) interval(s *SpecSchedule, unit string) (Schedule, error) {
+	if len(p.times) > 0 {
+		return nil, parseErrorf(ReasonSyntax, "", "at ... cannot be combined with every %s", unit)
+	}
+	size := map[string]int{"second": 60, "minute": 60, "hour": 24}[unit]
+	if p.step >= size {
+		return nil, parseErrorf(ReasonSyntax, "", "every %d %ss is out of range; use \"@every\" instead", p.step, unit)
+	}
+
+	first, last := hours.min, hours.max
+	if p.window != nil {
+		first, last = p.window[0].hour, p.window[1].hour
+		if unit != "hour" {
+			// The window ends at the given hour.
+			if last == first {
+				return nil, parseErrorf(ReasonSyntax, "", "between ... must not be empty")
+			}
+			last--
+		}
+	}
+
+	if size%p.step != 0 && (unit != "hour" || p.window == nil) {
+		if p.window != nil || s.Month != all(months) || s.Dom != all(dom) || s.Dow != all(dow) {
+			return nil, parseErrorf(ReasonSyntax, "", "every %d %ss does not divide an %s evenly, and cannot be combined with other restrictions",
+				p.step, unit, map[string]string{"second": "minute", "minute": "hour", "hour": "day"}[unit])
+		}
+		return Every(time.Duration(p.step) * map[string]time.Duration{
+			"second": time.Second, "minute": time.Minute, "hour": time.Hour,
+		}[unit]), nil
+	}
+
+	var (
+		every = fmt.Sprintf("*/%d", p.step)
+		hour  = "*"
+		err   error
+	)
+	if p.window != nil {
+		hour = fmt.Sprintf("%d-%d", first, last)
+	}
+	switch unit {
+	case "second":
+		s.Second, err = getRange(every, seconds)
+		s.Minute = all(minutes)
+	case "minute":
+		s.Minute, err = getRange(every, minutes)
+	case "hour":
+		hour += every[1:]
+	}
+	if err != nil {
+		return nil, err
+	}
+	if s.Hour, err = getRange(hour, hours); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// clockBits returns the hour, minute and second bits for the given times,
+// provided that they can be expressed by a single schedule.
+func clockBits(times []clock) (hour, minute, second uint64, err error) {
+	distinct := make(map[clock]bool)
+	for _, c := range times {
+		hour |= 1 << c.hour
+		minute |= 1 << c.minute
+		second |= 1 << c.second
+		distinct[c] = true
+	}
+	if count := bits.OnesCount64(hour) * bits.OnesCount64(minute) * bits.OnesCount64(second); count != len(distinct) {
+		return 0, 0, 0, parseErrorf(ReasonSyntax, "", "times must share their minutes or their hours to be combined")
+	}
+	return hour, minute, second, nil
+}
@@ -0,0 +1,179 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNaturalParse(t *testing.T) {
+	spec := func(expr string) Schedule {
+		s, err := secondParser.Parse(expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	entries := []struct {
+		expr     string
+		expected Schedule
+	}{
+		{"every weekday at 9:30", spec("0 30 9 * * 1-5")},
+		{"Every Weekday at 9:30am", spec("0 30 9 * * 1-5")},
+		{"every 2 hours between 8am and 6pm", spec("0 0 8-18/2 * * *")},
+		{"every 15 minutes between 9am and 5pm on weekdays", spec("0 */15 9-16 * * 1-5")},
+		{"on the first monday of each month at noon", spec("0 0 12 * * mon#1")},
+		{"on the last friday of the month at 17:00", spec("0 0 17 * * 5L")},
+		{"on the last day of the month at 23:59:30", spec("30 59 23 L * *")},
+		{"on the first weekday of every month at 8am", spec("0 0 8 1W * *")},
+		{"on the last weekday of the month", spec("0 0 0 LW * *")},
+		{"on the 1st and 15th at 10:00", spec("0 0 10 1,15 * *")},
+		{"every monday, wednesday and friday at 6:30 pm", spec("0 30 18 * * mon,wed,fri")},
+		{"on weekends at 9 and 21:00", spec("0 0 9,21 * * sat,sun")},
+		{"at 9:00 and 9:30", spec("0 0,30 9 * * *")},
+		{"every day at midnight", spec("0 0 0 * * *")},
+		{"daily at 12am", spec("0 0 0 * * *")},
+		{"every minute", spec("0 * * * * *")},
+		{"every 30 seconds", spec("*/30 * * * * *")},
+		{"every hour", spec("0 0 * * * *")},
+		{"hourly", spec("0 0 * * * *")},
+		{"every three hours", spec("0 0 */3 * * *")},
+		{"every other hour on sundays", spec("0 0 */2 * * sun")},
+		{"every 5 hours from 8am to 6pm", spec("0 0 8-18/5 * * *")},
+		{"every week", spec("0 0 0 * * sun")},
+		{"weekly on tuesday at 4pm", spec("0 0 16 * * tue")},
+		{"every month", spec("0 0 0 1 * *")},
+		{"monthly on the 15th", spec("0 0 0 15 * *")},
+		{"every year", spec("0 0 0 1 1 *")},
+		{"yearly in july", spec("0 0 0 1 7 *")},
+		{"every day at 9:00 in january and july", spec("0 0 9 * 1,7 *")},
+		{"every weekday at 9:30 in Asia/Tokyo", spec("TZ=Asia/Tokyo 0 30 9 * * 1-5")},
+		{"TZ=Asia/Tokyo every weekday at 9:30", spec("TZ=Asia/Tokyo 0 30 9 * * 1-5")},
+		{"every 7 minutes", ConstantDelaySchedule{7 * time.Minute}},
+		{"every 45 seconds", ConstantDelaySchedule{45 * time.Second}},
+	}
+
+	for _, c := range entries {
+		actual, err := NaturalParser{}.Parse(c.expr)
+		if err != nil {
+			t.Errorf("%s => unexpected error %v", c.expr, err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("%s => expected %+v, got %+v", c.expr, c.expected, actual)
+		}
+	}
+}
+
+func TestNaturalParseErrors(t *testing.T) {
+	var tests = []struct{ expr, err string }{
+		{"", "empty spec string"},
+		{"sometimes", `expected "every", "on", "at", "between" or "in", found "sometimes" (word 1)`},
+		{"every blue moon", `expected a day of the week, found "blue" (word 2)`},
+		{"every 5", "expected a unit such as \"minutes\" or \"hours\" at end of phrase"},
+		{"every 5 parsecs", `found "parsecs" (word 3)`},
+		{"every 0 minutes", "frequency must be positive"},
+		{"every hour daily", "frequency given more than once"},
+		{"every weekday at 25:00", "invalid time \"25:00\""},
+		{"every weekday at 13pm", "hour 13 out of range (1-12) for pm"},
+		{"every weekday at nine", `expected a time such as "9:30" or "5pm"`},
+		{"at 9:00 and 10:30", "times must share their minutes or their hours"},
+		{"every 2 hours between 8:30 and 18:00", "must start and end on the hour"},
+		{"every 2 hours between 6pm and 8am", "must not cross midnight"},
+		{"every 15 minutes between 9am and 9am", "must not be empty"},
+		{"every 2 hours at 9:00", "at ... cannot be combined with every hour"},
+		{"every 7 minutes on weekdays", "does not divide an hour evenly"},
+		{"every 90 minutes", "is out of range"},
+		{"every 2 days", "every 2 days cannot be expressed"},
+		{"every day between 8am and 6pm", "only applies to hourly or more frequent"},
+		{"on the 15th on mondays", "cannot restrict both"},
+		{"on the sixth monday of the month", "expected an ordinal"},
+		{"on the 6th monday of the month", "at most 5 of each day of the week"},
+		{"on the second weekday of the month", "only the first or last weekday"},
+		{"on the 1st of the year", `expected "month", found "year"`},
+		{"every day in Mars/Olympus", "expected a month or a time zone"},
+		{"in", "expected a month or a time zone at end of phrase"},
+		{"on", "expected a day of the week at end of phrase"},
+	}
+	for _, c := range tests {
+		actual, err := NaturalParser{}.Parse(c.expr)
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%s => expected %v, got %v", c.expr, c.err, err)
+		}
+		if actual != nil {
+			t.Errorf("expected nil schedule on error, got %v", actual)
+		}
+	}
+}
+
+func TestNaturalParseErrorLocation(t *testing.T) {
+	tests := []struct {
+		spec           string
+		field          SpecField
+		offset, length int
+		reason         ParseErrorReason
+	}{
+		{"", FieldSpec, 0, 0, ReasonEmpty},
+		{"every blue moon", FieldSpec, 6, 4, ReasonUnexpected},
+		{"TZ=UTC every 0 minutes", FieldSpec, 13, 1, ReasonBelowMinimum},
+		{"every monday,friday at 25:00", FieldSpec, 23, 5, ReasonAboveMaximum},
+		{"every 2 hours between 6pm and 8am", FieldSpec, 30, 3, ReasonReversedRange},
+		{"every 5", FieldSpec, 7, 0, ReasonUnexpected},
+		{"at 9:00 and 10:30", FieldSpec, 0, 17, ReasonSyntax},
+		{"TZ=Mars/Olympus every day", FieldTimezone, 3, 12, ReasonLocation},
+	}
+	for _, c := range tests {
+		_, err := NaturalParser{}.Parse(c.spec)
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("%q: expected a ParseError, got %v", c.spec, err)
+			continue
+		}
+		if perr.Spec != c.spec || perr.Field != c.field || perr.Offset != c.offset || perr.Length != c.length || perr.Reason != c.reason {
+			t.Errorf("%q: expected %s at %d+%d for %s, got %s at %d+%d for %s: %v", c.spec,
+				c.field, c.offset, c.length, c.reason, perr.Field, perr.Offset, perr.Length, perr.Reason, perr)
+		}
+	}
+}
+
+func TestNaturalParseFallback(t *testing.T) {
+	parser := NaturalParser{Fallback: standardParser}
+	hourly, _ := standardParser.Parse("@hourly")
+	entries := []struct {
+		expr     string
+		expected Schedule
+	}{
+		{"every hour", hourly},
+		{"5 * * * *", every5min(time.Local)},
+		{"@every 5m", ConstantDelaySchedule{5 * time.Minute}},
+		{"TZ=UTC 5 * * * *", every5min(time.UTC)},
+	}
+
+	for _, c := range entries {
+		actual, err := parser.Parse(c.expr)
+		if err != nil {
+			t.Errorf("%s => unexpected error %v", c.expr, err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("%s => expected %+v, got %+v", c.expr, c.expected, actual)
+		}
+	}
+
+	if _, err := (NaturalParser{}).Parse("5 * * * *"); err == nil {
+		t.Error("expected an error without a fallback parser")
+	}
+}
+
+func TestNaturalParserWithCron(t *testing.T) {
+	cron := New(WithParser(NaturalParser{Fallback: standardParser}))
+	if _, err := cron.AddFunc("natural", "every weekday at 9:30", func(context.Context) error { return nil }); err != nil {
+		t.Error(err)
+	}
+	if _, err := cron.AddFunc("hashed", "H 9 * * *", func(context.Context) error { return nil }); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,62 @@
+package cron
+
+import "time"
+
+// OnceSchedule activates once, at the given time, and never again.  It backs
+// the "@at" descriptor.  Once it has run, Cron retires its entry; see
+// WithRemoveCompleted.
+type OnceSchedule struct {
+	At time.Time
+
+	// Location in which the wall clock of At is read, if not nil, as for
+	// AnchoredSchedule.  If nil, At is an instant.
+	Location *time.Location
+}
+
+// At returns a schedule that activates once, at the given time.  Any fields
+// less than a second are truncated.
+func At(t time.Time) OnceSchedule {
+	return OnceSchedule{At: t.Truncate(time.Second)}
+}
+
+// Next returns the time of the schedule if the given time is before it, and
+// otherwise the zero time.
+func (schedule OnceSchedule) Next(t time.Time) time.Time {
+	if at := wallTime(schedule.At, schedule.Location, t); t.Before(at) {
+		return at.In(t.Location())
+	}
+	return time.Time{}
+}
+
+// Prev returns the time of the schedule if the given time is after it, and
+// otherwise the zero time.
+func (schedule OnceSchedule) Prev(t time.Time) time.Time {
+	if at := wallTime(schedule.At, schedule.Location, t); t.After(at) {
+		return at.In(t.Location())
+	}
+	return time.Time{}
+}
+
+// String returns the "@at" descriptor for the schedule, with the time in RFC
+// 3339 format, or as a wall clock time if it has a location.
+func (schedule OnceSchedule) String() string {
+	return locationPrefix(schedule.Location) + "@at " + wallString(schedule.At, schedule.Location)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning the descriptor.
+func (schedule OnceSchedule) MarshalText() ([]byte, error) {
+	return []byte(schedule.String()), nil
+}
+
+// parseAt returns the schedule for the "@at" descriptor given the time
+// following it, which is interpreted in the given location unless it has an
+// offset of its own.
+func parseAt(value, descriptor string, loc *time.Location) (Schedule, error) {
+	if value == "" {
+		return nil, parseErrorf(ReasonAnchor, "", "missing time: %s", descriptor)
+	}
+	if t, loc, ok := parseAnchor(value, loc); ok {
+		return OnceSchedule{At: t.Truncate(time.Second), Location: loc}, nil
+	}
+	return nil, parseErrorf(ReasonAnchor, value, "failed to parse time %q: %s", value, descriptor)
+}
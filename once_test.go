@@ -0,0 +1,78 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOnceSchedule(t *testing.T) {
+	at := At(getTime("Mon Jul 9 14:45:30 2012").Add(500 * time.Millisecond))
+	tests := []struct {
+		time       string
+		next, prev string
+	}{
+		{"Mon Jul 9 14:45 2012", "Mon Jul 9 14:45:30 2012", ""},
+		{"Mon Jul 9 14:45:30 2012", "", ""},
+		{"Tue Jul 10 00:00 2012", "", "Mon Jul 9 14:45:30 2012"},
+	}
+	for _, c := range tests {
+		if actual := at.Next(getTime(c.time)); c.next == "" && !actual.IsZero() || c.next != "" && !actual.Equal(getTime(c.next)) {
+			t.Errorf("%s: expected next %q, got %v", c.time, c.next, actual)
+		}
+		if actual := at.Prev(getTime(c.time)); c.prev == "" && !actual.IsZero() || c.prev != "" && !actual.Equal(getTime(c.prev)) {
+			t.Errorf("%s: expected prev %q, got %v", c.time, c.prev, actual)
+		}
+	}
+}
+
+func TestParseAt(t *testing.T) {
+	shanghai, _ := time.LoadLocation("Asia/Shanghai")
+	tests := []struct {
+		spec     string
+		expected time.Time
+	}{
+		{"@at 2026-11-01T08:00:00+08:00", time.Date(2026, time.November, 1, 0, 0, 0, 0, time.UTC)},
+		{"TZ=Asia/Shanghai @at 2026-11-01 08:00", time.Date(2026, time.November, 1, 8, 0, 0, 0, shanghai)},
+		{"TZ=Asia/Shanghai @at 2026-11-01", time.Date(2026, time.November, 1, 0, 0, 0, 0, shanghai)},
+	}
+	for _, c := range tests {
+		sched, err := ParseStandard(c.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		once := sched.(OnceSchedule)
+		if actual := wallTime(once.At, once.Location, c.expected); !actual.Equal(c.expected) {
+			t.Errorf("%s: expected %v, got %v", c.spec, c.expected, actual)
+		}
+	}
+
+	for _, spec := range []string{"@at", "@at tomorrow", "@at 2026-13-01"} {
+		_, err := ParseStandard(spec)
+		if perr, ok := err.(*ParseError); !ok || perr.Reason != ReasonAnchor {
+			t.Errorf("%s: expected an anchor error, got %v", spec, err)
+		}
+	}
+}
+
+func TestOnceString(t *testing.T) {
+	sched, _ := ParseStandard("@at 2026-11-01T08:00:00+08:00")
+	if actual := sched.(OnceSchedule).String(); actual != "@at 2026-11-01T08:00:00+08:00" {
+		t.Errorf("expected the descriptor, got %q", actual)
+	}
+	at := At(time.Date(2026, time.November, 1, 8, 0, 0, 0, time.UTC))
+	if actual := Describe(at); actual != "Once, at 2026-11-01 08:00:00 UTC" {
+		t.Errorf("expected an English description, got %q", actual)
+	}
+	if actual := Chinese.Describe(at); actual != "于2026-11-01 08:00:00 UTC运行一次" {
+		t.Errorf("expected a Chinese description, got %q", actual)
+	}
+
+	// Times without an offset are given in the location of the schedule.
+	for _, spec := range []string{"@at 2026-11-01T08:00:00", "TZ=Asia/Shanghai @at 2026-11-01T08:00:00"} {
+		sched, _ := ParseStandard(spec)
+		if actual := sched.(OnceSchedule).String(); actual != spec {
+			t.Errorf("expected %q, got %q", spec, actual)
+		}
+	}
+}
@@ -43,3 +43,57 @@ func WithLogger(logger Logger) Option {
 		c.logger = logger
 	}
 }
+
+// WithRemoveCompleted removes entries once they have run for the last time,
+// such as those of one-shot schedules ("@at" and "@reboot"), instead of
+// keeping them marked as Completed.  Entries stopped by NotAfter or MaxRuns
+// are kept.
+func WithRemoveCompleted() Option {
+	return func(c *Cron) {
+		c.removeCompleted = true
+	}
+}
+
+// WithDSTPolicy sets how the schedules parsed by the cron instance treat wall
+// clock times skipped or repeated by daylight saving time changes.  It applies
+// to the SpecSchedules that specs parse to, including those combined with
+// other schedules; schedules given to Schedule keep their own policy.
+func WithDSTPolicy(policy DSTPolicy) Option {
+	return func(c *Cron) {
+		c.dst = policy
+	}
+}
+
+// WithCoordinates sets the coordinates, in degrees north and east, of the
+// solar schedules ("@sunrise", "@sunset", "@dawn" and "@dusk") parsed by the
+// cron instance which do not give their own.
+func WithCoordinates(latitude, longitude float64) Option {
+	return func(c *Cron) {
+		c.coordinates = &Coordinates{Latitude: latitude, Longitude: longitude}
+	}
+}
+
+// EntryOption represents a modification to an entry added to a Cron.
+type EntryOption func(*Entry)
+
+// NotBefore keeps the entry from running before the given time.
+func NotBefore(t time.Time) EntryOption {
+	return func(e *Entry) {
+		e.NotBefore = t
+	}
+}
+
+// NotAfter keeps the entry from running after the given time.
+func NotAfter(t time.Time) EntryOption {
+	return func(e *Entry) {
+		e.NotAfter = t
+	}
+}
+
+// MaxRuns stops the entry once it has run n times, whether or not the runs
+// succeeded.
+func MaxRuns(n int) EntryOption {
+	return func(e *Entry) {
+		e.MaxRuns = n
+	}
+}
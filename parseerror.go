@@ -0,0 +1,150 @@
+package cron
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// SpecField names the part of a spec in which a ParseError occurred.
+type SpecField string
+
+const (
+	FieldSpec        SpecField = "spec" // the spec as a whole, e.g. the number of fields
+	FieldTimezone    SpecField = "timezone"
+	FieldDescriptor  SpecField = "descriptor"
+	FieldRecurrence  SpecField = "recurrence" // iCalendar recurrence properties
+	FieldMillisecond SpecField = "millisecond"
+	FieldSecond      SpecField = "second"
+	FieldMinute      SpecField = "minute"
+	FieldHour        SpecField = "hour"
+	FieldDom         SpecField = "dom"
+	FieldMonth       SpecField = "month"
+	FieldDow         SpecField = "dow"
+	FieldYear        SpecField = "year"
+	FieldJitter      SpecField = "jitter" // the "~" delay suffix
+)
+
+// placeFields are the fields of a spec, in the order of places.
+var placeFields = []SpecField{
+	FieldSecond,
+	FieldMinute,
+	FieldHour,
+	FieldDom,
+	FieldMonth,
+	FieldDow,
+	FieldYear,
+}
+
+// ParseErrorReason is a machine-readable code for the cause of a ParseError.
+type ParseErrorReason string
+
+const (
+	ReasonEmpty             ParseErrorReason = "empty"              // the spec is empty
+	ReasonFieldCount        ParseErrorReason = "field_count"        // too few or too many fields
+	ReasonOptions           ParseErrorReason = "options"            // the parser is misconfigured
+	ReasonSyntax            ParseErrorReason = "syntax"             // e.g. too many hyphens
+	ReasonNumber            ParseErrorReason = "number"             // not a number, or negative
+	ReasonBelowMinimum      ParseErrorReason = "below_minimum"      // a value below Min
+	ReasonAboveMaximum      ParseErrorReason = "above_maximum"      // a value above Max
+	ReasonReversedRange     ParseErrorReason = "reversed_range"     // a range whose end is before its start
+	ReasonStep              ParseErrorReason = "step"               // a step of zero
+	ReasonLocation          ParseErrorReason = "location"           // an unknown time zone
+	ReasonDescriptor        ParseErrorReason = "descriptor"         // an unknown or disallowed descriptor
+	ReasonDuration          ParseErrorReason = "duration"           // a bad duration in a descriptor
+	ReasonUnexpected        ParseErrorReason = "unexpected"         // an unexpected word in a descriptor
+	ReasonAnchor            ParseErrorReason = "anchor"             // a bad anchor time for "@every ... from" or "@at"
+	ReasonIntervalAlignment ParseErrorReason = "interval_alignment" // an aligned interval that does not divide a day
+	ReasonCoordinates       ParseErrorReason = "coordinates"        // bad or missing coordinates for a solar descriptor
+)
+
+// ParseError is the error returned by Parser for specs it cannot parse.  It
+// locates the offending token within the spec, e.g. for highlighting it:
+//
+//	var perr *cron.ParseError
+//	if errors.As(err, &perr) {
+//		fmt.Println(perr.Spec)
+//		fmt.Println(strings.Repeat(" ", perr.Offset) + strings.Repeat("^", perr.Length))
+//	}
+type ParseError struct {
+	// Spec is the spec that failed to parse.
+	Spec string
+
+	// Field is the part of the spec that failed to parse.
+	Field SpecField
+
+	// Offset and Length give the position of the offending token in Spec, in
+	// bytes.  If the token could not be located, they span the whole field.
+	Offset, Length int
+
+	// Min and Max are the values allowed in the field, if it is one of the
+	// time fields.
+	Min, Max int
+
+	// Reason is a machine-readable code for the cause of the error.
+	Reason ParseErrorReason
+
+	// Message describes the error, as returned by Error.
+	Message string
+
+	// token is the offending text, located within the field by locate.
+	token string
+}
+
+// Error returns the message describing the error.
+func (e *ParseError) Error() string {
+	return e.Message
+}
+
+// parseErrorf returns a ParseError for the given token, to be located in the
+// spec by the caller.
+func parseErrorf(reason ParseErrorReason, token, format string, args ...interface{}) *ParseError {
+	return &ParseError{
+		Reason:  reason,
+		Message: fmt.Sprintf(format, args...),
+		token:   token,
+	}
+}
+
+// locate sets the spec and field of the error, and finds its token within
+// the part of the spec between start and end.  Errors which are not
+// ParseErrors are returned unchanged.
+func locate(err error, spec string, field SpecField, start, end int) error {
+	perr, ok := err.(*ParseError)
+	if !ok {
+		return err
+	}
+	perr.Spec, perr.Field = spec, field
+	perr.Offset, perr.Length = start, end-start
+	if i := strings.Index(spec[start:end], perr.token); perr.token != "" && i >= 0 {
+		perr.Offset, perr.Length = start+i, len(perr.token)
+	}
+	return perr
+}
+
+// span is the byte range of a field within a spec.
+type span struct {
+	start, end int
+}
+
+// fieldSpans returns the positions of the whitespace-separated fields of the
+// spec, as split by strings.Fields.
+func fieldSpans(spec string) []span {
+	var (
+		spans []span
+		start = -1
+	)
+	for i, r := range spec {
+		switch {
+		case unicode.IsSpace(r) && start >= 0:
+			spans = append(spans, span{start, i})
+			start = -1
+		case !unicode.IsSpace(r) && start < 0:
+			start = i
+		}
+	}
+	if start >= 0 {
+		spans = append(spans, span{start, len(spec)})
+	}
+	return spans
+}
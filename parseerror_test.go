@@ -0,0 +1,75 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestParseErrorLocation(t *testing.T) {
+	yearParser := NewParser(Second | Minute | Hour | Dom | Month | Dow | YearOptional | Descriptor)
+	tests := []struct {
+		parser         Parser
+		spec           string
+		field          SpecField
+		offset, length int
+		min, max       int
+		reason         ParseErrorReason
+	}{
+		{standardParser, "", FieldSpec, 0, 0, 0, 0, ReasonEmpty},
+		{standardParser, "* * * *", FieldSpec, 0, 7, 0, 0, ReasonFieldCount},
+		{standardParser, "5 60 * * *", FieldHour, 2, 2, 0, 23, ReasonAboveMaximum},
+		{standardParser, "0-60 * * * *", FieldMinute, 0, 4, 0, 59, ReasonAboveMaximum},
+		{standardParser, "0 0 1,0 * *", FieldDom, 6, 1, 1, 31, ReasonBelowMinimum},
+		{standardParser, "0 0 * 6-2 *", FieldMonth, 6, 3, 1, 12, ReasonReversedRange},
+		{standardParser, "*/0 * * * *", FieldMinute, 0, 3, 0, 59, ReasonStep},
+		{standardParser, "0 0 * * mon-fri-sat", FieldDow, 8, 11, 0, 6, ReasonSyntax},
+		{standardParser, "0 0 * * 1,x-5", FieldDow, 10, 1, 0, 6, ReasonNumber},
+		{standardParser, "0 0 * * mon#6", FieldDow, 8, 5, 0, 6, ReasonAboveMaximum},
+		{standardParser, "0 0 L-40 * *", FieldDom, 4, 4, 1, 31, ReasonAboveMaximum},
+		{standardParser, "H(0-70) * * * *", FieldMinute, 0, 7, 0, 59, ReasonAboveMaximum},
+		{standardParser, "  TZ=Mars/Olympus 0 0 * * *", FieldSpec, 0, 27, 0, 0, ReasonFieldCount},
+		{standardParser, "TZ=Mars/Olympus 0 0 * * *", FieldTimezone, 3, 12, 0, 0, ReasonLocation},
+		{standardParser, "TZ=UTC  0 0 32 * *", FieldDom, 12, 2, 1, 31, ReasonAboveMaximum},
+		{standardParser, "@fortnightly", FieldDescriptor, 0, 12, 0, 0, ReasonDescriptor},
+		{standardParser, "TZ=UTC @every 5x", FieldDescriptor, 14, 2, 0, 0, ReasonDuration},
+		{standardParser, "@every 7h aligned", FieldDescriptor, 7, 2, 0, 0, ReasonIntervalAlignment},
+		{standardParser, "@every 1h from yesterday", FieldDescriptor, 15, 9, 0, 0, ReasonAnchor},
+		{standardParser, "@reboot 30s", FieldDescriptor, 8, 3, 0, 0, ReasonSyntax},
+		{standardParser, "@sunset -30m 91,0", FieldDescriptor, 13, 2, 0, 0, ReasonCoordinates},
+		{NewParser(Minute | Hour | Dom | Month | Dow), "@daily", FieldDescriptor, 0, 6, 0, 0, ReasonDescriptor},
+		{secondParser, "0 0 0 * * * 1", FieldSpec, 0, 13, 0, 0, ReasonFieldCount},
+		{yearParser, "0 0 0 * * * 1969", FieldYear, 12, 4, 1970, 2099, ReasonBelowMinimum},
+		{NewParser(SecondOptional | Minute | Hour | Dom | Month | Dow), "0 0 * * 7", FieldDow, 8, 1, 0, 6, ReasonAboveMaximum},
+		{NewParser(SecondOptional | Minute | Hour | Dom | Month | Dow), "0 0 0 * * 7", FieldDow, 10, 1, 0, 6, ReasonAboveMaximum},
+	}
+
+	for _, c := range tests {
+		_, err := c.parser.Parse(c.spec)
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("%q: expected a ParseError, got %v", c.spec, err)
+			continue
+		}
+		if perr.Spec != c.spec || perr.Field != c.field || perr.Offset != c.offset || perr.Length != c.length ||
+			perr.Min != c.min || perr.Max != c.max || perr.Reason != c.reason {
+			t.Errorf("%q: expected %s at %d+%d (%d-%d) for %s, got %s at %d+%d (%d-%d) for %s: %v", c.spec,
+				c.field, c.offset, c.length, c.min, c.max, c.reason,
+				perr.Field, perr.Offset, perr.Length, perr.Min, perr.Max, perr.Reason, perr)
+		}
+		if perr.Error() != err.Error() || perr.Message == "" {
+			t.Errorf("%q: expected the message %q, got %q", c.spec, err, perr.Message)
+		}
+	}
+}
+
+func TestParseErrorFromCron(t *testing.T) {
+	_, err := New().AddFunc("bad", "* * * * 8", func(context.Context) error { return nil })
+	var perr *ParseError
+	if !errors.As(err, &perr) {
+		t.Fatalf("expected a ParseError, got %v", err)
+	}
+	if perr.Field != FieldDow || perr.Offset != 8 {
+		t.Errorf("expected the day of week at offset 8, got %s at %d", perr.Field, perr.Offset)
+	}
+}
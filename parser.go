@@ -2,6 +2,7 @@ package cron
 
 import (
 	"fmt"
+	"hash/fnv"
 	"math"
 	"strconv"
 	"strings"
@@ -24,6 +25,9 @@ const (
 	Dow                                    // Day of week field, default *
 	DowOptional                            // Optional day of week field, default *
 	Descriptor                             // Allow descriptors such as @monthly, @weekly, etc.
+	Year                                   // Year field, default *
+	YearOptional                           // Optional year field, default *
+	Millisecond                            // Milliseconds field before all others, default 0
 )
 
 var places = []ParseOption{
@@ -33,6 +37,7 @@ var places = []ParseOption{
 	Dom,
 	Month,
 	Dow,
+	Year,
 }
 
 var defaults = []string{
@@ -42,6 +47,7 @@ var defaults = []string{
 	"*",
 	"*",
 	"*",
+	"*",
 }
 
 // A custom Parser that can be configured.
@@ -76,6 +82,9 @@ func NewParser(options ParseOption) Parser {
 	if options&SecondOptional > 0 {
 		optionals++
 	}
+	if options&YearOptional > 0 {
+		optionals++
+	}
 	if optionals > 1 {
 		panic("multiple optionals may not be configured")
 	}
@@ -85,73 +94,220 @@ func NewParser(options ParseOption) Parser {
 // Parse returns a new crontab schedule representing the given spec.
 // It returns a descriptive error if the spec is not valid.
 // It accepts crontab specs and features configured by NewParser.
+//
+// Hash ("H") tokens are resolved as if by ParseWithSeed with an empty seed.
 func (p Parser) Parse(spec string) (Schedule, error) {
+	return p.ParseWithSeed(spec, "")
+}
+
+// ParseWithSeed is like Parse, but resolves hash ("H") tokens to values
+// derived from the given seed, typically the title of the entry.  The same
+// spec and seed always produce the same schedule.
+func (p Parser) ParseWithSeed(spec, seed string) (Schedule, error) {
 	if len(spec) == 0 {
-		return nil, fmt.Errorf("empty spec string")
+		return nil, &ParseError{Field: FieldSpec, Reason: ReasonEmpty, Message: "empty spec string"}
+	}
+
+	// Handle jittered schedules, e.g. "@hourly ~5m"
+	if _, _, ok := splitJitter(spec); ok {
+		return parseJitter(spec, seed, func(spec string) (Schedule, error) {
+			return p.ParseWithSeed(spec, seed)
+		})
 	}
 
+	// Handle combined schedules, e.g. "0 9 * * 1-5 | 0 12 * * 6"
+	if isComposite(spec) {
+		return p.parseComposite(spec, seed)
+	}
+
+	var (
+		original = spec
+		spans    = fieldSpans(spec)
+	)
+
 	// Extract timezone if present
-	var loc = time.Local
-	if strings.HasPrefix(spec, "TZ=") || strings.HasPrefix(spec, "CRON_TZ=") {
-		var err error
-		i := strings.Index(spec, " ")
-		eq := strings.Index(spec, "=")
-		if loc, err = time.LoadLocation(spec[eq+1 : i]); err != nil {
-			return nil, fmt.Errorf("provided bad location %s: %v", spec[eq+1:i], err)
+	loc, spec, err := parseTimezone(spec)
+	if err != nil {
+		return nil, locate(err, original, FieldTimezone, spans[0].start, spans[0].end)
+	}
+	if spec != original {
+		spans = spans[1:]
+	}
+
+	// Handle iCalendar recurrences, e.g. "RRULE:FREQ=DAILY;BYHOUR=9"
+	if isRecurrence(spec) {
+		schedule, err := parseRecurrence(spec, loc)
+		if err != nil {
+			return nil, locate(err, original, FieldRecurrence, spans[0].start, spans[len(spans)-1].end)
 		}
-		spec = strings.TrimSpace(spec[i:])
+		return schedule, nil
 	}
 
 	// Handle named schedules (descriptors), if configured
 	if strings.HasPrefix(spec, "@") {
+		start, end := spans[0].start, spans[len(spans)-1].end
 		if p.options&Descriptor == 0 {
-			return nil, fmt.Errorf("parser does not accept descriptors: %v", spec)
+			err = parseErrorf(ReasonDescriptor, "", "parser does not accept descriptors: %v", spec)
+			return nil, locate(err, original, FieldDescriptor, start, end)
+		}
+		schedule, err := parseDescriptor(spec, loc)
+		if err != nil {
+			return nil, locate(err, original, FieldDescriptor, start, end)
 		}
-		return parseDescriptor(spec, loc)
+		return schedule, nil
 	}
 
 	// Split on whitespace.
 	fields := strings.Fields(spec)
 
+	// The milliseconds field precedes the others, and is parsed apart from
+	// them since its values do not fit in a bit set.
+	var millis []int
+	if p.options&Millisecond > 0 && len(fields) > 0 {
+		field, err := expandHash(fields[0], milliseconds, milliseconds, hashSeed(seed, len(places)))
+		if err == nil {
+			millis, err = getMilliseconds(field)
+		}
+		if err != nil {
+			err = locate(err, original, FieldMillisecond, spans[0].start, spans[0].end)
+			if perr, ok := err.(*ParseError); ok {
+				perr.Min, perr.Max = int(milliseconds.min), int(milliseconds.max)
+			}
+			return nil, err
+		}
+		fields, spans = fields[1:], spans[1:]
+	}
+	sources := fieldSources(len(fields), p.options)
+
 	// Validate & fill in any omitted or optional fields
-	var err error
 	fields, err = normalizeFields(fields, p.options)
 	if err != nil {
-		return nil, err
+		return nil, locate(err, original, FieldSpec, 0, len(original))
 	}
 
-	field := func(field string, r bounds) uint64 {
+	// fieldError locates an error in the field at index i.
+	fieldError := func(i int, err error) error {
+		if sources[i] < 0 {
+			return locate(err, original, placeFields[i], 0, len(original))
+		}
+		err = locate(err, original, placeFields[i], spans[sources[i]].start, spans[sources[i]].end)
+		if perr, ok := err.(*ParseError); ok {
+			perr.Min, perr.Max = int(fieldBounds[i].min), int(fieldBounds[i].max)
+		}
+		return err
+	}
+	for i := range fields {
+		if fields[i], err = expandHash(fields[i], fieldBounds[i], hashBounds[i], hashSeed(seed, i)); err != nil {
+			return nil, fieldError(i, err)
+		}
+	}
+
+	field := func(i int, r bounds) uint64 {
 		if err != nil {
 			return 0
 		}
 		var bits uint64
-		bits, err = getField(field, r)
+		if bits, err = getField(fields[i], r); err != nil {
+			err = fieldError(i, err)
+		}
 		return bits
 	}
+	dayField := func(i int, r bounds, parseRule dayRuleParser) (uint64, []DayRule) {
+		if err != nil {
+			return 0, nil
+		}
+		var (
+			bits  uint64
+			rules []DayRule
+		)
+		if bits, rules, err = getDayField(fields[i], r, parseRule); err != nil {
+			err = fieldError(i, err)
+		}
+		return bits, rules
+	}
 
 	var (
-		second     = field(fields[0], seconds)
-		minute     = field(fields[1], minutes)
-		hour       = field(fields[2], hours)
-		dayofmonth = field(fields[3], dom)
-		month      = field(fields[4], months)
-		dayofweek  = field(fields[5], dow)
+		second               = field(0, seconds)
+		minute               = field(1, minutes)
+		hour                 = field(2, hours)
+		dayofmonth, domRules = dayField(3, dom, parseDomRule)
+		month                = field(4, months)
+		dayofweek, dowRules  = dayField(5, dow, parseDowRule)
 	)
+	var year []int
+	if len(fields) > len(places)-1 && err == nil {
+		if year, err = getYears(fields[6]); err != nil {
+			err = fieldError(6, err)
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	return &SpecSchedule{
-		Second:   second,
-		Minute:   minute,
-		Hour:     hour,
-		Dom:      dayofmonth,
-		Month:    month,
-		Dow:      dayofweek,
-		Location: loc,
+		Second:      second,
+		Minute:      minute,
+		Hour:        hour,
+		Dom:         dayofmonth,
+		Month:       month,
+		Dow:         dayofweek,
+		DomRules:    domRules,
+		DowRules:    dowRules,
+		Year:        year,
+		Millisecond: millis,
+		Location:    loc,
 	}, nil
 }
 
+// parseComposite parses a combined schedule.  A leading time zone applies to
+// each operand without one of its own, e.g. both in
+// "TZ=Asia/Tokyo 0 9 * * 1-5 | 0 12 * * 6".
+func (p Parser) parseComposite(spec, seed string) (Schedule, error) {
+	var zone string
+	if _, rest, err := parseTimezone(spec); err != nil {
+		spans := fieldSpans(spec)
+		return nil, locate(err, spec, FieldTimezone, spans[0].start, spans[0].end)
+	} else if rest != spec {
+		zone = spec[:strings.IndexAny(spec, " \t\n")]
+	}
+	start := len(spec) - len(strings.TrimLeft(spec[len(zone):], " \t\n"))
+	schedule, err := parseComposite(spec[start:], func(operand string) (Schedule, error) {
+		if zone == "" || strings.HasPrefix(operand, "TZ=") || strings.HasPrefix(operand, "CRON_TZ=") {
+			return p.ParseWithSeed(operand, seed)
+		}
+		schedule, err := p.ParseWithSeed(zone+" "+operand, seed)
+		if perr, ok := err.(*ParseError); ok {
+			perr.Offset -= len(zone) + 1
+		}
+		return schedule, err
+	})
+	if perr, ok := err.(*ParseError); ok {
+		// Locate the error within the spec with its time zone.
+		perr.Spec = spec
+		perr.Offset += start
+	}
+	return schedule, err
+}
+
+// parseTimezone extracts the "TZ=" or "CRON_TZ=" prefix from the given spec.
+// It returns the location, or time.Local if there is none, and the rest of
+// the spec.
+func parseTimezone(spec string) (*time.Location, string, error) {
+	if !strings.HasPrefix(spec, "TZ=") && !strings.HasPrefix(spec, "CRON_TZ=") {
+		return time.Local, spec, nil
+	}
+	i := strings.IndexAny(spec, " \t\n")
+	if i < 0 {
+		i = len(spec)
+	}
+	eq := strings.Index(spec, "=")
+	loc, err := time.LoadLocation(spec[eq+1 : i])
+	if err != nil {
+		return nil, "", parseErrorf(ReasonLocation, spec[eq+1:i], "provided bad location %s: %v", spec[eq+1:i], err)
+	}
+	return loc, strings.TrimSpace(spec[i:]), nil
+}
+
 // normalizeFields takes a subset set of the time fields and returns the full set
 // with defaults (zeroes) populated for unset fields.
 //
@@ -168,8 +324,12 @@ func normalizeFields(fields []string, options ParseOption) ([]string, error) {
 		options |= Dow
 		optionals++
 	}
+	if options&YearOptional > 0 {
+		options |= Year
+		optionals++
+	}
 	if optionals > 1 {
-		return nil, fmt.Errorf("multiple optionals may not be configured")
+		return nil, parseErrorf(ReasonOptions, "", "multiple optionals may not be configured")
 	}
 
 	// Figure out how many fields we need
@@ -181,12 +341,17 @@ func normalizeFields(fields []string, options ParseOption) ([]string, error) {
 	}
 	min := max - optionals
 
-	// Validate number of fields
+	// Validate number of fields, counting the milliseconds field which the
+	// caller has taken off.
 	if count := len(fields); count < min || count > max {
+		extra := 0
+		if options&Millisecond > 0 {
+			extra = 1
+		}
 		if min == max {
-			return nil, fmt.Errorf("expected exactly %d fields, found %d: %s", min, count, fields)
+			return nil, parseErrorf(ReasonFieldCount, "", "expected exactly %d fields, found %d: %s", min+extra, count+extra, fields)
 		}
-		return nil, fmt.Errorf("expected %d to %d fields, found %d: %s", min, max, count, fields)
+		return nil, parseErrorf(ReasonFieldCount, "", "expected %d to %d fields, found %d: %s", min+extra, max+extra, count+extra, fields)
 	}
 
 	// Populate the optional field if not provided
@@ -194,18 +359,25 @@ func normalizeFields(fields []string, options ParseOption) ([]string, error) {
 		switch {
 		case options&DowOptional > 0:
 			fields = append(fields, defaults[5]) // TODO: improve access to default
+		case options&YearOptional > 0:
+			fields = append(fields, defaults[6])
 		case options&SecondOptional > 0:
 			fields = append([]string{defaults[0]}, fields...)
 		default:
-			return nil, fmt.Errorf("unknown optional field")
+			return nil, parseErrorf(ReasonOptions, "", "unknown optional field")
 		}
 	}
 
-	// Populate all fields not part of options with their defaults
+	// Populate all fields not part of options with their defaults.
+	// The year is only included if it was configured.
+	count := len(places)
+	if options&Year == 0 {
+		count--
+	}
 	n := 0
-	expandedFields := make([]string, len(places))
+	expandedFields := make([]string, count)
 	copy(expandedFields, defaults)
-	for i, place := range places {
+	for i, place := range places[:count] {
 		if options&place > 0 {
 			expandedFields[i] = fields[n]
 			n++
@@ -214,6 +386,44 @@ func normalizeFields(fields []string, options ParseOption) ([]string, error) {
 	return expandedFields, nil
 }
 
+// fieldSources returns, for each of the fields returned by normalizeFields
+// given n fields, the index of the given field it came from, or -1 if it was
+// filled in with its default.
+func fieldSources(n int, options ParseOption) []int {
+	var optional ParseOption
+	switch {
+	case options&SecondOptional > 0:
+		optional = Second
+	case options&DowOptional > 0:
+		optional = Dow
+	case options&YearOptional > 0:
+		optional = Year
+	}
+	options |= optional
+
+	max := 0
+	for _, place := range places {
+		if options&place > 0 {
+			max++
+		}
+	}
+	count := len(places)
+	if options&Year == 0 {
+		count--
+	}
+	sources := make([]int, count)
+	next := 0
+	for i, place := range places[:count] {
+		sources[i] = -1
+		if options&place == 0 || place == optional && n < max {
+			continue
+		}
+		sources[i] = next
+		next++
+	}
+	return sources
+}
+
 var standardParser = NewParser(
 	Minute | Hour | Dom | Month | Dow | Descriptor,
 )
@@ -230,6 +440,140 @@ func ParseStandard(standardSpec string) (Schedule, error) {
 	return standardParser.Parse(standardSpec)
 }
 
+// Parsers for the specs returned by the String methods of schedules, which
+// include the seconds field if it is not zero, and the year field if any.
+var (
+	textParser = NewParser(
+		SecondOptional | Minute | Hour | Dom | Month | Dow | Descriptor,
+	)
+	textYearParser = NewParser(
+		Second | Minute | Hour | Dom | Month | Dow | Year,
+	)
+	textMillisecondParser = NewParser(
+		Millisecond | Second | Minute | Hour | Dom | Month | Dow | Year,
+	)
+)
+
+// parseText returns the schedule for a spec returned by the String method of
+// one of the schedules in this package.
+func parseText(spec string) (Schedule, error) {
+	if _, _, ok := splitJitter(spec); ok {
+		return parseJitter(spec, "", parseText)
+	}
+	if isComposite(spec) {
+		return parseComposite(spec, parseText)
+	}
+	_, rest, err := parseTimezone(strings.TrimSpace(spec))
+	if err != nil {
+		return nil, err
+	}
+	if isSystemd(rest) {
+		return parseSystemd(spec)
+	}
+	switch len(strings.Fields(rest)) {
+	case 7:
+		return textYearParser.Parse(spec)
+	case 8:
+		return textMillisecondParser.Parse(spec)
+	}
+	return textParser.Parse(spec)
+}
+
+// fieldBounds are the bounds of each field, in the order of places.
+var fieldBounds = []bounds{
+	seconds,
+	minutes,
+	hours,
+	dom,
+	months,
+	dow,
+	years,
+}
+
+// hashBounds are the ranges that a plain "H" resolves within, in the order of
+// places.  The day of month stops at 28 so that the job runs in every month.
+var hashBounds = []bounds{
+	seconds,
+	minutes,
+	hours,
+	{dom.min, 28, nil},
+	months,
+	dow,
+	years,
+}
+
+// hashSeed returns the hash of the given seed for the field at index i, so
+// that each field of a spec resolves "H" differently.
+func hashSeed(seed string, i int) uint {
+	h := fnv.New32a()
+	h.Write([]byte(seed))
+	h.Write([]byte{byte(i)})
+	return uint(h.Sum32())
+}
+
+// expandHash replaces the hash tokens in a field with the values they
+// resolve to for the given hash:
+//   "H" [ "(" number "-" number ")" ] [ "/" number ]
+// A plain "H" picks a value within def, "H(a-b)" picks one within a-b, which
+// must lie within r, and "H/step" picks the start of the range within the
+// first step.
+func expandHash(field string, r, def bounds, hash uint) (string, error) {
+	if !strings.Contains(field, "H") {
+		return field, nil
+	}
+	ranges := strings.Split(field, ",")
+	for i, expr := range ranges {
+		if !strings.HasPrefix(expr, "H") {
+			continue
+		}
+		var (
+			rangeAndStep = strings.SplitN(expr[1:], "/", 2)
+			start, end   = def.min, def.max
+			err          error
+		)
+		if inner := rangeAndStep[0]; inner != "" {
+			if len(inner) < 2 || inner[0] != '(' || inner[len(inner)-1] != ')' {
+				return "", parseErrorf(ReasonSyntax, expr, "malformed hash range: %s", expr)
+			}
+			lowAndHigh := strings.Split(inner[1:len(inner)-1], "-")
+			if len(lowAndHigh) != 2 {
+				return "", parseErrorf(ReasonSyntax, expr, "malformed hash range: %s", expr)
+			}
+			if start, err = mustParseInt(lowAndHigh[0]); err != nil {
+				return "", err
+			}
+			if end, err = mustParseInt(lowAndHigh[1]); err != nil {
+				return "", err
+			}
+			switch {
+			case start < r.min:
+				return "", parseErrorf(ReasonBelowMinimum, expr, "beginning of range (%d) below minimum (%d): %s", start, r.min, expr)
+			case end > r.max:
+				return "", parseErrorf(ReasonAboveMaximum, expr, "end of range (%d) above maximum (%d): %s", end, r.max, expr)
+			case start > end:
+				return "", parseErrorf(ReasonReversedRange, expr, "beginning of range (%d) beyond end of range (%d): %s", start, end, expr)
+			}
+		}
+		if len(rangeAndStep) == 1 {
+			ranges[i] = strconv.Itoa(int(start + hash%(end-start+1)))
+			continue
+		}
+		step, err := mustParseInt(rangeAndStep[1])
+		if err != nil {
+			return "", err
+		}
+		if step == 0 {
+			return "", parseErrorf(ReasonStep, expr, "step of range should be a positive number: %s", expr)
+		}
+		span := step
+		if span > end-start+1 {
+			span = end - start + 1
+		}
+		ranges[i] = fmt.Sprintf("%d-%d/%d", start+hash%span, end, step)
+	}
+	return strings.Join(ranges, ","), nil
+}
+
 // getField returns an Int with the bits set representing all of the times that
 // the field represents or error parsing field value.  A "field" is a comma-separated
 // list of "ranges".
@@ -246,27 +590,225 @@ func getField(field string, r bounds) (uint64, error) {
 	return bits, nil
 }
 
+// dayRuleParser parses a single day expression using one of the Quartz-style
+// modifiers. It returns false if the expression does not use a modifier.
+type dayRuleParser func(expr string) (DayRule, bool, error)
+
+// getDayField is like getField, but also accepts expressions using the
+// Quartz-style L, W and # modifiers understood by parseRule.  Those are
+// returned as rules rather than bits.
+func getDayField(field string, r bounds, parseRule dayRuleParser) (uint64, []DayRule, error) {
+	var (
+		bits  uint64
+		rules []DayRule
+	)
+	ranges := strings.FieldsFunc(field, func(r rune) bool { return r == ',' })
+	for _, expr := range ranges {
+		rule, ok, err := parseRule(expr)
+		if err != nil {
+			return 0, nil, err
+		}
+		if ok {
+			rules = append(rules, rule)
+			continue
+		}
+		bit, err := getRange(expr, r)
+		if err != nil {
+			return 0, nil, err
+		}
+		bits |= bit
+	}
+	return bits, rules, nil
+}
+
+// parseDomRule parses the day-of-month modifiers:
+//   "L" | "L-" number | number "W" | "LW" | [ number ] "WD" | "LWD"
+func parseDomRule(expr string) (DayRule, bool, error) {
+	upper := strings.ToUpper(expr)
+	switch {
+	case upper == "L":
+		return DayRule{Kind: LastDayOfMonth}, true, nil
+	case upper == "LW":
+		return DayRule{Kind: LastWeekdayOfMonth}, true, nil
+	case upper == "WD":
+		return DayRule{Kind: Workday}, true, nil
+	case upper == "LWD":
+		return DayRule{Kind: LastWorkdayOfMonth}, true, nil
+	case strings.HasSuffix(upper, "WD"):
+		n, err := mustParseInt(expr[:len(expr)-2])
+		if err != nil {
+			return DayRule{}, false, err
+		}
+		if n < dom.min || n > dom.max {
+			reason := ReasonAboveMaximum
+			if n < dom.min {
+				reason = ReasonBelowMinimum
+			}
+			return DayRule{}, false, parseErrorf(reason, expr, "number of working day (%d) out of range (%d-%d): %s", n, dom.min, dom.max, expr)
+		}
+		return DayRule{Kind: NthWorkday, N: int(n)}, true, nil
+	case strings.HasPrefix(upper, "L-"):
+		n, err := mustParseInt(expr[2:])
+		if err != nil {
+			return DayRule{}, false, err
+		}
+		if n >= dom.max {
+			return DayRule{}, false, parseErrorf(ReasonAboveMaximum, expr, "offset from last day (%d) above maximum (%d): %s", n, dom.max-1, expr)
+		}
+		return DayRule{Kind: LastDayOfMonth, N: int(n)}, true, nil
+	case strings.HasSuffix(upper, "W"):
+		n, err := mustParseInt(expr[:len(expr)-1])
+		if err != nil {
+			return DayRule{}, false, err
+		}
+		if n < dom.min || n > dom.max {
+			reason := ReasonAboveMaximum
+			if n < dom.min {
+				reason = ReasonBelowMinimum
+			}
+			return DayRule{}, false, parseErrorf(reason, expr, "day of nearest weekday (%d) out of range (%d-%d): %s", n, dom.min, dom.max, expr)
+		}
+		return DayRule{Kind: NearestWeekday, N: int(n)}, true, nil
+	}
+	return DayRule{}, false, nil
+}
+
+// parseDowRule parses the day-of-week modifiers:
+//   weekday "#" number | weekday "L"
+// The weekday may be a number or a name.
+func parseDowRule(expr string) (DayRule, bool, error) {
+	if i := strings.Index(expr, "#"); i >= 0 {
+		wd, err := parseWeekday(expr[:i], expr)
+		if err != nil {
+			return DayRule{}, false, err
+		}
+		n, err := mustParseInt(expr[i+1:])
+		if err != nil {
+			return DayRule{}, false, err
+		}
+		if n < 1 || n > 5 {
+			reason := ReasonAboveMaximum
+			if n < 1 {
+				reason = ReasonBelowMinimum
+			}
+			return DayRule{}, false, parseErrorf(reason, expr, "occurrence of weekday (%d) out of range (1-5): %s", n, expr)
+		}
+		return DayRule{Kind: NthDayOfWeek, N: int(n), Weekday: wd}, true, nil
+	}
+	if len(expr) > 1 && strings.HasSuffix(strings.ToUpper(expr), "L") {
+		wd, err := parseWeekday(expr[:len(expr)-1], expr)
+		if err != nil {
+			return DayRule{}, false, err
+		}
+		return DayRule{Kind: LastDayOfWeek, Weekday: wd}, true, nil
+	}
+	return DayRule{}, false, nil
+}
+
+// parseWeekday returns the (possibly-named) day of the week in value, which
+// is part of the expression expr.
+func parseWeekday(value, expr string) (time.Weekday, error) {
+	wd, err := parseIntOrName(value, dow.names)
+	if err != nil {
+		return 0, err
+	}
+	if wd > dow.max {
+		return 0, parseErrorf(ReasonAboveMaximum, expr, "weekday (%d) above maximum (%d): %s", wd, dow.max, expr)
+	}
+	return time.Weekday(wd), nil
+}
+
 // getRange returns the bits indicated by the given expression:
 //   number | number "-" number [ "/" number ]
 // or error parsing range.
 func getRange(expr string, r bounds) (uint64, error) {
+	start, end, step, star, err := parseRange(expr, r)
+	if err != nil {
+		return 0, err
+	}
+
+	var extra uint64
+	if star && step == 1 {
+		extra = starBit
+	}
+	return getBits(start, end, step) | extra, nil
+}
+
+// getYears returns the sorted list of years represented by the given field,
+// or nil if it matches every year.  The field has the same syntax as the
+// others, but years do not fit in a bit set.
+func getYears(field string) ([]int, error) {
 	var (
-		start, end, step uint
-		rangeAndStep     = strings.Split(expr, "/")
-		lowAndHigh       = strings.Split(rangeAndStep[0], "-")
-		singleDigit      = len(lowAndHigh) == 1
-		err              error
+		matched = make([]bool, years.max-years.min+1)
+		list    []int
+	)
+	ranges := strings.FieldsFunc(field, func(r rune) bool { return r == ',' })
+	for _, expr := range ranges {
+		start, end, step, star, err := parseRange(expr, years)
+		if err != nil {
+			return nil, err
+		}
+		if star && step == 1 {
+			return nil, nil
+		}
+		for y := start; y <= end; y += step {
+			matched[y-years.min] = true
+		}
+	}
+	for i, ok := range matched {
+		if ok {
+			list = append(list, int(years.min)+i)
+		}
+	}
+	return list, nil
+}
+
+// getMilliseconds returns the sorted list of milliseconds represented by the
+// given field, or nil if it only matches the whole second.
+func getMilliseconds(field string) ([]int, error) {
+	var (
+		matched = make([]bool, milliseconds.max-milliseconds.min+1)
+		list    []int
+	)
+	ranges := strings.FieldsFunc(field, func(r rune) bool { return r == ',' })
+	for _, expr := range ranges {
+		start, end, step, _, err := parseRange(expr, milliseconds)
+		if err != nil {
+			return nil, err
+		}
+		for ms := start; ms <= end; ms += step {
+			matched[ms] = true
+		}
+	}
+	for ms, ok := range matched {
+		if ok {
+			list = append(list, ms)
+		}
+	}
+	if len(list) == 1 && list[0] == 0 {
+		return nil, nil
+	}
+	return list, nil
+}
+
+// parseRange returns the start, end and step of the given expression:
+//   number | number "-" number [ "/" number ]
+// and whether it was a star, or error parsing range.
+func parseRange(expr string, r bounds) (start, end, step uint, star bool, err error) {
+	var (
+		rangeAndStep = strings.Split(expr, "/")
+		lowAndHigh   = strings.Split(rangeAndStep[0], "-")
+		singleDigit  = len(lowAndHigh) == 1
 	)
 
-	var extra uint64
 	if lowAndHigh[0] == "*" || lowAndHigh[0] == "?" {
 		start = r.min
 		end = r.max
-		extra = starBit
+		star = true
 	} else {
 		start, err = parseIntOrName(lowAndHigh[0], r.names)
 		if err != nil {
-			return 0, err
+			return
 		}
 		switch len(lowAndHigh) {
 		case 1:
@@ -274,10 +816,11 @@ func getRange(expr string, r bounds) (uint64, error) {
 		case 2:
 			end, err = parseIntOrName(lowAndHigh[1], r.names)
 			if err != nil {
-				return 0, err
+				return
 			}
 		default:
-			return 0, fmt.Errorf("too many hyphens: %s", expr)
+			err = parseErrorf(ReasonSyntax, expr, "too many hyphens: %s", expr)
+			return
 		}
 	}
 
@@ -287,34 +830,29 @@ func getRange(expr string, r bounds) (uint64, error) {
 	case 2:
 		step, err = mustParseInt(rangeAndStep[1])
 		if err != nil {
-			return 0, err
+			return
 		}
 
 		// Special handling: "N/step" means "N-max/step".
 		if singleDigit {
 			end = r.max
 		}
-		if step > 1 {
-			extra = 0
-		}
 	default:
-		return 0, fmt.Errorf("too many slashes: %s", expr)
+		err = parseErrorf(ReasonSyntax, expr, "too many slashes: %s", expr)
+		return
 	}
 
-	if start < r.min {
-		return 0, fmt.Errorf("beginning of range (%d) below minimum (%d): %s", start, r.min, expr)
+	switch {
+	case start < r.min:
+		err = parseErrorf(ReasonBelowMinimum, expr, "beginning of range (%d) below minimum (%d): %s", start, r.min, expr)
+	case end > r.max:
+		err = parseErrorf(ReasonAboveMaximum, expr, "end of range (%d) above maximum (%d): %s", end, r.max, expr)
+	case start > end:
+		err = parseErrorf(ReasonReversedRange, expr, "beginning of range (%d) beyond end of range (%d): %s", start, end, expr)
+	case step == 0:
+		err = parseErrorf(ReasonStep, expr, "step of range should be a positive number: %s", expr)
 	}
-	if end > r.max {
-		return 0, fmt.Errorf("end of range (%d) above maximum (%d): %s", end, r.max, expr)
-	}
-	if start > end {
-		return 0, fmt.Errorf("beginning of range (%d) beyond end of range (%d): %s", start, end, expr)
-	}
-	if step == 0 {
-		return 0, fmt.Errorf("step of range should be a positive number: %s", expr)
-	}
-
-	return getBits(start, end, step) | extra, nil
+	return
 }
 
 // parseIntOrName returns the (possibly-named) integer contained in expr.
@@ -331,10 +869,10 @@ func parseIntOrName(expr string, names map[string]uint) (uint, error) {
 func mustParseInt(expr string) (uint, error) {
 	num, err := strconv.Atoi(expr)
 	if err != nil {
-		return 0, fmt.Errorf("failed to parse int from %s: %s", expr, err)
+		return 0, parseErrorf(ReasonNumber, expr, "failed to parse int from %s: %s", expr, err)
 	}
 	if num < 0 {
-		return 0, fmt.Errorf("negative number (%d) not allowed: %s", num, expr)
+		return 0, parseErrorf(ReasonNumber, expr, "negative number (%d) not allowed: %s", num, expr)
 	}
 
 	return uint(num), nil
@@ -421,14 +959,146 @@ func parseDescriptor(descriptor string, loc *time.Location) (Schedule, error) {
 
 	}
 
+	for _, reboot := range []string{"@reboot", "@startup"} {
+		if descriptor == reboot || strings.HasPrefix(descriptor, reboot+" ") {
+			return parseReboot(strings.TrimSpace(descriptor[len(reboot):]), descriptor)
+		}
+	}
+
+	const at = "@at"
+	if descriptor == at || strings.HasPrefix(descriptor, at+" ") {
+		return parseAt(strings.Join(strings.Fields(descriptor[len(at):]), " "), descriptor, loc)
+	}
+
+	const bday = "@bday"
+	if descriptor == bday || strings.HasPrefix(descriptor, bday+" ") {
+		return parseBusinessDay(strings.Fields(descriptor[len(bday):]), descriptor, loc)
+	}
+
+	for event, name := range solarEventNames {
+		if solar := "@" + name; descriptor == solar || strings.HasPrefix(descriptor, solar+" ") {
+			return parseSolar(SolarEvent(event), strings.Fields(descriptor[len(solar):]), descriptor, loc)
+		}
+	}
+
 	const every = "@every "
 	if strings.HasPrefix(descriptor, every) {
-		duration, err := time.ParseDuration(descriptor[len(every):])
+		return parseEvery(strings.Fields(descriptor[len(every):]), descriptor, loc)
+	}
+
+	const interval = "@interval"
+	if descriptor == interval || strings.HasPrefix(descriptor, interval+" ") {
+		return parseInterval(strings.Fields(descriptor[len(interval):]), descriptor)
+	}
+
+	return nil, parseErrorf(ReasonDescriptor, "", "unrecognized descriptor: %s", descriptor)
+}
+
+// parseReboot returns the schedule for the "@reboot" descriptor given the
+// optional delay following it, of the form "+" duration.
+func parseReboot(delay, descriptor string) (Schedule, error) {
+	if delay == "" {
+		return RebootSchedule{}, nil
+	}
+	if !strings.HasPrefix(delay, "+") {
+		return nil, parseErrorf(ReasonSyntax, delay, "delay must start with '+': %s", descriptor)
+	}
+	duration, err := time.ParseDuration(delay[1:])
+	if err != nil {
+		return nil, parseErrorf(ReasonDuration, delay, "failed to parse duration %s: %s", descriptor, err)
+	}
+	if duration < 0 {
+		return nil, parseErrorf(ReasonDuration, delay, "negative delay not allowed: %s", descriptor)
+	}
+	return RebootSchedule{Delay: duration}, nil
+}
+
+// parseInterval returns the schedule for the "@interval" descriptor given the
+// duration following it.
+func parseInterval(fields []string, descriptor string) (Schedule, error) {
+	if len(fields) == 0 {
+		return nil, parseErrorf(ReasonDuration, "", "failed to parse duration %s: missing duration", descriptor)
+	}
+	if len(fields) > 1 {
+		return nil, parseErrorf(ReasonUnexpected, fields[1], "unexpected %q after duration: %s", fields[1], descriptor)
+	}
+	duration, err := time.ParseDuration(fields[0])
+	if err != nil {
+		return nil, parseErrorf(ReasonDuration, fields[0], "failed to parse duration %s: %s", descriptor, err)
+	}
+	if duration <= 0 {
+		return nil, parseErrorf(ReasonDuration, fields[0], "interval must be positive: %s", descriptor)
+	}
+	return Interval(duration), nil
+}
+
+// anchorLayouts are the layouts accepted for the anchor of an interval.
+var anchorLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// parseAnchor parses a time in one of anchorLayouts.  A time without an
+// offset is returned as a wall clock time in UTC, to be read in the given
+// location.
+func parseAnchor(value string, loc *time.Location) (time.Time, *time.Location, bool) {
+	for _, layout := range anchorLayouts {
+		t, err := time.Parse(layout, value)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse duration %s: %s", descriptor, err)
+			continue
 		}
+		if layout != time.RFC3339 {
+			return t, loc, true
+		}
+		return t, nil, true
+	}
+	return time.Time{}, nil, false
+}
+
+// parseEvery returns the schedule for the "@every" descriptor given the
+// fields following it:
+//   duration [ "aligned" | "after-completion" | "from" time ]
+func parseEvery(fields []string, descriptor string, loc *time.Location) (Schedule, error) {
+	if len(fields) == 0 {
+		return nil, parseErrorf(ReasonDuration, "", "failed to parse duration %s: missing duration", descriptor)
+	}
+	duration, err := time.ParseDuration(fields[0])
+	if err != nil {
+		return nil, parseErrorf(ReasonDuration, fields[0], "failed to parse duration %s: %s", descriptor, err)
+	}
+	if len(fields) == 1 {
 		return Every(duration), nil
 	}
+	if duration <= 0 {
+		return nil, parseErrorf(ReasonDuration, fields[0], "interval must be positive: %s", descriptor)
+	}
 
-	return nil, fmt.Errorf("unrecognized descriptor: %s", descriptor)
+	switch fields[1] {
+	case "aligned":
+		if len(fields) > 2 {
+			return nil, parseErrorf(ReasonUnexpected, fields[2], "unexpected %q after aligned: %s", fields[2], descriptor)
+		}
+		if (24*time.Hour)%duration != 0 {
+			return nil, parseErrorf(ReasonIntervalAlignment, fields[0], "aligned interval must divide a day evenly: %s", descriptor)
+		}
+		return AlignedSchedule{Interval: duration, Location: loc}, nil
+
+	case "after-completion":
+		if len(fields) > 2 {
+			return nil, parseErrorf(ReasonUnexpected, fields[2], "unexpected %q after after-completion: %s", fields[2], descriptor)
+		}
+		return FixedDelay(duration), nil
+
+	case "from":
+		value := strings.Join(fields[2:], " ")
+		if anchor, loc, ok := parseAnchor(value, loc); ok {
+			return AnchoredSchedule{Interval: duration, Anchor: anchor, Location: loc}, nil
+		}
+		return nil, parseErrorf(ReasonAnchor, value, "failed to parse anchor time %q: %s", value, descriptor)
+	}
+	return nil, parseErrorf(ReasonUnexpected, fields[1], "unexpected %q after duration: %s", fields[1], descriptor)
 }
@@ -1,6 +1,8 @@
 package cron
 
 import (
+	"fmt"
+	"math/bits"
 	"reflect"
 	"strings"
 	"testing"
@@ -118,11 +120,62 @@ func TestBits(t *testing.T) {
 	}
 }
 
+func TestDayField(t *testing.T) {
+	fields := []struct {
+		expr      string
+		r         bounds
+		parseRule dayRuleParser
+		bits      uint64
+		rules     []DayRule
+	}{
+		{"1,15", dom, parseDomRule, 1<<1 | 1<<15, nil},
+		{"L", dom, parseDomRule, 0, []DayRule{{Kind: LastDayOfMonth}}},
+		{"l-3", dom, parseDomRule, 0, []DayRule{{Kind: LastDayOfMonth, N: 3}}},
+		{"1,15W,LW", dom, parseDomRule, 1 << 1, []DayRule{{Kind: NearestWeekday, N: 15}, {Kind: LastWeekdayOfMonth}}},
+		{"2#2", dow, parseDowRule, 0, []DayRule{{Kind: NthDayOfWeek, N: 2, Weekday: time.Tuesday}}},
+		{"MON-WED,fri#1", dow, parseDowRule, 1<<1 | 1<<2 | 1<<3, []DayRule{{Kind: NthDayOfWeek, N: 1, Weekday: time.Friday}}},
+		{"5L", dow, parseDowRule, 0, []DayRule{{Kind: LastDayOfWeek, Weekday: time.Friday}}},
+	}
+
+	for _, c := range fields {
+		bits, rules, err := getDayField(c.expr, c.r, c.parseRule)
+		if err != nil {
+			t.Errorf("%s => unexpected error %v", c.expr, err)
+		}
+		if bits != c.bits {
+			t.Errorf("%s => expected %b, got %b", c.expr, c.bits, bits)
+		}
+		if !reflect.DeepEqual(rules, c.rules) {
+			t.Errorf("%s => expected %v, got %v", c.expr, c.rules, rules)
+		}
+	}
+}
+
 func TestParseScheduleErrors(t *testing.T) {
 	var tests = []struct{ expr, err string }{
+		{"0 0 0 L-31 * ?", "offset from last day (31) above maximum"},
+		{"0 0 0 32W * ?", "out of range"},
+		{"0 0 0 xW * ?", "failed to parse int from"},
+		{"0 0 0 ? * 2#6", "occurrence of weekday (6) out of range"},
+		{"0 0 0 ? * 7#1", "weekday (7) above maximum"},
+		{"0 0 0 ? * xyzL", "failed to parse int from"},
+		{"0 0 0 ? * L#2", "failed to parse int from"},
 		{"* 5 j * * *", "failed to parse int from"},
 		{"@every Xm", "failed to parse duration"},
 		{"@unrecognized", "unrecognized descriptor"},
+		{"@rebooted", "unrecognized descriptor"},
+		{"@every 7m aligned", "must divide a day evenly"},
+		{"@every 15m aligned now", "unexpected \"now\" after aligned"},
+		{"@every 15m later", "unexpected \"later\" after duration"},
+		{"@every 15m after-completion now", "unexpected \"now\" after after-completion"},
+		{"@every -15m aligned", "interval must be positive"},
+		{"@every 36h from tomorrow", "failed to parse anchor time"},
+		{"@interval", "missing duration"},
+		{"@interval 0s", "interval must be positive"},
+		{"@interval 250ms aligned", "unexpected \"aligned\" after duration"},
+		{"@reboot 30s", "delay must start with '+'"},
+		{"@reboot +30x", "failed to parse duration"},
+		{"@reboot +-30s", "negative delay not allowed"},
 		{"* * * *", "expected 5 to 6 fields"},
 		{"", "empty spec string"},
 	}
@@ -150,11 +203,23 @@ func TestParseSchedule(t *testing.T) {
 		{standardParser, "CRON_TZ=UTC  5 * * * *", every5min(time.UTC)},
 		{secondParser, "CRON_TZ=Asia/Tokyo 0 5 * * * *", every5min(tokyo)},
 		{secondParser, "@every 5m", ConstantDelaySchedule{5 * time.Minute}},
+		{secondParser, "@every 1.5s", ConstantDelaySchedule{time.Second}},
+		{secondParser, "@interval 250ms", IntervalSchedule{250 * time.Millisecond}},
 		{secondParser, "@midnight", midnight(time.Local)},
 		{secondParser, "TZ=UTC  @midnight", midnight(time.UTC)},
 		{secondParser, "TZ=Asia/Tokyo @midnight", midnight(tokyo)},
 		{secondParser, "@yearly", annual(time.Local)},
 		{secondParser, "@annually", annual(time.Local)},
+		{secondParser, "@every 15m aligned", AlignedSchedule{15 * time.Minute, time.Local}},
+		{secondParser, "@every 5m after-completion", FixedDelaySchedule{5 * time.Minute}},
+		{secondParser, "TZ=Asia/Tokyo @every 1h aligned", AlignedSchedule{time.Hour, tokyo}},
+		{secondParser, "@every 36h from 2026-01-01T09:30", AnchoredSchedule{36 * time.Hour, time.Date(2026, 1, 1, 9, 30, 0, 0, time.UTC), time.Local}},
+		{secondParser, "TZ=Asia/Tokyo @every 36h from 2026-01-01", AnchoredSchedule{36 * time.Hour, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), tokyo}},
+		{secondParser, "TZ=Asia/Tokyo @every 1h from 2026-01-01 09:30:15", AnchoredSchedule{time.Hour, time.Date(2026, 1, 1, 9, 30, 15, 0, time.UTC), tokyo}},
+		{secondParser, "@reboot", RebootSchedule{}},
+		{secondParser, "@startup", RebootSchedule{}},
+		{secondParser, "@reboot +30s", RebootSchedule{30 * time.Second}},
+		{secondParser, "TZ=UTC @startup +1m", RebootSchedule{time.Minute}},
 		{
 			parser: secondParser,
 			expr:   "* 5 * * * *",
@@ -252,6 +317,24 @@ func TestNormalizeFields(t *testing.T) {
 			SecondOptional | Hour | Dom | Month,
 			[]string{"0", "0", "5", "15", "*", "*"},
 		},
+		{
+			"AllFields_Year",
+			[]string{"0", "5", "*", "*", "*", "*", "2027"},
+			Second | Minute | Hour | Dom | Month | Dow | Year,
+			[]string{"0", "5", "*", "*", "*", "*", "2027"},
+		},
+		{
+			"SubsetFields_YearOptional_Provided",
+			[]string{"5", "*", "*", "*", "*", "2027"},
+			Minute | Hour | Dom | Month | Dow | YearOptional,
+			[]string{"0", "5", "*", "*", "*", "*", "2027"},
+		},
+		{
+			"SubsetFields_YearOptional_NotProvided",
+			[]string{"5", "*", "*", "*", "*"},
+			Minute | Hour | Dom | Month | Dow | YearOptional,
+			[]string{"0", "5", "*", "*", "*", "*", "*"},
+		},
 	}
 
 	for _, test := range tests {
@@ -280,6 +363,12 @@ func TestNormalizeFields_Errors(t *testing.T) {
 			SecondOptional | Minute | Hour | Dom | Month | DowOptional,
 			"",
 		},
+		{
+			"DowAndYearOptionals",
+			[]string{"0", "0", "1", "*", "*"},
+			Minute | Hour | Dom | Month | DowOptional | YearOptional,
+			"multiple optionals",
+		},
 		{
 			"TooManyFields",
 			[]string{"0", "5", "*", "*"},
@@ -320,7 +409,7 @@ func TestStandardSpecSchedule(t *testing.T) {
 	}{
 		{
 			expr:     "5 * * * *",
-			expected: &SpecSchedule{1 << seconds.min, 1 << 5, all(hours), all(dom), all(months), all(dow), time.Local},
+			expected: &SpecSchedule{Second: 1 << seconds.min, Minute: 1 << 5, Hour: all(hours), Dom: all(dom), Month: all(months), Dow: all(dow), Location: time.Local},
 		},
 		{
 			expr:     "@every 5m",
@@ -350,6 +439,115 @@ func TestStandardSpecSchedule(t *testing.T) {
 	}
 }
 
+func TestYearSchedule(t *testing.T) {
+	parser := NewParser(Minute | Hour | Dom | Month | Dow | YearOptional)
+	entries := []struct {
+		expr     string
+		expected []int
+		err      string
+	}{
+		{expr: "0 0 1 1 *", expected: nil},
+		{expr: "0 0 1 1 * *", expected: nil},
+		{expr: "0 0 1 1 * ?", expected: nil},
+		{expr: "0 0 1 1 * 2027", expected: []int{2027}},
+		{expr: "0 0 1 1 * 2027-2030", expected: []int{2027, 2028, 2029, 2030}},
+		{expr: "0 0 1 1 * 2030,2027-2028", expected: []int{2027, 2028, 2030}},
+		{expr: "0 0 1 1 * 2090/3", expected: []int{2090, 2093, 2096, 2099}},
+		{expr: "0 0 1 1 * 1969", err: "below minimum"},
+		{expr: "0 0 1 1 * 2100", err: "above maximum"},
+		{expr: "0 0 1 1 * 2030-2027", err: "beyond end of range"},
+	}
+
+	for _, c := range entries {
+		actual, err := parser.Parse(c.expr)
+		if len(c.err) != 0 {
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("%s => expected %v, got %v", c.expr, c.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s => unexpected error %v", c.expr, err)
+			continue
+		}
+		if year := actual.(*SpecSchedule).Year; !reflect.DeepEqual(year, c.expected) {
+			t.Errorf("%s => expected %v, got %v", c.expr, c.expected, year)
+		}
+	}
+}
+
+func TestHashSchedule(t *testing.T) {
+	entries := []struct {
+		expr, seed string
+		field      func(*SpecSchedule) uint64
+		r          bounds
+		step       uint
+	}{
+		{"H * * * *", "a", func(s *SpecSchedule) uint64 { return s.Minute }, minutes, 0},
+		{"0 H * * *", "b", func(s *SpecSchedule) uint64 { return s.Hour }, hours, 0},
+		{"0 0 H * *", "c", func(s *SpecSchedule) uint64 { return s.Dom }, bounds{1, 28, nil}, 0},
+		{"H(0-29) * * * *", "d", func(s *SpecSchedule) uint64 { return s.Minute }, bounds{0, 29, nil}, 0},
+		{"H(10-12) * * * *", "e", func(s *SpecSchedule) uint64 { return s.Minute }, bounds{10, 12, nil}, 0},
+		{"H/15 * * * *", "f", func(s *SpecSchedule) uint64 { return s.Minute }, minutes, 15},
+		{"H(30-59)/10 * * * *", "g", func(s *SpecSchedule) uint64 { return s.Minute }, bounds{30, 59, nil}, 10},
+	}
+
+	for _, c := range entries {
+		sched, err := standardParser.ParseWithSeed(c.expr, c.seed)
+		if err != nil {
+			t.Errorf("%s => unexpected error %v", c.expr, err)
+			continue
+		}
+		again, _ := standardParser.ParseWithSeed(c.expr, c.seed)
+		if !reflect.DeepEqual(sched, again) {
+			t.Errorf("%s => expected the same schedule for the same seed", c.expr)
+		}
+
+		actual := c.field(sched.(*SpecSchedule))
+		start := uint(bits.TrailingZeros64(actual))
+		if start < c.r.min || start > c.r.max || c.step > 0 && start >= c.r.min+c.step {
+			t.Errorf("%s => resolved start %d out of range", c.expr, start)
+		}
+		expected := getBits(start, start, 1)
+		if c.step > 0 {
+			expected = getBits(start, c.r.max, c.step)
+		}
+		if actual != expected {
+			t.Errorf("%s => expected %b, got %b", c.expr, expected, actual)
+		}
+	}
+}
+
+func TestHashSpreadsSeeds(t *testing.T) {
+	seen := make(map[uint64]bool)
+	for i := 0; i < 100; i++ {
+		sched, err := standardParser.ParseWithSeed("H * * * *", fmt.Sprint("job", i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen[sched.(*SpecSchedule).Minute] = true
+	}
+	if len(seen) < 20 {
+		t.Errorf("expected hashed minutes to spread out, got %d distinct values", len(seen))
+	}
+}
+
+func TestHashScheduleErrors(t *testing.T) {
+	var tests = []struct{ expr, err string }{
+		{"H(10) * * * *", "malformed hash range"},
+		{"H(10-5) * * * *", "beyond end of range"},
+		{"H(0-70) * * * *", "above maximum"},
+		{"H/0 * * * *", "should be a positive number"},
+		{"H(x-5) * * * *", "failed to parse int from"},
+	}
+	for _, c := range tests {
+		_, err := standardParser.ParseWithSeed(c.expr, "seed")
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%s => expected %v, got %v", c.expr, c.err, err)
+		}
+	}
+}
+
 func TestNoDescriptorParser(t *testing.T) {
 	parser := NewParser(Minute | Hour)
 	_, err := parser.Parse("@every 1m")
@@ -359,15 +557,15 @@ func TestNoDescriptorParser(t *testing.T) {
 }
 
 func every5min(loc *time.Location) *SpecSchedule {
-	return &SpecSchedule{1 << 0, 1 << 5, all(hours), all(dom), all(months), all(dow), loc}
+	return &SpecSchedule{Second: 1 << 0, Minute: 1 << 5, Hour: all(hours), Dom: all(dom), Month: all(months), Dow: all(dow), Location: loc}
 }
 
 func every5min5s(loc *time.Location) *SpecSchedule {
-	return &SpecSchedule{1 << 5, 1 << 5, all(hours), all(dom), all(months), all(dow), loc}
+	return &SpecSchedule{Second: 1 << 5, Minute: 1 << 5, Hour: all(hours), Dom: all(dom), Month: all(months), Dow: all(dow), Location: loc}
 }
 
 func midnight(loc *time.Location) *SpecSchedule {
-	return &SpecSchedule{1, 1, 1, all(dom), all(months), all(dow), loc}
+	return &SpecSchedule{Second: 1, Minute: 1, Hour: 1, Dom: all(dom), Month: all(months), Dow: all(dow), Location: loc}
 }
 
 func annual(loc *time.Location) *SpecSchedule {
@@ -0,0 +1,35 @@
+package cron
+
+import "time"
+
+// RebootSchedule activates once, Delay after the Cron scheduler first starts
+// running, and never again, even if the scheduler is stopped and started.  It
+// backs the "@reboot" descriptor.
+type RebootSchedule struct {
+	Delay time.Duration
+}
+
+// Start returns the time at which the schedule activates, for a scheduler
+// that starts running at the given time.
+func (schedule RebootSchedule) Start(t time.Time) time.Time {
+	return t.Add(schedule.Delay)
+}
+
+// Next always returns the zero time: apart from the activation returned by
+// Start, the schedule never activates.
+func (schedule RebootSchedule) Next(t time.Time) time.Time {
+	return time.Time{}
+}
+
+// String returns the "@reboot" descriptor for the schedule.
+func (schedule RebootSchedule) String() string {
+	if schedule.Delay > 0 {
+		return "@reboot +" + shortDuration(schedule.Delay)
+	}
+	return "@reboot"
+}
+
+// MarshalText implements encoding.TextMarshaler, returning the descriptor.
+func (schedule RebootSchedule) MarshalText() ([]byte, error) {
+	return []byte(schedule.String()), nil
+}
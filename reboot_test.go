@@ -0,0 +1,28 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRebootSchedule(t *testing.T) {
+	tests := []struct {
+		time     string
+		delay    time.Duration
+		expected string
+	}{
+		{"Mon Jul 9 14:45 2012", 0, "Mon Jul 9 14:45 2012"},
+		{"Mon Jul 9 14:45 2012", 30 * time.Second, "Mon Jul 9 14:45:30 2012"},
+		{"Mon Jul 9 23:59:45 2012", 2 * time.Minute, "Tue Jul 10 00:01:45 2012"},
+	}
+
+	for _, c := range tests {
+		schedule := RebootSchedule{Delay: c.delay}
+		if actual, expected := schedule.Start(getTime(c.time)), getTime(c.expected); actual != expected {
+			t.Errorf("%s, %s: (expected) %v != %v (actual)", c.time, c.delay, expected, actual)
+		}
+		if actual := schedule.Next(getTime(c.time)); !actual.IsZero() {
+			t.Errorf("%s, %s: expected no next activation, got %v", c.time, c.delay, actual)
+		}
+	}
+}
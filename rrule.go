@@ -0,0 +1,939 @@
+package cron
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency is the FREQ of a recurrence rule: the period in which the rule
+// repeats.
+type Frequency int
+
+const (
+	Secondly Frequency = iota
+	Minutely
+	Hourly
+	Daily
+	Weekly
+	Monthly
+	Yearly
+)
+
+var frequencyNames = []string{"SECONDLY", "MINUTELY", "HOURLY", "DAILY", "WEEKLY", "MONTHLY", "YEARLY"}
+
+// String returns the name of the frequency in a recurrence rule, e.g. "DAILY".
+func (f Frequency) String() string {
+	if f < Secondly || f > Yearly {
+		return "Frequency(" + strconv.Itoa(int(f)) + ")"
+	}
+	return frequencyNames[f]
+}
+
+// WeekdayNum is a BYDAY value of a recurrence rule: a weekday, and for
+// monthly and yearly rules optionally its ordinal within the month or year,
+// e.g. "-1FR" for the last Friday.
+type WeekdayNum struct {
+	N       int // 0 for every such weekday, negative to count from the end
+	Weekday time.Weekday
+}
+
+// String returns the BYDAY value, e.g. "MO" or "-1FR".
+func (w WeekdayNum) String() string {
+	day := weekdayCodes[w.Weekday]
+	if w.N != 0 {
+		day = strconv.Itoa(w.N) + day
+	}
+	return day
+}
+
+var weekdayCodes = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// RecurrenceRule is an RFC 5545 recurrence rule, as given by the RRULE and
+// EXRULE properties.  The BY fields limit or expand the occurrences within
+// each period, as described by the RFC; negative values count from the end.
+type RecurrenceRule struct {
+	Freq     Frequency
+	Interval int       // the number of periods between occurrences; 0 means 1
+	Count    int       // the number of occurrences, or 0 for no limit
+	Until    time.Time // the last possible occurrence, or zero for no limit
+
+	BySecond   []int
+	ByMinute   []int
+	ByHour     []int
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	ByYearDay  []int
+	ByWeekNo   []int
+	ByMonth    []int
+	BySetPos   []int
+
+	// WeekStart is the first day of the week, for weekly rules and BYWEEKNO.
+	// The parser defaults it to Monday, as RFC 5545 does.
+	WeekStart time.Weekday
+
+	// cutoff is the last occurrence allowed by Count, as counted by the
+	// parser.
+	cutoff countCutoff
+}
+
+// countCutoff is the COUNT-th occurrence of a rule starting at start, as a
+// wall clock time in UTC, or the zero time if there is none up to the end of
+// the year limit.
+type countCutoff struct {
+	start, last time.Time
+	limit       int
+}
+
+// RecurrenceSchedule is a Schedule given by RFC 5545 recurrence properties,
+// as exported by calendar applications, e.g.:
+//
+//	DTSTART;TZID=America/New_York:20260105T090000
+//	RRULE:FREQ=MONTHLY;BYDAY=MO,TU;BYSETPOS=-1;COUNT=10
+//	EXDATE;TZID=America/New_York:20260331T090000
+//
+// It activates at the occurrences of its rules and at its dates, except those
+// of its exclusion rules and dates.  Wall clock times are read in the
+// location of Start; as RFC 5545 specifies, one which occurs twice is the
+// first of them, and one skipped by a daylight saving time change is read
+// with the UTC offset before the change.
+type RecurrenceSchedule struct {
+	Start   time.Time        // DTSTART, the first possible occurrence
+	Rules   []RecurrenceRule // RRULE
+	ExRules []RecurrenceRule // EXRULE
+	Dates   []time.Time      // RDATE
+	ExDates []time.Time      // EXDATE
+}
+
+// recurrenceYears is how far after the given time Next looks for an
+// occurrence.  Unlike SpecSchedule, which jumps between matching dates, rules
+// are expanded a period at a time, so they are not searched for a whole
+// cycle of the calendar.
+const recurrenceYears = 5
+
+// Next returns the first occurrence after the given time, or the zero time
+// if there is none within five years.
+func (s *RecurrenceSchedule) Next(t time.Time) time.Time {
+	limit := t.In(s.Start.Location()).Year() + recurrenceYears
+
+	var next time.Time
+	for i := range s.Rules {
+		for after := t; ; {
+			occurrence := s.Rules[i].next(s.Start, after, limit)
+			if occurrence.IsZero() || !next.IsZero() && !occurrence.Before(next) {
+				break
+			}
+			if !s.excluded(occurrence, limit) {
+				next = occurrence
+				break
+			}
+			after = occurrence
+		}
+	}
+	for _, date := range s.Dates {
+		if date.After(t) && (next.IsZero() || date.Before(next)) && !s.excluded(date, limit) {
+			next = date
+		}
+	}
+	if next.IsZero() {
+		return next
+	}
+	return next.In(t.Location())
+}
+
+// excluded returns true if the given time is one of the exclusion dates or
+// an occurrence of one of the exclusion rules.
+func (s *RecurrenceSchedule) excluded(t time.Time, limit int) bool {
+	for _, date := range s.ExDates {
+		if date.Equal(t) {
+			return true
+		}
+	}
+	for i := range s.ExRules {
+		if s.ExRules[i].next(s.Start, t.Add(-time.Nanosecond), limit).Equal(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// next returns the first occurrence of the rule starting at dtstart after the
+// given time, or the zero time if there is none up to the end of the year
+// limit.
+func (r *RecurrenceRule) next(dtstart, after time.Time, limit int) time.Time {
+	var (
+		loc   = dtstart.Location()
+		start = wallClock(dtstart)
+		last  time.Time
+	)
+	if r.Count > 0 {
+		last = r.countCutoff(start, limit).last
+	}
+
+	// Wall clock times shifted out of a daylight saving time gap occur later
+	// than the wall clock suggests, by as much as the UTC offset rose.  So
+	// start that much earlier, and keep looking for as long after the first
+	// occurrence found.
+	var (
+		next time.Time
+		stop time.Time
+		from = after.In(loc)
+	)
+	r.each(start, wallClock(from).Add(-offsetRise(from)), limit, func(occurrence time.Time) bool {
+		if !last.IsZero() && occurrence.After(last) || !stop.IsZero() && occurrence.After(stop) {
+			return false
+		}
+		t := localTime(occurrence, loc)
+		if !r.Until.IsZero() && t.After(r.Until) {
+			return false
+		}
+		if t.After(after) && (next.IsZero() || t.Before(next)) {
+			next = t
+			if stop.IsZero() {
+				stop = occurrence.Add(offsetRise(t))
+			}
+		}
+		return true
+	})
+	return next
+}
+
+// countCutoff returns the COUNT-th occurrence of the rule starting at the
+// given wall clock time, counting up to the end of the year limit unless the
+// parser already has.
+func (r *RecurrenceRule) countCutoff(start time.Time, limit int) countCutoff {
+	if c := r.cutoff; c.start.Equal(start) && (!c.last.IsZero() || c.limit >= limit) {
+		return c
+	}
+	c := countCutoff{start: start, limit: limit}
+	n := 0
+	r.each(start, start, limit, func(occurrence time.Time) bool {
+		if n++; n == r.Count {
+			c.last = occurrence
+			return false
+		}
+		return true
+	})
+	return c
+}
+
+// offsetRise returns how much the UTC offset of the time's location rose
+// in the day before it, as it does for daylight saving time, or 0.
+func offsetRise(t time.Time) time.Duration {
+	_, before := t.Add(-24 * time.Hour).Zone()
+	_, offset := t.Zone()
+	if offset <= before {
+		return 0
+	}
+	return time.Duration(offset-before) * time.Second
+}
+
+// each calls fn with the wall clock times of the rule's occurrences, in
+// order, from the period containing from until fn returns false or the
+// periods pass the end of the year limit.  Wall clock times are given as
+// times in UTC.
+func (r *RecurrenceRule) each(start, from time.Time, limit int, fn func(time.Time) bool) {
+	for p := r.period(start, from); ; p++ {
+		begin := r.periodStart(start, p)
+		if begin.Year() > limit {
+			return
+		}
+		occurrences, skip := r.expand(start, begin)
+		for _, occurrence := range occurrences {
+			if !occurrence.Before(start) && !fn(occurrence) {
+				return
+			}
+		}
+		if !skip.IsZero() {
+			if q := r.period(start, skip); q > p {
+				p = q - 1
+			}
+		}
+	}
+}
+
+// interval returns the number of periods between occurrences.
+func (r *RecurrenceRule) interval() int {
+	if r.Interval < 1 {
+		return 1
+	}
+	return r.Interval
+}
+
+// period returns the index of the period of the rule containing the given
+// wall clock time, or the last one before it.
+func (r *RecurrenceRule) period(start, t time.Time) int {
+	var units int
+	switch r.Freq {
+	case Yearly:
+		units = t.Year() - start.Year()
+	case Monthly:
+		units = (t.Year()-start.Year())*12 + int(t.Month()) - int(start.Month())
+	case Weekly:
+		units = int(r.weekStart(t).Sub(r.weekStart(start)) / (7 * 24 * time.Hour))
+	case Daily:
+		units = int(startOfDay(t).Sub(startOfDay(start)) / (24 * time.Hour))
+	case Hourly:
+		units = int(t.Truncate(time.Hour).Sub(start.Truncate(time.Hour)) / time.Hour)
+	case Minutely:
+		units = int(t.Truncate(time.Minute).Sub(start.Truncate(time.Minute)) / time.Minute)
+	default:
+		units = int(t.Sub(start) / time.Second)
+	}
+	if units < 0 {
+		return 0
+	}
+	return units / r.interval()
+}
+
+// periodStart returns the wall clock time at which the period of the rule
+// with the given index begins.
+func (r *RecurrenceRule) periodStart(start time.Time, p int) time.Time {
+	n := p * r.interval()
+	switch r.Freq {
+	case Yearly:
+		return time.Date(start.Year()+n, time.January, 1, 0, 0, 0, 0, time.UTC)
+	case Monthly:
+		return time.Date(start.Year(), start.Month()+time.Month(n), 1, 0, 0, 0, 0, time.UTC)
+	case Weekly:
+		return r.weekStart(start).AddDate(0, 0, 7*n)
+	case Daily:
+		return startOfDay(start).AddDate(0, 0, n)
+	case Hourly:
+		return start.Truncate(time.Hour).Add(time.Duration(n) * time.Hour)
+	case Minutely:
+		return start.Truncate(time.Minute).Add(time.Duration(n) * time.Minute)
+	}
+	return start.Add(time.Duration(n) * time.Second)
+}
+
+// expand returns the wall clock times of the occurrences in the period
+// beginning at the given time, in order.  If a rule more frequent than daily
+// cannot occur until later in the day, it also returns when it may next.
+func (r *RecurrenceRule) expand(start, begin time.Time) (occurrences []time.Time, skip time.Time) {
+	var end time.Time
+	switch r.Freq {
+	case Yearly:
+		end = begin.AddDate(1, 0, 0)
+	case Monthly:
+		end = begin.AddDate(0, 1, 0)
+	case Weekly:
+		end = begin.AddDate(0, 0, 7)
+	default:
+		end = startOfDay(begin).AddDate(0, 0, 1)
+	}
+	var days []time.Time
+	for d := startOfDay(begin); d.Before(end); d = d.AddDate(0, 0, 1) {
+		if r.dayMatches(start, d) {
+			days = append(days, d)
+		}
+	}
+	if len(days) == 0 {
+		if r.Freq < Daily {
+			return nil, end
+		}
+		return nil, time.Time{}
+	}
+
+	hours := timeValues(r.ByHour, start.Hour(), begin.Hour(), r.Freq <= Hourly)
+	if len(hours) == 0 && r.Freq < Hourly {
+		return nil, begin.Truncate(time.Hour).Add(time.Hour)
+	}
+	minutes := timeValues(r.ByMinute, start.Minute(), begin.Minute(), r.Freq <= Minutely)
+	if len(minutes) == 0 && r.Freq < Minutely {
+		return nil, begin.Truncate(time.Minute).Add(time.Minute)
+	}
+	seconds := timeValues(r.BySecond, start.Second(), begin.Second(), r.Freq == Secondly)
+	for _, d := range days {
+		for _, hour := range hours {
+			for _, minute := range minutes {
+				for _, second := range seconds {
+					occurrences = append(occurrences, time.Date(d.Year(), d.Month(), d.Day(), hour, minute, second, 0, time.UTC))
+				}
+			}
+		}
+	}
+	if len(r.BySetPos) > 0 {
+		occurrences = setPositions(occurrences, r.BySetPos)
+	}
+	return occurrences, time.Time{}
+}
+
+// timeValues returns the values of a time field within a day, in order.  If
+// the field is fixed by the period, it is the current value, if allowed.
+// Otherwise it is the values given, or else the value of DTSTART.
+func timeValues(values []int, start, current int, fixed bool) []int {
+	if fixed {
+		if len(values) == 0 || containsInt(values, current) {
+			return []int{current}
+		}
+		return nil
+	}
+	if len(values) == 0 {
+		return []int{start}
+	}
+	sorted := append([]int(nil), values...)
+	sort.Ints(sorted)
+	return sorted
+}
+
+// dayMatches returns true if the rule may occur on the given day.  Rules
+// which do not give any days occur on the day of DTSTART within their period.
+func (r *RecurrenceRule) dayMatches(start, day time.Time) bool {
+	var (
+		year  = day.Year()
+		month = day.Month()
+	)
+	if len(r.ByMonth) > 0 && !containsInt(r.ByMonth, int(month)) {
+		return false
+	}
+	if len(r.ByWeekNo) > 0 && !r.weekNoMatches(day) {
+		return false
+	}
+	if len(r.ByYearDay) > 0 && !signedMatch(r.ByYearDay, day.YearDay(), daysInYear(year)) {
+		return false
+	}
+	if len(r.ByMonthDay) > 0 && !signedMatch(r.ByMonthDay, day.Day(), daysIn(month, year)) {
+		return false
+	}
+	if len(r.ByDay) > 0 && !r.byDayMatches(day) {
+		return false
+	}
+	if len(r.ByWeekNo)+len(r.ByYearDay)+len(r.ByMonthDay)+len(r.ByDay) > 0 {
+		return true
+	}
+	switch r.Freq {
+	case Yearly:
+		return (len(r.ByMonth) > 0 || month == start.Month()) && day.Day() == start.Day()
+	case Monthly:
+		return day.Day() == start.Day()
+	case Weekly:
+		return day.Weekday() == start.Weekday()
+	}
+	return true
+}
+
+// byDayMatches returns true if the day is one of the BYDAY weekdays.  Their
+// ordinals count within the month, or for yearly rules without BYMONTH,
+// within the year.
+func (r *RecurrenceRule) byDayMatches(day time.Time) bool {
+	for _, w := range r.ByDay {
+		if day.Weekday() != w.Weekday {
+			continue
+		}
+		if w.N == 0 {
+			return true
+		}
+		n, length := day.Day(), daysIn(day.Month(), day.Year())
+		if r.Freq == Yearly && len(r.ByMonth) == 0 {
+			n, length = day.YearDay(), daysInYear(day.Year())
+		}
+		if w.N > 0 && (n-1)/7+1 == w.N || w.N < 0 && (length-n)/7+1 == -w.N {
+			return true
+		}
+	}
+	return false
+}
+
+// weekNoMatches returns true if the day is in one of the BYWEEKNO weeks.  As
+// in ISO 8601, the first week of the year is the first with at least four
+// days in the year, but weeks start on WeekStart.
+func (r *RecurrenceRule) weekNoMatches(day time.Time) bool {
+	year, week := r.week(day)
+	_, weeks := r.week(time.Date(year, time.December, 28, 0, 0, 0, 0, time.UTC))
+	return signedMatch(r.ByWeekNo, week, weeks)
+}
+
+// week returns the year and number of the week containing the given day.
+func (r *RecurrenceRule) week(day time.Time) (year, week int) {
+	// A week belongs to the year containing its fourth day.
+	fourth := r.weekStart(day).AddDate(0, 0, 3)
+	return fourth.Year(), (fourth.YearDay()-1)/7 + 1
+}
+
+// weekStart returns the midnight beginning the week containing t.
+func (r *RecurrenceRule) weekStart(t time.Time) time.Time {
+	return startOfDay(t).AddDate(0, 0, -(int(t.Weekday())-int(r.WeekStart)+7)%7)
+}
+
+// setPositions returns the occurrences at the given BYSETPOS positions, in
+// order.
+func setPositions(occurrences []time.Time, positions []int) []time.Time {
+	var indexes []int
+	for _, pos := range positions {
+		i := pos - 1
+		if pos < 0 {
+			i = len(occurrences) + pos
+		}
+		if i >= 0 && i < len(occurrences) && !containsInt(indexes, i) {
+			indexes = append(indexes, i)
+		}
+	}
+	sort.Ints(indexes)
+	selected := make([]time.Time, len(indexes))
+	for j, i := range indexes {
+		selected[j] = occurrences[i]
+	}
+	return selected
+}
+
+// signedMatch returns true if value is one of the values, where negative
+// values count back from the last one, n.
+func signedMatch(values []int, value, n int) bool {
+	for _, v := range values {
+		if v == value || v < 0 && n+1+v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func daysInYear(year int) int {
+	return time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC).YearDay()
+}
+
+// startOfDay returns the start of the day of the given wall clock time.
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// localTime returns the time in loc with the given wall clock, given as a
+// time in UTC.  As in RFC 5545, a wall clock which occurs twice is the first
+// of them, and one skipped by a daylight saving time change is read with the
+// UTC offset before the change.
+func localTime(wall time.Time, loc *time.Location) time.Time {
+	// UTC offsets are within 14 hours, so these are before and after any
+	// time with the wall clock.
+	_, before := wall.Add(-26 * time.Hour).In(loc).Zone()
+	_, after := wall.Add(26 * time.Hour).In(loc).Zone()
+	for _, offset := range []int{before, after} {
+		t := wall.Add(-time.Duration(offset) * time.Second).In(loc)
+		if _, o := t.Zone(); o == offset {
+			return t
+		}
+	}
+	return wall.Add(-time.Duration(before) * time.Second).In(loc)
+}
+
+// String returns the recurrence as iCalendar properties separated by spaces,
+// which Parser turns back into an equal schedule.
+func (s *RecurrenceSchedule) String() string {
+	props := []string{dateTimeProperty("DTSTART", []time.Time{s.Start}, s.Start.Location())}
+	for _, r := range s.Rules {
+		props = append(props, "RRULE:"+r.String())
+	}
+	for _, r := range s.ExRules {
+		props = append(props, "EXRULE:"+r.String())
+	}
+	if len(s.Dates) > 0 {
+		props = append(props, dateTimeProperty("RDATE", s.Dates, s.Start.Location()))
+	}
+	if len(s.ExDates) > 0 {
+		props = append(props, dateTimeProperty("EXDATE", s.ExDates, s.Start.Location()))
+	}
+	return strings.Join(props, " ")
+}
+
+// MarshalText implements encoding.TextMarshaler, returning the properties.
+func (s *RecurrenceSchedule) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, accepting the properties.
+func (s *RecurrenceSchedule) UnmarshalText(text []byte) error {
+	schedule, err := parseText(string(text))
+	if err != nil {
+		return err
+	}
+	recurrence, ok := schedule.(*RecurrenceSchedule)
+	if !ok {
+		return fmt.Errorf("not a recurrence: %s", text)
+	}
+	*s = *recurrence
+	return nil
+}
+
+// String returns the value of the RRULE or EXRULE property for the rule,
+// e.g. "FREQ=MONTHLY;BYDAY=MO,TU;BYSETPOS=-1;COUNT=10".
+func (r RecurrenceRule) String() string {
+	parts := []string{"FREQ=" + r.Freq.String()}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format(utcDateTimeLayout))
+	}
+	for _, by := range []struct {
+		name   string
+		values []int
+	}{
+		{"BYMONTH", r.ByMonth},
+		{"BYWEEKNO", r.ByWeekNo},
+		{"BYYEARDAY", r.ByYearDay},
+		{"BYMONTHDAY", r.ByMonthDay},
+	} {
+		if len(by.values) > 0 {
+			parts = append(parts, by.name+"="+joinInts(by.values))
+		}
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, w := range r.ByDay {
+			days[i] = w.String()
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	for _, by := range []struct {
+		name   string
+		values []int
+	}{
+		{"BYHOUR", r.ByHour},
+		{"BYMINUTE", r.ByMinute},
+		{"BYSECOND", r.BySecond},
+		{"BYSETPOS", r.BySetPos},
+	} {
+		if len(by.values) > 0 {
+			parts = append(parts, by.name+"="+joinInts(by.values))
+		}
+	}
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+weekdayCodes[r.WeekStart])
+	}
+	return strings.Join(parts, ";")
+}
+
+func joinInts(values []int) string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = strconv.Itoa(v)
+	}
+	return strings.Join(s, ",")
+}
+
+const (
+	dateLayout        = "20060102"
+	dateTimeLayout    = "20060102T150405"
+	utcDateTimeLayout = "20060102T150405Z"
+)
+
+// dateTimeProperty returns a property listing the given times: in UTC, as
+// floating times for time.Local, and otherwise with the TZID of loc.
+func dateTimeProperty(name string, times []time.Time, loc *time.Location) string {
+	values := make([]string, len(times))
+	for i, t := range times {
+		if loc == time.UTC {
+			values[i] = t.UTC().Format(utcDateTimeLayout)
+		} else {
+			values[i] = t.In(loc).Format(dateTimeLayout)
+		}
+	}
+	if loc != time.UTC && loc != time.Local {
+		name += ";TZID=" + loc.String()
+	}
+	return name + ":" + strings.Join(values, ",")
+}
+
+// recurrenceProperties are the iCalendar properties that make up a
+// recurrence.
+var recurrenceProperties = []string{"DTSTART", "RRULE", "EXRULE", "RDATE", "EXDATE"}
+
+// isRecurrence returns true if the spec starts with one of the recurrence
+// properties.
+func isRecurrence(spec string) bool {
+	upper := strings.ToUpper(spec)
+	for _, name := range recurrenceProperties {
+		if strings.HasPrefix(upper, name+":") || strings.HasPrefix(upper, name+";") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRecurrence returns the schedule for a spec of whitespace-separated
+// recurrence properties.  Floating times, without a TZID, are read in loc.
+// Without DTSTART, the recurrence starts at midnight on 1 January 1970.
+func parseRecurrence(spec string, loc *time.Location) (*RecurrenceSchedule, error) {
+	var (
+		s       = &RecurrenceSchedule{Start: time.Date(1970, time.January, 1, 0, 0, 0, 0, loc)}
+		props   = strings.Fields(spec)
+		dtstart string
+	)
+	for _, prop := range props {
+		name, params, value, err := splitProperty(prop)
+		if err != nil {
+			return nil, err
+		}
+		if name != "DTSTART" {
+			continue
+		}
+		if dtstart != "" {
+			return nil, parseErrorf(ReasonUnexpected, prop, "more than one DTSTART: %s", prop)
+		}
+		dtstart = prop
+		if s.Start, err = parseDateTime(prop, value, params, loc); err != nil {
+			return nil, err
+		}
+	}
+
+	loc = s.Start.Location()
+	for _, prop := range props {
+		name, params, value, _ := splitProperty(prop)
+		var err error
+		switch name {
+		case "DTSTART":
+		case "RRULE", "EXRULE":
+			var r RecurrenceRule
+			if r, err = parseRule(value, loc); err != nil {
+				break
+			}
+			if name == "RRULE" {
+				s.Rules = append(s.Rules, r)
+			} else {
+				s.ExRules = append(s.ExRules, r)
+			}
+		case "RDATE", "EXDATE":
+			for _, v := range strings.Split(value, ",") {
+				var t time.Time
+				if t, err = parseDateTime(prop, v, params, loc); err != nil {
+					break
+				}
+				if name == "RDATE" {
+					s.Dates = append(s.Dates, t)
+				} else {
+					s.ExDates = append(s.ExDates, t)
+				}
+			}
+		default:
+			err = parseErrorf(ReasonUnexpected, prop, "unrecognized recurrence property %s: %s", name, prop)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(s.Rules) == 0 && len(s.Dates) == 0 {
+		return nil, parseErrorf(ReasonSyntax, "", "recurrence needs an RRULE or RDATE: %s", spec)
+	}
+
+	// Count the occurrences of rules with a COUNT once, rather than on each
+	// call to Next.
+	for _, rules := range [][]RecurrenceRule{s.Rules, s.ExRules} {
+		for i := range rules {
+			if r := &rules[i]; r.Count > 0 {
+				r.cutoff = r.countCutoff(wallClock(s.Start), int(years.max))
+			}
+		}
+	}
+	return s, nil
+}
+
+// splitProperty splits an iCalendar property into its upper case name, its
+// parameters and its value, e.g. "DTSTART;TZID=Asia/Tokyo:20260101T090000".
+func splitProperty(prop string) (name string, params map[string]string, value string, err error) {
+	i := strings.Index(prop, ":")
+	if i < 0 {
+		return "", nil, "", parseErrorf(ReasonSyntax, prop, "missing ':' in recurrence property %s", prop)
+	}
+	parts := strings.Split(prop[:i], ";")
+	params = make(map[string]string)
+	for _, param := range parts[1:] {
+		kv := strings.SplitN(param, "=", 2)
+		if len(kv) != 2 {
+			return "", nil, "", parseErrorf(ReasonSyntax, param, "missing '=' in parameter %s: %s", param, prop)
+		}
+		params[strings.ToUpper(kv[0])] = kv[1]
+	}
+	return strings.ToUpper(parts[0]), params, prop[i+1:], nil
+}
+
+// parseDateTime returns the time for an iCalendar DATE or DATE-TIME value of
+// the given property, in UTC if it ends in "Z", and otherwise in the
+// location of its TZID parameter or in loc.
+func parseDateTime(prop, value string, params map[string]string, loc *time.Location) (time.Time, error) {
+	if tzid := params["TZID"]; tzid != "" {
+		var err error
+		if loc, err = time.LoadLocation(tzid); err != nil {
+			return time.Time{}, parseErrorf(ReasonLocation, tzid, "provided bad location %s: %v", tzid, err)
+		}
+	}
+	if kind := strings.ToUpper(params["VALUE"]); kind != "" && kind != "DATE" && kind != "DATE-TIME" {
+		return time.Time{}, parseErrorf(ReasonUnexpected, params["VALUE"], "unsupported value type %s: %s", params["VALUE"], prop)
+	}
+	if t, err := time.Parse(utcDateTimeLayout, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{dateTimeLayout, dateLayout} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return localTime(t, loc), nil
+		}
+	}
+	return time.Time{}, parseErrorf(ReasonSyntax, value, "failed to parse date-time %s: %s", value, prop)
+}
+
+// icalWeekdays are the weekdays of recurrence rules, by their codes.
+var icalWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// parseRule returns the recurrence rule for the value of an RRULE or EXRULE
+// property.  Floating UNTIL times are read in loc.
+func parseRule(value string, loc *time.Location) (RecurrenceRule, error) {
+	var (
+		r    = RecurrenceRule{WeekStart: time.Monday}
+		seen = make(map[string]bool)
+	)
+	for _, part := range strings.Split(value, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return r, parseErrorf(ReasonSyntax, part, "missing '=' in rule part %s: %s", part, value)
+		}
+		name, v := strings.ToUpper(kv[0]), strings.ToUpper(kv[1])
+		if seen[name] {
+			return r, parseErrorf(ReasonUnexpected, part, "more than one %s: %s", name, value)
+		}
+		seen[name] = true
+
+		var err error
+		switch name {
+		case "FREQ":
+			r.Freq = -1
+			for f, freq := range frequencyNames {
+				if v == freq {
+					r.Freq = Frequency(f)
+				}
+			}
+			if r.Freq < 0 {
+				err = parseErrorf(ReasonSyntax, part, "unrecognized frequency %s: %s", kv[1], value)
+			}
+		case "INTERVAL":
+			r.Interval, err = ruleInt(part, v, 1, math.MaxInt32)
+		case "COUNT":
+			r.Count, err = ruleInt(part, v, 1, math.MaxInt32)
+		case "UNTIL":
+			r.Until, err = parseDateTime(part, v, nil, loc)
+		case "BYSECOND":
+			r.BySecond, err = ruleInts(part, v, 0, 59, false)
+		case "BYMINUTE":
+			r.ByMinute, err = ruleInts(part, v, 0, 59, false)
+		case "BYHOUR":
+			r.ByHour, err = ruleInts(part, v, 0, 23, false)
+		case "BYDAY":
+			r.ByDay, err = parseByDay(part, v)
+		case "BYMONTHDAY":
+			r.ByMonthDay, err = ruleInts(part, v, 1, 31, true)
+		case "BYYEARDAY":
+			r.ByYearDay, err = ruleInts(part, v, 1, 366, true)
+		case "BYWEEKNO":
+			r.ByWeekNo, err = ruleInts(part, v, 1, 53, true)
+		case "BYMONTH":
+			r.ByMonth, err = ruleInts(part, v, 1, 12, false)
+		case "BYSETPOS":
+			r.BySetPos, err = ruleInts(part, v, 1, 366, true)
+		case "WKST":
+			var ok bool
+			if r.WeekStart, ok = icalWeekdays[v]; !ok {
+				err = parseErrorf(ReasonSyntax, part, "unrecognized weekday %s: %s", kv[1], value)
+			}
+		default:
+			err = parseErrorf(ReasonUnexpected, part, "unrecognized rule part %s: %s", kv[0], value)
+		}
+		if err != nil {
+			return r, err
+		}
+	}
+
+	switch {
+	case !seen["FREQ"]:
+		return r, parseErrorf(ReasonSyntax, value, "rule has no FREQ: %s", value)
+	case r.Count > 0 && !r.Until.IsZero():
+		return r, parseErrorf(ReasonSyntax, value, "rule has both COUNT and UNTIL: %s", value)
+	case len(r.ByWeekNo) > 0 && r.Freq != Yearly:
+		return r, parseErrorf(ReasonSyntax, value, "BYWEEKNO is only allowed in yearly rules: %s", value)
+	}
+	for _, w := range r.ByDay {
+		if w.N != 0 && (r.Freq < Monthly || len(r.ByWeekNo) > 0) {
+			return r, parseErrorf(ReasonSyntax, w.String(), "numbered BYDAY is only allowed in monthly and yearly rules without BYWEEKNO: %s", value)
+		}
+	}
+	return r, nil
+}
+
+// ruleInt returns the integer value of a rule part, within [min, max].
+func ruleInt(part, value string, min, max int) (int, error) {
+	values, err := ruleInts(part, value, min, max, false)
+	if err != nil {
+		return 0, err
+	}
+	if len(values) != 1 {
+		return 0, parseErrorf(ReasonSyntax, part, "expected a single value: %s", part)
+	}
+	return values[0], nil
+}
+
+// ruleInts returns the comma-separated integer values of a rule part, within
+// [min, max] or, if signed, within [-max, -min] too.
+func ruleInts(part, value string, min, max int, signed bool) ([]int, error) {
+	var values []int
+	for _, s := range strings.Split(value, ",") {
+		v, err := strconv.Atoi(strings.TrimPrefix(s, "+"))
+		if err != nil {
+			return nil, parseErrorf(ReasonNumber, part, "failed to parse int from %s: %s", s, err)
+		}
+		abs := v
+		if signed && v < 0 {
+			abs = -v
+		}
+		if abs < min {
+			return nil, parseErrorf(ReasonBelowMinimum, part, "value (%d) below minimum (%d): %s", v, min, part)
+		}
+		if abs > max {
+			return nil, parseErrorf(ReasonAboveMaximum, part, "value (%d) above maximum (%d): %s", v, max, part)
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// parseByDay returns the weekdays of a BYDAY rule part, e.g. "MO,-1FR".
+func parseByDay(part, value string) ([]WeekdayNum, error) {
+	var days []WeekdayNum
+	for _, s := range strings.Split(value, ",") {
+		if len(s) < 2 {
+			return nil, parseErrorf(ReasonSyntax, part, "unrecognized weekday %s: %s", s, part)
+		}
+		weekday, ok := icalWeekdays[s[len(s)-2:]]
+		if !ok {
+			return nil, parseErrorf(ReasonSyntax, part, "unrecognized weekday %s: %s", s, part)
+		}
+		w := WeekdayNum{Weekday: weekday}
+		if n := s[:len(s)-2]; n != "" {
+			values, err := ruleInts(part, n, 1, 53, true)
+			if err != nil {
+				return nil, err
+			}
+			w.N = values[0]
+		}
+		days = append(days, w)
+	}
+	return days, nil
+}
@@ -0,0 +1,243 @@
+package cron
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRecurrenceNext(t *testing.T) {
+	tests := []struct {
+		spec, time string
+		expected   []string
+	}{
+		// Examples from RFC 5545
+		{"DTSTART;TZID=America/New_York:19970902T090000 RRULE:FREQ=DAILY;COUNT=3", "1997-09-01T00:00:00-0400",
+			[]string{"1997-09-02T09:00:00-0400", "1997-09-03T09:00:00-0400", "1997-09-04T09:00:00-0400"}},
+		{"DTSTART;TZID=America/New_York:19970905T090000 RRULE:FREQ=MONTHLY;COUNT=10;BYDAY=1FR", "1997-12-31T00:00:00-0500",
+			[]string{"1998-01-02T09:00:00-0500", "1998-02-06T09:00:00-0500", "1998-03-06T09:00:00-0500",
+				"1998-04-03T09:00:00-0500", "1998-05-01T09:00:00-0400", "1998-06-05T09:00:00-0400"}},
+		{"DTSTART;TZID=America/New_York:19970902T090000 RRULE:FREQ=MONTHLY;BYDAY=FR;BYMONTHDAY=13", "1997-09-01T00:00:00-0400",
+			[]string{"1998-02-13T09:00:00-0500", "1998-03-13T09:00:00-0500", "1998-11-13T09:00:00-0500", "1999-08-13T09:00:00-0400"}},
+		{"DTSTART;TZID=America/New_York:19970512T090000 RRULE:FREQ=YEARLY;BYWEEKNO=20;BYDAY=MO", "1997-05-01T00:00:00-0400",
+			[]string{"1997-05-12T09:00:00-0400", "1998-05-11T09:00:00-0400", "1999-05-17T09:00:00-0400"}},
+		{"DTSTART;TZID=America/New_York:19970904T090000 RRULE:FREQ=MONTHLY;COUNT=3;BYDAY=TU,WE,TH;BYSETPOS=3", "1997-09-01T00:00:00-0400",
+			[]string{"1997-09-04T09:00:00-0400", "1997-10-07T09:00:00-0400", "1997-11-06T09:00:00-0500"}},
+		{"DTSTART;TZID=America/New_York:19970929T090000 RRULE:FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-2", "1997-09-01T00:00:00-0400",
+			[]string{"1997-09-29T09:00:00-0400", "1997-10-30T09:00:00-0500", "1997-11-27T09:00:00-0500"}},
+		{"DTSTART;TZID=America/New_York:19970902T090000 RRULE:FREQ=MINUTELY;INTERVAL=20;BYHOUR=9,10,11,12,13,14,15,16", "1997-09-02T16:00:00-0400",
+			[]string{"1997-09-02T16:20:00-0400", "1997-09-02T16:40:00-0400", "1997-09-03T09:00:00-0400"}},
+		{"DTSTART;TZID=America/New_York:19970101T090000 RRULE:FREQ=YEARLY;BYYEARDAY=1,100,200;COUNT=4", "1997-01-01T00:00:00-0500",
+			[]string{"1997-01-01T09:00:00-0500", "1997-04-10T09:00:00-0400", "1997-07-19T09:00:00-0400", "1998-01-01T09:00:00-0500"}},
+		{"DTSTART;TZID=America/New_York:19970805T090000 RRULE:FREQ=WEEKLY;INTERVAL=2;COUNT=4;BYDAY=TU,SU;WKST=SU", "1997-08-01T00:00:00-0400",
+			[]string{"1997-08-05T09:00:00-0400", "1997-08-17T09:00:00-0400", "1997-08-19T09:00:00-0400", "1997-08-31T09:00:00-0400"}},
+		{"DTSTART;TZID=America/New_York:19970805T090000 RRULE:FREQ=WEEKLY;INTERVAL=2;COUNT=4;BYDAY=TU,SU;WKST=MO", "1997-08-01T00:00:00-0400",
+			[]string{"1997-08-05T09:00:00-0400", "1997-08-10T09:00:00-0400", "1997-08-19T09:00:00-0400", "1997-08-24T09:00:00-0400"}},
+
+		// The last Monday or Tuesday of the month
+		{"DTSTART;TZID=America/New_York:20260101T090000 RRULE:FREQ=MONTHLY;BYDAY=MO,TU;BYSETPOS=-1;COUNT=10", "2026-01-01T00:00:00-0500",
+			[]string{"2026-01-27T09:00:00-0500", "2026-02-24T09:00:00-0500", "2026-03-31T09:00:00-0400"}},
+
+		// Defaults from DTSTART
+		{"DTSTART:20260131T083000Z RRULE:FREQ=MONTHLY", "2026-02-01T00:00:00+0000",
+			[]string{"2026-03-31T08:30:00+0000", "2026-05-31T08:30:00+0000"}},
+		{"DTSTART:20240229T120000Z RRULE:FREQ=YEARLY", "2024-03-01T00:00:00+0000",
+			[]string{"2028-02-29T12:00:00+0000"}},
+		{"DTSTART:20260101T000000Z RRULE:FREQ=WEEKLY;BYHOUR=9,17", "2026-01-01T12:00:00+0000",
+			[]string{"2026-01-01T17:00:00+0000", "2026-01-08T09:00:00+0000"}},
+
+		// Without DTSTART, in the location of the TZ= prefix
+		{"TZ=Asia/Shanghai RRULE:FREQ=DAILY;BYHOUR=9;BYMINUTE=30", "2026-10-16T12:00:00+0800",
+			[]string{"2026-10-17T09:30:00+0800", "2026-10-18T09:30:00+0800"}},
+		{"TZ=UTC RRULE:FREQ=HOURLY;INTERVAL=6", "2026-10-16T12:00:00+0000",
+			[]string{"2026-10-16T18:00:00+0000", "2026-10-17T00:00:00+0000"}},
+
+		// Until, inclusive
+		{"DTSTART:20260101T090000Z RRULE:FREQ=DAILY;UNTIL=20260103T090000Z", "2026-01-01T00:00:00+0000",
+			[]string{"2026-01-01T09:00:00+0000", "2026-01-02T09:00:00+0000", "2026-01-03T09:00:00+0000"}},
+		{"DTSTART;TZID=Asia/Tokyo:20260101T090000 RRULE:FREQ=DAILY;UNTIL=20260102", "2026-01-01T00:00:00+0900",
+			[]string{"2026-01-01T09:00:00+0900"}},
+
+		// Exclusions and extra dates
+		{"DTSTART:20260101T090000Z RRULE:FREQ=DAILY;COUNT=5 EXDATE:20260103T090000Z,20260104T090000Z RDATE:20260110T120000Z", "2026-01-01T00:00:00+0000",
+			[]string{"2026-01-01T09:00:00+0000", "2026-01-02T09:00:00+0000", "2026-01-05T09:00:00+0000", "2026-01-10T12:00:00+0000"}},
+		{"DTSTART:20261016T090000Z RRULE:FREQ=DAILY EXRULE:FREQ=WEEKLY;BYDAY=SA,SU", "2026-10-16T12:00:00+0000",
+			[]string{"2026-10-19T09:00:00+0000", "2026-10-20T09:00:00+0000"}},
+		{"RDATE;TZID=Europe/Paris:20261224T180000,20261231T180000", "2026-10-16T12:00:00+0000",
+			[]string{"2026-12-24T17:00:00+0000", "2026-12-31T17:00:00+0000"}},
+
+		// Daylight saving time: skipped wall clocks are read with the offset
+		// before the change, repeated ones are the first of them.
+		{"DTSTART;TZID=America/New_York:20260307T023000 RRULE:FREQ=DAILY;COUNT=3", "2026-03-01T00:00:00-0500",
+			[]string{"2026-03-07T02:30:00-0500", "2026-03-08T03:30:00-0400", "2026-03-09T02:30:00-0400"}},
+		{"DTSTART;TZID=America/New_York:20261031T013000 RRULE:FREQ=DAILY;COUNT=3", "2026-10-30T00:00:00-0400",
+			[]string{"2026-10-31T01:30:00-0400", "2026-11-01T01:30:00-0400", "2026-11-02T01:30:00-0500"}},
+		{"DTSTART;TZID=America/New_York:20260308T000000 RRULE:FREQ=MINUTELY;INTERVAL=30;BYHOUR=1,2,3", "2026-03-08T01:00:00-0500",
+			[]string{"2026-03-08T01:30:00-0500", "2026-03-08T03:00:00-0400", "2026-03-08T03:30:00-0400", "2026-03-09T01:00:00-0400"}},
+
+		// Secondly, counted from DTSTART years before
+		{"DTSTART:20260101T000000Z RRULE:FREQ=SECONDLY;INTERVAL=60;COUNT=200000", "2026-05-19T21:18:07+0000",
+			[]string{"2026-05-19T21:19:00+0000"}},
+		{"DTSTART:20260101T000000Z RRULE:FREQ=SECONDLY;INTERVAL=60;COUNT=200000", "2026-05-19T21:19:00+0000", nil},
+		{"DTSTART:20260101T000000Z RRULE:FREQ=SECONDLY;INTERVAL=15;COUNT=3", "2025-12-31T00:00:00+0000",
+			[]string{"2026-01-01T00:00:00+0000", "2026-01-01T00:00:15+0000", "2026-01-01T00:00:30+0000"}},
+
+		// No more occurrences
+		{"DTSTART:20260101T090000Z RRULE:FREQ=DAILY;COUNT=2", "2026-01-02T09:00:00+0000", nil},
+		{"DTSTART:20260101T090000Z RRULE:FREQ=DAILY;COUNT=2 EXDATE:20260102T090000Z", "2026-01-01T09:00:00+0000", nil},
+		{"DTSTART:20260101T090000Z RRULE:FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30", "2026-01-01T00:00:00+0000", nil},
+		{"DTSTART:20260101T090000Z RRULE:FREQ=SECONDLY;BYMONTH=2;BYMONTHDAY=30", "2026-01-01T00:00:00+0000", nil},
+	}
+
+	for _, c := range tests {
+		sched, err := ParseStandard(c.spec)
+		if err != nil {
+			t.Errorf("%s: %v", c.spec, err)
+			continue
+		}
+		n := len(c.expected)
+		if n == 0 {
+			n = 1
+		}
+		assertTimes(t, c.spec, NextN(sched, getTime(c.time), n), c.expected)
+	}
+}
+
+func BenchmarkRecurrenceNext(b *testing.B) {
+	benchmarks := []struct {
+		name, spec string
+	}{
+		{"Secondly", "DTSTART:20260101T000000Z RRULE:FREQ=SECONDLY;INTERVAL=5"},
+		{"Count", "DTSTART:20200101T090000Z RRULE:FREQ=HOURLY;COUNT=100000"},
+		{"NewYork", "DTSTART;TZID=America/New_York:20260101T090000 RRULE:FREQ=DAILY"},
+	}
+	start := time.Date(2026, time.July, 9, 23, 35, 0, 0, time.UTC)
+	for _, bm := range benchmarks {
+		sched, err := ParseStandard(bm.spec)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(bm.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				sched.Next(start)
+			}
+		})
+	}
+}
+
+func TestRecurrenceUntil(t *testing.T) {
+	// Every other week on Monday, Wednesday and Friday until December 24, 1997.
+	sched, err := ParseStandard("DTSTART;TZID=America/New_York:19970901T090000 RRULE:FREQ=WEEKLY;INTERVAL=2;UNTIL=19971224T000000Z;WKST=SU;BYDAY=MO,WE,FR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	times := Between(sched, getTime("1997-09-01T00:00:00-0400"), getTime("1998-01-01T00:00:00-0500"))
+	if len(times) != 25 {
+		t.Fatalf("expected 25 occurrences, got %d: %v", len(times), times)
+	}
+	if last := getTime("1997-12-22T09:00:00-0500"); !times[24].Equal(last) {
+		t.Errorf("expected the last occurrence on %v, got %v", last, times[24])
+	}
+}
+
+func TestRecurrenceString(t *testing.T) {
+	tests := []struct {
+		spec, expected string
+	}{
+		{"DTSTART;TZID=America/New_York:20260101T090000 RRULE:FREQ=MONTHLY;BYDAY=MO,TU;BYSETPOS=-1;COUNT=10",
+			"DTSTART;TZID=America/New_York:20260101T090000 RRULE:FREQ=MONTHLY;COUNT=10;BYDAY=MO,TU;BYSETPOS=-1"},
+		{"dtstart:20260101T090000Z rrule:freq=monthly;interval=2;until=20261231T000000Z;byday=mo,-1fr;wkst=su exdate:20260105T090000Z",
+			"DTSTART:20260101T090000Z RRULE:FREQ=MONTHLY;INTERVAL=2;UNTIL=20261231T000000Z;BYDAY=MO,-1FR;WKST=SU EXDATE:20260105T090000Z"},
+		{"TZ=Asia/Shanghai RRULE:FREQ=DAILY;BYHOUR=9;BYMINUTE=30 EXRULE:FREQ=YEARLY;BYMONTH=1;BYMONTHDAY=1",
+			"DTSTART;TZID=Asia/Shanghai:19700101T000000 RRULE:FREQ=DAILY;BYHOUR=9;BYMINUTE=30 EXRULE:FREQ=YEARLY;BYMONTH=1;BYMONTHDAY=1"},
+		{"RDATE;TZID=Europe/Paris:20261224T180000 DTSTART:20260101T000000Z",
+			"DTSTART:20260101T000000Z RDATE:20261224T170000Z"},
+	}
+
+	for _, c := range tests {
+		sched, err := ParseStandard(c.spec)
+		if err != nil {
+			t.Errorf("%s: %v", c.spec, err)
+			continue
+		}
+		actual := sched.(*RecurrenceSchedule).String()
+		if actual != c.expected {
+			t.Errorf("%s: expected %q, got %q", c.spec, c.expected, actual)
+		}
+		again, err := ParseStandard(actual)
+		if err != nil {
+			t.Errorf("%s: %v", actual, err)
+			continue
+		}
+		if again.(*RecurrenceSchedule).String() != actual {
+			t.Errorf("%s: expected the same spec after parsing, got %q", actual, again)
+		}
+	}
+}
+
+func TestRecurrenceJSON(t *testing.T) {
+	spec := "DTSTART:20260101T090000Z RRULE:FREQ=DAILY;COUNT=5"
+	sched, err := ParseStandard(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(sched)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded RecurrenceSchedule
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.String() != spec {
+		t.Errorf("expected %q, got %q", spec, decoded.String())
+	}
+}
+
+func TestRecurrenceParseErrors(t *testing.T) {
+	tests := []struct {
+		spec           string
+		offset, length int
+		reason         ParseErrorReason
+	}{
+		{"RRULE:FREQ=DAILY;BYHOUR=24", 17, 9, ReasonAboveMaximum},
+		{"RRULE:FREQ=MONTHLY;BYMONTHDAY=0", 19, 12, ReasonBelowMinimum},
+		{"RRULE:FREQ=DAILY;BYMINUTE=x", 17, 10, ReasonNumber},
+		{"RRULE:FREQ=FORTNIGHTLY", 6, 16, ReasonSyntax},
+		{"RRULE:BYHOUR=9", 6, 8, ReasonSyntax},
+		{"RRULE:FREQ=DAILY;COUNT=3;UNTIL=20260101T000000Z", 6, 41, ReasonSyntax},
+		{"RRULE:FREQ=DAILY;BYDAY=1MO", 23, 3, ReasonSyntax},
+		{"RRULE:FREQ=DAILY;BYDAY=XX", 17, 8, ReasonSyntax},
+		{"RRULE:FREQ=DAILY;COLOR=RED", 17, 9, ReasonUnexpected},
+		{"DTSTART;TZID=Mars/Olympus:20260101T090000 RRULE:FREQ=DAILY", 13, 12, ReasonLocation},
+		{"DTSTART:2026-01-01 RRULE:FREQ=DAILY", 8, 10, ReasonSyntax},
+		{"TZ=UTC RRULE:FREQ=DAILY VALARM:X", 24, 8, ReasonUnexpected},
+		{"DTSTART:20260101T090000Z", 0, 24, ReasonSyntax},
+	}
+
+	for _, c := range tests {
+		_, err := ParseStandard(c.spec)
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("%q: expected a ParseError, got %v", c.spec, err)
+			continue
+		}
+		if perr.Field != FieldRecurrence || perr.Offset != c.offset || perr.Length != c.length || perr.Reason != c.reason {
+			t.Errorf("%q: expected %d+%d for %s, got %s at %d+%d for %s: %v", c.spec,
+				c.offset, c.length, c.reason, perr.Field, perr.Offset, perr.Length, perr.Reason, perr)
+		}
+	}
+}
+
+func TestRecurrenceInCron(t *testing.T) {
+	cron := New(WithParser(NewParser(Minute | Hour | Dom | Month | Dow)))
+	id, err := cron.AddFunc("standup", "TZ=UTC RRULE:FREQ=WEEKLY;BYDAY=MO,WE,FR;BYHOUR=9;BYMINUTE=30", func(context.Context) error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cron.Entry(id).Schedule.(*RecurrenceSchedule); !ok {
+		t.Errorf("expected a RecurrenceSchedule, got %T", cron.Entry(id).Schedule)
+	}
+}
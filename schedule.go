@@ -0,0 +1,36 @@
+package cron
+
+import "time"
+
+// NextN returns the next n activation times of the schedule after the given
+// time, in order.  It returns fewer if the schedule stops activating, i.e. its
+// Next returns the zero time.
+func NextN(s Schedule, t time.Time, n int) []time.Time {
+	var times []time.Time
+	for len(times) < n {
+		next := s.Next(t)
+		if next.IsZero() || !next.After(t) {
+			break
+		}
+		times = append(times, next)
+		t = next
+	}
+	return times
+}
+
+// Between returns the activation times of the schedule after from, up to and
+// including to, in order, e.g. to backfill the runs missed while a scheduler
+// was down.  Schedules which run often may have very many activations in a
+// long range; see NextN to limit them.
+func Between(s Schedule, from, to time.Time) []time.Time {
+	var times []time.Time
+	for t := from; ; {
+		next := s.Next(t)
+		if next.IsZero() || !next.After(t) || next.After(to) {
+			break
+		}
+		times = append(times, next)
+		t = next
+	}
+	return times
+}
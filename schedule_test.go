@@ -0,0 +1,84 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextN(t *testing.T) {
+	parser := NewParser(Second | Minute | Hour | Dom | Month | Dow | YearOptional | Descriptor)
+	tests := []struct {
+		time, spec string
+		n          int
+		expected   []string
+	}{
+		{"Mon Jul 9 14:45 2012", "0 0/15 * * * *", 3,
+			[]string{"Mon Jul 9 15:00 2012", "Mon Jul 9 15:15 2012", "Mon Jul 9 15:30 2012"}},
+		{"Mon Jul 9 14:45 2012", "0 0/15 * * * *", 0, nil},
+
+		// Daylight savings time 2am EDT (-4) -> 1am EST (-5)
+		{"2012-11-04T00:30:00-0400", "TZ=America/New_York 0 0 * * * ?", 3,
+			[]string{"2012-11-04T01:00:00-0400", "2012-11-04T01:00:00-0500", "2012-11-04T02:00:00-0500"}},
+
+		// Fewer activations than asked for
+		{"Mon Jul 9 14:45 2012", "0 0 0 1 1 * 2013-2014", 3,
+			[]string{"Tue Jan 1 00:00 2013", "Wed Jan 1 00:00 2014"}},
+		{"Mon Jul 9 14:45 2012", "0 0 0 30 Feb ?", 3, nil},
+	}
+
+	for _, c := range tests {
+		sched, err := parser.Parse(c.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertTimes(t, c.time+" "+c.spec, NextN(sched, getTime(c.time), c.n), c.expected)
+	}
+}
+
+func TestBetween(t *testing.T) {
+	parser := NewParser(Second | Minute | Hour | Dom | Month | Dow | YearOptional | Descriptor)
+	tests := []struct {
+		from, to, spec string
+		expected       []string
+	}{
+		// After from, up to and including to
+		{"Mon Jul 9 14:45 2012", "Mon Jul 9 15:30 2012", "0 0/15 * * * *",
+			[]string{"Mon Jul 9 15:00 2012", "Mon Jul 9 15:15 2012", "Mon Jul 9 15:30 2012"}},
+		{"Mon Jul 9 14:45 2012", "Mon Jul 9 14:59 2012", "0 0/15 * * * *", nil},
+		{"Mon Jul 9 14:45 2012", "Mon Jul 9 14:00 2012", "0 0/15 * * * *", nil},
+
+		// Daylight savings time 2am EST (-5) -> 3am EDT (-4)
+		{"2012-03-10T23:00:00-0500", "2012-03-11T04:00:00-0400", "TZ=America/New_York 0 30 * * * ?",
+			[]string{"2012-03-10T23:30:00-0500", "2012-03-11T00:30:00-0500", "2012-03-11T01:30:00-0500", "2012-03-11T03:30:00-0400"}},
+
+		// Unsatisfiable
+		{"Mon Jul 9 14:45 2012", "Mon Jul 9 14:45 2022", "0 0 0 30 Feb ?", nil},
+	}
+
+	for _, c := range tests {
+		sched, err := parser.Parse(c.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertTimes(t, c.from+" "+c.spec, Between(sched, getTime(c.from), getTime(c.to)), c.expected)
+	}
+}
+
+func TestBetweenConstantDelay(t *testing.T) {
+	actual := Between(Every(40*time.Minute), getTime("Mon Jul 9 14:00 2012"), getTime("Mon Jul 9 16:00 2012"))
+	assertTimes(t, "@every 40m", actual, []string{"Mon Jul 9 14:40 2012", "Mon Jul 9 15:20 2012", "Mon Jul 9 16:00 2012"})
+}
+
+func assertTimes(t *testing.T, name string, actual []time.Time, expected []string) {
+	t.Helper()
+	if len(actual) != len(expected) {
+		t.Errorf("%s: expected %v, got %v", name, expected, actual)
+		return
+	}
+	for i := range expected {
+		if !actual[i].Equal(getTime(expected[i])) {
+			t.Errorf("%s: expected %v, got %v", name, expected, actual)
+			return
+		}
+	}
+}
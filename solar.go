@@ -0,0 +1,319 @@
+package cron
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SolarEvent is a daily event of the sun, as seen from a point on Earth.
+type SolarEvent int
+
+const (
+	Sunrise SolarEvent = iota // the top of the sun rises above the horizon
+	Sunset                    // the top of the sun sets below the horizon
+	Dawn                      // civil dawn: the sun rises to 6° below the horizon
+	Dusk                      // civil dusk: the sun sets to 6° below the horizon
+)
+
+// solarEventNames are the names of the events, as their descriptors have them.
+var solarEventNames = [...]string{
+	Sunrise: "sunrise",
+	Sunset:  "sunset",
+	Dawn:    "dawn",
+	Dusk:    "dusk",
+}
+
+// String returns the name of the event, e.g. "sunset".
+func (e SolarEvent) String() string {
+	return solarEventNames[e]
+}
+
+// zenith returns the angle of the sun from the zenith at the event, in
+// degrees, allowing for atmospheric refraction and the size of the sun.
+func (e SolarEvent) zenith() float64 {
+	if e == Dawn || e == Dusk {
+		return 96
+	}
+	return 90.833
+}
+
+// Coordinates give a point on Earth, in degrees north of the equator and east
+// of the prime meridian.
+type Coordinates struct {
+	Latitude, Longitude float64
+}
+
+// String returns the coordinates as a spec has them, e.g. "52.52,13.405".
+func (c Coordinates) String() string {
+	return strconv.FormatFloat(c.Latitude, 'f', -1, 64) + "," + strconv.FormatFloat(c.Longitude, 'f', -1, 64)
+}
+
+// SolarSchedule activates daily at an event of the sun, such as sunset, as
+// seen from its coordinates.  The times are computed with NOAA's solar
+// equations, to within a minute or so.  It backs the "@sunrise", "@sunset",
+// "@dawn" and "@dusk" descriptors.
+type SolarSchedule struct {
+	Event SolarEvent
+
+	// Offset moves each activation from the event, e.g. -30 minutes for half
+	// an hour before sunset.
+	Offset time.Duration
+
+	// Coordinates of the observer.  Specs without them leave them nil, for
+	// Cron to set from WithCoordinates; the schedule never activates without
+	// them.
+	Coordinates *Coordinates
+
+	// Location in which days begin and end.  As with SpecSchedule,
+	// time.Local means the location of the time given to Next.
+	Location *time.Location
+}
+
+// Solar returns a schedule activating daily at the event, as seen from the
+// given latitude and longitude, in degrees north and east.
+func Solar(event SolarEvent, latitude, longitude float64) SolarSchedule {
+	return SolarSchedule{
+		Event:       event,
+		Coordinates: &Coordinates{Latitude: latitude, Longitude: longitude},
+		Location:    time.Local,
+	}
+}
+
+// maxSolarDays is the number of days Next and Prev search for an activation:
+// beyond the polar circles, the sun may not rise or set for months.
+const maxSolarDays = 366
+
+// Next returns the first activation after the given time, or the zero time if
+// there is none within a year.
+func (s SolarSchedule) Next(t time.Time) time.Time {
+	if s.Coordinates == nil {
+		return time.Time{}
+	}
+	year, month, day := t.In(s.location(t)).Date()
+	// Activations are in order of their days, but start a day early, as the
+	// offset or the longitude may move them to another day.
+	for i := -1; i <= maxSolarDays; i++ {
+		if next, ok := s.activation(year, month, day+i); ok && next.After(t) {
+			return next.In(t.Location())
+		}
+	}
+	return time.Time{}
+}
+
+// Prev returns the last activation before the given time, or the zero time if
+// there is none within a year.
+func (s SolarSchedule) Prev(t time.Time) time.Time {
+	if s.Coordinates == nil {
+		return time.Time{}
+	}
+	year, month, day := t.In(s.location(t)).Date()
+	for i := 1; i >= -maxSolarDays; i-- {
+		if prev, ok := s.activation(year, month, day+i); ok && prev.Before(t) {
+			return prev.In(t.Location())
+		}
+	}
+	return time.Time{}
+}
+
+func (s SolarSchedule) location(t time.Time) *time.Location {
+	if s.Location == nil || s.Location == time.Local {
+		return t.Location()
+	}
+	return s.Location
+}
+
+// activation returns the activation on the given day, which is normalized as
+// by time.Date, or false if the sun does not reach the event that day.
+func (s SolarSchedule) activation(year int, month time.Month, day int) (time.Time, bool) {
+	// The event is computed for the day in UTC, about the solar noon at the
+	// coordinates, which is within a day of noon in the location.
+	midnight := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	minutes := 720 - 4*s.Coordinates.Longitude
+
+	// Compute the event with the sun's position at solar noon, and then again
+	// with its position at the event.
+	for i := 0; i < 2; i++ {
+		var ok bool
+		at := midnight.Add(time.Duration(minutes * float64(time.Minute)))
+		if minutes, ok = solarMinutes(s.Event, *s.Coordinates, at); !ok {
+			return time.Time{}, false
+		}
+	}
+	at := midnight.Add(time.Duration(minutes * float64(time.Minute))).Round(time.Second)
+	return at.Add(s.Offset), true
+}
+
+// solarMinutes returns the time of the event on the day of t, in minutes from
+// midnight UTC, with the position of the sun at t, or false if the sun does
+// not reach the event that day.  It follows NOAA's solar calculator, after Jean
+// Meeus' Astronomical Algorithms.
+func solarMinutes(event SolarEvent, c Coordinates, t time.Time) (float64, bool) {
+	var (
+		// Julian centuries since J2000.0.
+		jd = float64(t.Unix())/86400 + 2440587.5
+		T  = (jd - 2451545) / 36525
+
+		meanLong  = math.Mod(280.46646+T*(36000.76983+T*0.0003032), 360)
+		meanAnom  = 357.52911 + T*(35999.05029-0.0001537*T)
+		eccent    = 0.016708634 - T*(0.000042037+0.0000001267*T)
+		center    = sinDeg(meanAnom)*(1.914602-T*(0.004817+0.000014*T)) + sinDeg(2*meanAnom)*(0.019993-0.000101*T) + sinDeg(3*meanAnom)*0.000289
+		omega     = 125.04 - 1934.136*T
+		appLong   = meanLong + center - 0.00569 - 0.00478*sinDeg(omega)
+		meanObliq = 23 + (26+(21.448-T*(46.815+T*(0.00059-T*0.001813)))/60)/60
+		obliq     = meanObliq + 0.00256*cosDeg(omega)
+		decl      = degrees(math.Asin(sinDeg(obliq) * sinDeg(appLong)))
+
+		// The equation of time, in minutes.
+		y       = math.Pow(math.Tan(radians(obliq/2)), 2)
+		eqTime  = 4 * degrees(y*sinDeg(2*meanLong)-2*eccent*sinDeg(meanAnom)+4*eccent*y*sinDeg(meanAnom)*cosDeg(2*meanLong)-0.5*y*y*sinDeg(4*meanLong)-1.25*eccent*eccent*sinDeg(2*meanAnom))
+		cosHour = cosDeg(event.zenith())/(cosDeg(c.Latitude)*cosDeg(decl)) - math.Tan(radians(c.Latitude))*math.Tan(radians(decl))
+	)
+	if cosHour < -1 || cosHour > 1 {
+		return 0, false
+	}
+	hourAngle := degrees(math.Acos(cosHour))
+	noon := 720 - 4*c.Longitude - eqTime
+	if event == Sunrise || event == Dawn {
+		return noon - 4*hourAngle, true
+	}
+	return noon + 4*hourAngle, true
+}
+
+func radians(deg float64) float64 { return deg * math.Pi / 180 }
+func degrees(rad float64) float64 { return rad * 180 / math.Pi }
+func sinDeg(deg float64) float64  { return math.Sin(radians(deg)) }
+func cosDeg(deg float64) float64  { return math.Cos(radians(deg)) }
+
+// String returns the descriptor for the schedule, e.g.
+// "@sunset -30m 52.52,13.405".
+func (s SolarSchedule) String() string {
+	spec := "@" + s.Event.String()
+	switch {
+	case s.Offset > 0:
+		spec += " +" + shortDuration(s.Offset)
+	case s.Offset < 0:
+		spec += " -" + shortDuration(-s.Offset)
+	}
+	if s.Coordinates != nil {
+		spec += " " + s.Coordinates.String()
+	}
+	if s.Location != nil && s.Location != time.Local {
+		spec = "TZ=" + s.Location.String() + " " + spec
+	}
+	return spec
+}
+
+// MarshalText implements encoding.TextMarshaler, returning the descriptor.
+func (s SolarSchedule) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Describe describes the schedule, e.g. "30 minutes before sunset".
+func (s SolarSchedule) Describe(lang Language) string {
+	if lang == Chinese {
+		event := [...]string{Sunrise: "日出", Sunset: "日落", Dawn: "黎明", Dusk: "黄昏"}[s.Event]
+		switch {
+		case s.Offset > 0:
+			event += "后" + lang.duration(s.Offset)
+		case s.Offset < 0:
+			event += "前" + lang.duration(-s.Offset)
+		default:
+			event += "时"
+		}
+		return "每天" + event + lang.location(s.Location)
+	}
+	event := "at " + s.Event.String()
+	switch {
+	case s.Offset > 0:
+		event = lang.duration(s.Offset) + " after " + s.Event.String()
+	case s.Offset < 0:
+		event = lang.duration(-s.Offset) + " before " + s.Event.String()
+	}
+	return lang.sentence(event, lang.location(s.Location))
+}
+
+// parseSolar returns the schedule for a solar descriptor given the fields
+// following it:
+//   [ ("+" | "-") duration ] [ latitude "," longitude ]
+func parseSolar(event SolarEvent, fields []string, descriptor string, loc *time.Location) (Schedule, error) {
+	s := SolarSchedule{Event: event, Location: loc}
+	if len(fields) > 0 && (strings.HasPrefix(fields[0], "+") || strings.HasPrefix(fields[0], "-")) && !strings.Contains(fields[0], ",") {
+		offset, err := time.ParseDuration(fields[0])
+		if err != nil {
+			return nil, parseErrorf(ReasonDuration, fields[0], "failed to parse offset %s: %s", descriptor, err)
+		}
+		s.Offset, fields = offset, fields[1:]
+	}
+	if len(fields) > 0 && !strings.Contains(fields[0], ",") {
+		if _, err := time.ParseDuration(fields[0]); err == nil {
+			return nil, parseErrorf(ReasonSyntax, fields[0], "offset must start with '+' or '-': %s", descriptor)
+		}
+	}
+	if len(fields) > 0 {
+		c, err := parseCoordinates(fields[0], descriptor)
+		if err != nil {
+			return nil, err
+		}
+		s.Coordinates, fields = &c, fields[1:]
+	}
+	if len(fields) > 0 {
+		return nil, parseErrorf(ReasonUnexpected, fields[0], "unexpected %q after %s: %s", fields[0], event, descriptor)
+	}
+	return s, nil
+}
+
+// parseCoordinates parses coordinates written as "latitude,longitude".
+func parseCoordinates(value, descriptor string) (Coordinates, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 2 {
+		return Coordinates{}, parseErrorf(ReasonCoordinates, value, "coordinates must be latitude,longitude: %s", descriptor)
+	}
+	latitude, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil || latitude < -90 || latitude > 90 {
+		return Coordinates{}, parseErrorf(ReasonCoordinates, parts[0], "latitude (%s) must be a number from -90 to 90: %s", parts[0], descriptor)
+	}
+	longitude, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil || longitude < -180 || longitude > 180 {
+		return Coordinates{}, parseErrorf(ReasonCoordinates, parts[1], "longitude (%s) must be a number from -180 to 180: %s", parts[1], descriptor)
+	}
+	return Coordinates{Latitude: latitude, Longitude: longitude}, nil
+}
+
+// locateSolar returns the schedule with the given coordinates set on the solar
+// schedules making it up which have none, including those within composite,
+// jittered and calendar schedules.  It returns false if one has none and no
+// coordinates are given.
+func locateSolar(s Schedule, c *Coordinates) (Schedule, bool) {
+	ok := true
+	locate := func(s Schedule) Schedule {
+		s, located := locateSolar(s, c)
+		ok = ok && located
+		return s
+	}
+	switch s := s.(type) {
+	case SolarSchedule:
+		if s.Coordinates == nil {
+			s.Coordinates = c
+		}
+		return s, s.Coordinates != nil
+	case UnionSchedule:
+		schedules := make([]Schedule, len(s.Schedules))
+		for i, schedule := range s.Schedules {
+			schedules[i] = locate(schedule)
+		}
+		return UnionSchedule{Schedules: schedules}, ok
+	case IntersectSchedule:
+		return IntersectSchedule{A: locate(s.A), B: locate(s.B)}, ok
+	case ExceptSchedule:
+		return ExceptSchedule{Schedule: locate(s.Schedule), Blackout: locate(s.Blackout)}, ok
+	case JitterSchedule:
+		s.Schedule = locate(s.Schedule)
+		return s, ok
+	case CalendarSchedule:
+		s.Schedule = locate(s.Schedule)
+		return s, ok
+	}
+	return s, true
+}
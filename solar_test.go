@@ -0,0 +1,118 @@
+package cron
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSolarNext(t *testing.T) {
+	berlin := Solar(Sunrise, 52.52, 13.405)
+	london := Solar(Sunrise, 51.5074, -0.1278)
+	tromso := Solar(Sunrise, 69.6492, 18.9553)
+	tests := []struct {
+		schedule SolarSchedule
+		event    SolarEvent
+		offset   time.Duration
+		time     string
+		expected string
+	}{
+		// Published times, to the minute.
+		{berlin, Sunrise, 0, "TZ=Europe/Berlin 2026-06-21T00:00:00+0200", "2026-06-21T04:43:00+0200"},
+		{berlin, Sunset, 0, "TZ=Europe/Berlin 2026-06-21T00:00:00+0200", "2026-06-21T21:33:00+0200"},
+		{berlin, Dawn, 0, "TZ=Europe/Berlin 2026-06-21T00:00:00+0200", "2026-06-21T03:53:00+0200"},
+		{berlin, Dusk, 0, "TZ=Europe/Berlin 2026-06-21T00:00:00+0200", "2026-06-21T22:24:00+0200"},
+		{london, Sunrise, 0, "TZ=Europe/London 2026-12-21T00:00:00+0000", "2026-12-21T08:04:00+0000"},
+		{london, Sunset, 0, "TZ=Europe/London 2026-12-21T00:00:00+0000", "2026-12-21T15:53:00+0000"},
+
+		// Offsets, and the next day once the event has passed.
+		{london, Sunset, -30 * time.Minute, "TZ=Europe/London 2026-12-21T00:00:00+0000", "2026-12-21T15:23:00+0000"},
+		{london, Sunrise, time.Hour, "TZ=Europe/London 2026-12-21T12:00:00+0000", "2026-12-22T09:04:00+0000"},
+
+		// The sun does not rise in the polar night.
+		{tromso, Sunrise, 0, "TZ=Europe/Oslo 2026-12-01T00:00:00+0100", "2027-01-15T11:35:00+0100"},
+	}
+	for _, c := range tests {
+		s := c.schedule
+		s.Event, s.Offset = c.event, c.offset
+		actual := s.Next(getTime(c.time))
+		if d := actual.Sub(getTime(c.expected)); d < -time.Minute || d > time.Minute {
+			t.Errorf("%s from %s: expected %s, got %v", s, c.time, c.expected, actual)
+		}
+		if prev := s.Prev(actual.Add(time.Second)); !prev.Equal(actual) {
+			t.Errorf("%s: expected prev %v, got %v", s, actual, prev)
+		}
+	}
+}
+
+func TestParseSolar(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected string
+	}{
+		{"@sunrise", "@sunrise"},
+		{"@sunset -30m", "@sunset -30m"},
+		{"@dusk +15m 52.52,13.405", "@dusk +15m 52.52,13.405"},
+		{"@dawn -33.8688,151.2093", "@dawn -33.8688,151.2093"},
+		{"TZ=Europe/Berlin  @sunset -1h30m 52.52,13.405", "TZ=Europe/Berlin @sunset -1h30m 52.52,13.405"},
+	}
+	for _, c := range tests {
+		sched, err := ParseStandard(c.spec)
+		if err != nil {
+			t.Errorf("%s: %v", c.spec, err)
+			continue
+		}
+		if actual := sched.(SolarSchedule).String(); actual != c.expected {
+			t.Errorf("%s: expected %q, got %q", c.spec, c.expected, actual)
+		}
+		again, err := parseText(c.expected)
+		if err != nil || again.(SolarSchedule).String() != c.expected {
+			t.Errorf("%s: expected it to parse back, got %v, %v", c.expected, again, err)
+		}
+	}
+
+	errors := []struct {
+		spec, err string
+	}{
+		{"@sunset 30m", "offset must start with '+' or '-'"},
+		{"@sunset -30x", "failed to parse offset"},
+		{"@sunset 91,0", "latitude (91) must be a number from -90 to 90"},
+		{"@sunset 52.52,east", "longitude (east) must be a number from -180 to 180"},
+		{"@sunset 52.52", "coordinates must be latitude,longitude"},
+		{"@sunset 52.52,13.405 now", "unexpected \"now\" after sunset"},
+	}
+	for _, c := range errors {
+		if _, err := ParseStandard(c.spec); err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%s: expected %v, got %v", c.spec, c.err, err)
+		}
+	}
+}
+
+// Cron gives solar schedules its coordinates, and rejects them if it has none.
+func TestWithCoordinates(t *testing.T) {
+	noop := func(context.Context) error { return nil }
+	if _, err := New().AddFunc("lights", "@sunset", noop); err == nil {
+		t.Error("expected an error for missing coordinates")
+	} else if perr, ok := err.(*ParseError); !ok || perr.Reason != ReasonCoordinates {
+		t.Errorf("expected a coordinates ParseError, got %v", err)
+	}
+
+	cron := New(WithCoordinates(52.52, 13.405))
+	for spec, expected := range map[string]string{
+		"@sunset -30m":             "@sunset -30m 52.52,13.405",
+		"@sunrise 51.5074,-0.1278": "@sunrise 51.5074,-0.1278",
+		"@dusk ~10m":               "@dusk 52.52,13.405 ~10m",
+		"@dawn | 0 7 * * *":        "@dawn 52.52,13.405 | 0 7 * * *",
+		"@sunrise &^ 0 * * * sun":  "@sunrise 52.52,13.405 &^ 0 * * * sun",
+	} {
+		id, err := cron.AddFunc(spec, spec, noop)
+		if err != nil {
+			t.Errorf("%s: %v", spec, err)
+			continue
+		}
+		if actual := cron.Entry(id).Schedule.(interface{ String() string }).String(); actual != expected {
+			t.Errorf("%s: expected %q, got %q", spec, expected, actual)
+		}
+	}
+}
@@ -1,14 +1,40 @@
 package cron
 
-import "time"
+import (
+	"fmt"
+	"math/bits"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // SpecSchedule specifies a duty cycle (to the second granularity), based on a
 // traditional crontab specification. It is computed initially and stored as bit sets.
 type SpecSchedule struct {
 	Second, Minute, Hour, Dom, Month, Dow uint64
 
+	// DomRules and DowRules hold day-of-month and day-of-week expressions
+	// using the Quartz-style L, W and # modifiers, which cannot be stored as
+	// bit sets. A field matches a day if its bit set or any of its rules do.
+	DomRules, DowRules []DayRule
+
+	// Year is the sorted list of years in which the schedule is active, or
+	// nil if it is active every year.
+	Year []int
+
+	// Millisecond is the sorted list of milliseconds of each second at which
+	// the schedule is activated, or nil if it is only activated on the whole
+	// second.  It is set by the Millisecond parse option.
+	Millisecond []int
+
 	// Override location for this schedule.
 	Location *time.Location
+
+	// DST says how the schedule treats wall clock times skipped or repeated
+	// by daylight saving time changes in its location.  By default, skipped
+	// times are not activations, and repeated ones are activations twice.
+	DST DSTPolicy
 }
 
 // bounds provides a range of acceptable values (plus a map of name to value).
@@ -46,6 +72,8 @@ var (
 		"fri": 5,
 		"sat": 6,
 	}}
+	years        = bounds{1970, 2099, nil}
+	milliseconds = bounds{0, 999, nil}
 )
 
 const (
@@ -53,136 +81,726 @@ const (
 	starBit = 1 << 63
 )
 
+// cycleYears is how often the Gregorian calendar repeats, days of the week
+// included.  It is how far Next and Prev search for an activation of a
+// schedule which is not limited to some years: a schedule with none in a
+// whole cycle has none at all.
+const cycleYears = 400
+
 // Next returns the next time this schedule is activated, greater than the given
 // time.  If no time can be found to satisfy the schedule, return the zero time.
 func (s *SpecSchedule) Next(t time.Time) time.Time {
-	// General approach
-	//
-	// For Month, Day, Hour, Minute, Second:
-	// Check if the time value matches.  If yes, continue to the next field.
-	// If the field doesn't match the schedule, then increment the field until it matches.
-	// While incrementing the field, a wrap-around brings it back to the beginning
-	// of the field list (since it is necessary to re-verify previous field
-	// values)
+	if s.never() {
+		return time.Time{}
+	}
+	if s.Millisecond == nil {
+		return s.nextSecond(t)
+	}
 
+	// The rest of the current second, if it is an activation.
+	second := t.Add(-time.Duration(t.Nanosecond()))
+	for _, ms := range s.Millisecond {
+		if next := second.Add(time.Duration(ms) * time.Millisecond); next.After(t) {
+			if s.nextSecond(second.Add(-time.Nanosecond)).Equal(second) {
+				return next
+			}
+			break
+		}
+	}
+	next := s.nextSecond(t)
+	if next.IsZero() {
+		return next
+	}
+	return next.Add(time.Duration(s.Millisecond[0]) * time.Millisecond)
+}
+
+// nextMatch returns the next whole second after the second of the given time
+// whose wall clock matches the schedule.  Wall clock times skipped by a
+// daylight saving time change are not found, and those repeated by one are
+// found twice.
+func (s *SpecSchedule) nextMatch(t time.Time) time.Time {
 	// Convert the given time into the schedule's timezone, if one is specified.
 	// Save the original timezone so we can convert back after we find a time.
 	// Note that schedules without a time zone specified (time.Local) are treated
 	// as local to the time provided.
 	origLocation := t.Location()
-	loc := s.Location
-	if loc == time.Local {
-		loc = t.Location()
-	}
-	if s.Location != time.Local {
-		t = t.In(s.Location)
-	}
+	loc := s.location(t)
 
 	// Start at the earliest possible time (the upcoming second).
+	t = t.In(loc)
 	t = t.Add(1*time.Second - time.Duration(t.Nanosecond())*time.Nanosecond)
 
-	// This flag indicates whether a field has been incremented.
-	added := false
-
-	// If no time is found within five years, return zero.
-	yearLimit := t.Year() + 5
+	// Search the next two years first, and then up to the next matching wall
+	// clock, which only a daylight saving time change may skip.
+	last := s.lastYear(t)
+	limit := time.Date(t.Year()+2, time.January, 1, 0, 0, 0, 0, time.UTC)
+	if last < t.Year()+2 {
+		limit = time.Date(last+1, time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+	for {
+		// Search the wall clock times of each period with a constant UTC
+		// offset in turn, as prevMatch does.  Within a period, wall clock
+		// times and instants are in the same order, so the earliest matching
+		// wall clock is the answer.
+		for {
+			_, end := zoneBounds(t)
+			_, offset := t.Zone()
+			to := limit
+			if !end.IsZero() {
+				// The wall clock at the end of the period, before the change.
+				if wall := end.UTC().Add(time.Duration(offset) * time.Second); wall.Before(limit) {
+					to = wall
+				}
+			}
+			if wall, ok := s.nextWallClock(wallClock(t), to); ok {
+				return wall.Add(-time.Duration(offset) * time.Second).In(origLocation)
+			}
+			if to.Equal(limit) {
+				break
+			}
+			t = end.In(loc)
+		}
+		if limit.Year() > last {
+			return time.Time{}
+		}
+		t = time.Date(limit.Year(), time.January, 1, 0, 0, 0, 0, loc)
+		var ok bool
+		if limit, ok = s.nextLimit(t, last); !ok {
+			return time.Time{}
+		}
+	}
+}
 
-WRAP:
-	if t.Year() > yearLimit {
-		return time.Time{}
+// nextLimit returns the wall clock, as a time in UTC, up to which Next
+// searches the periods of the schedule's location from the given time: the
+// start of the second year after the next wall clock matching the schedule,
+// whatever the UTC offset.  It returns false if there is no such wall clock
+// up to the end of the given year.
+func (s *SpecSchedule) nextLimit(t time.Time, last int) (time.Time, bool) {
+	// A change of UTC offset moves the wall clock back by less than a day.
+	from := wallClock(t).Add(-24 * time.Hour)
+	wall, ok := s.nextWallClock(from, time.Date(last+1, time.January, 1, 0, 0, 0, 0, time.UTC))
+	if !ok {
+		return time.Time{}, false
 	}
+	return time.Date(wall.Year()+2, time.January, 1, 0, 0, 0, 0, time.UTC), true
+}
+
+// nextWallClock returns the earliest wall clock time from from, inclusive, to
+// to, exclusive, at which the schedule is activated.  Wall clock times are
+// given as times in UTC.
+//
+// Each field jumps straight to its next value in the schedule, found from its
+// bit set, resetting the smaller fields; a field with no value left carries
+// over to the next larger one.
+func (s *SpecSchedule) nextWallClock(from, to time.Time) (time.Time, bool) {
+	var (
+		days      uint64 // the days of daysYear and daysMonth
+		daysYear  int
+		daysMonth time.Month
+	)
+	for t := from; t.Before(to); {
+		year, month, day := t.Date()
+		hour, minute, second := t.Clock()
 
-	// Find the first applicable month.
-	// If it's this month, then do nothing.
-	for 1<<uint(t.Month())&s.Month == 0 {
-		// If we have to add a month, reset the other parts to 0.
-		if !added {
-			added = true
-			// Otherwise, set the date at the beginning (since the current time is irrelevant).
-			t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
-		}
-		t = t.AddDate(0, 1, 0)
-
-		// Wrapped around.
-		if t.Month() == time.January {
-			goto WRAP
-		}
-	}
-
-	// Now get a day in that month.
-	//
-	// NOTE: This causes issues for daylight savings regimes where midnight does
-	// not exist.  For example: Sao Paulo has DST that transforms midnight on
-	// 11/3 into 1am. Handle that by noticing when the Hour ends up != 0.
-	for !dayMatches(s, t) {
-		if !added {
-			added = true
-			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
-		}
-		t = t.AddDate(0, 0, 1)
-		// Notice if the hour is no longer midnight due to DST.
-		// Add an hour if it's 23, subtract an hour if it's 1.
-		if t.Hour() != 0 {
-			if t.Hour() > 12 {
-				t = t.Add(time.Duration(24-t.Hour()) * time.Hour)
-			} else {
-				t = t.Add(time.Duration(-t.Hour()) * time.Hour)
+		if !yearMatches(s, year) {
+			year = s.nextYear(year)
+			if year == 0 {
+				return time.Time{}, false
 			}
+			t = time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+
+		m, ok := nextBit(s.Month, uint(month), months.max)
+		if !ok {
+			t = time.Date(year+1, time.January, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if time.Month(m) != month {
+			t = time.Date(year, time.Month(m), 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+
+		if year != daysYear || month != daysMonth {
+			days, daysYear, daysMonth = s.dayBits(year, month), year, month
+		}
+		d, ok := nextBit(days, uint(day), dom.max)
+		if !ok {
+			t = time.Date(year, month+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if int(d) != day {
+			t = time.Date(year, month, int(d), 0, 0, 0, 0, time.UTC)
+			continue
+		}
+
+		h, ok := nextBit(s.Hour, uint(hour), hours.max)
+		if !ok {
+			t = time.Date(year, month, day+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if int(h) != hour {
+			t = time.Date(year, month, day, int(h), 0, 0, 0, time.UTC)
+			continue
 		}
 
-		if t.Day() == 1 {
-			goto WRAP
+		min, ok := nextBit(s.Minute, uint(minute), minutes.max)
+		if !ok {
+			t = time.Date(year, month, day, hour+1, 0, 0, 0, time.UTC)
+			continue
+		}
+		if int(min) != minute {
+			t = time.Date(year, month, day, hour, int(min), 0, 0, time.UTC)
+			continue
+		}
+
+		sec, ok := nextBit(s.Second, uint(second), seconds.max)
+		if !ok {
+			t = time.Date(year, month, day, hour, minute+1, 0, 0, time.UTC)
+			continue
+		}
+		if t = time.Date(year, month, day, hour, minute, int(sec), 0, time.UTC); t.Before(to) {
+			return t, true
 		}
 	}
+	return time.Time{}, false
+}
+
+// weekly has a bit set for every seventh day of a month, from 0.
+const weekly = 1 | 1<<7 | 1<<14 | 1<<21 | 1<<28
 
-	for 1<<uint(t.Hour())&s.Hour == 0 {
-		if !added {
-			added = true
-			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc)
+// dayBits returns the days of the given month on which the schedule is
+// activated, as the bits 1 to 31, as dayMatches has them.
+func (s *SpecSchedule) dayBits(year int, month time.Month) uint64 {
+	var (
+		last    = daysIn(month, year)
+		valid   = uint64(1)<<uint(last+1) - 2
+		first   = time.Date(year, month, 1, 0, 0, 0, 0, time.UTC).Weekday()
+		domBits = s.Dom & valid
+		dowBits uint64
+	)
+	for day := 1; day <= 7; day++ {
+		if s.Dow&(1<<uint((int(first)+day-1)%7)) > 0 {
+			dowBits |= weekly << uint(day)
 		}
-		t = t.Add(1 * time.Hour)
+	}
+	dowBits &= valid
 
-		if t.Hour() == 0 {
-			goto WRAP
+	if len(s.DomRules) > 0 || len(s.DowRules) > 0 {
+		for day := 1; day <= last; day++ {
+			t := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+			if rulesMatch(s.DomRules, t) {
+				domBits |= 1 << uint(day)
+			}
+			if rulesMatch(s.DowRules, t) {
+				dowBits |= 1 << uint(day)
+			}
 		}
 	}
+	if s.Dom&starBit > 0 || s.Dow&starBit > 0 {
+		return domBits & dowBits
+	}
+	return domBits | dowBits
+}
+
+// nextBit returns the lowest bit set in the bit set from the bit from to the
+// bit max, inclusive, or false if there is none.
+func nextBit(set uint64, from, max uint) (uint, bool) {
+	if from > max {
+		return 0, false
+	}
+	set &= (^uint64(0) << from) & (^uint64(0) >> (63 - max))
+	if set == 0 {
+		return 0, false
+	}
+	return uint(bits.TrailingZeros64(set)), true
+}
 
-	for 1<<uint(t.Minute())&s.Minute == 0 {
-		if !added {
-			added = true
-			t = t.Truncate(time.Minute)
+// Prev returns the last time this schedule is activated, earlier than the
+// given time.  If no time can be found to satisfy the schedule, return the
+// zero time.
+//
+// As with Next, wall clock times skipped or repeated by a daylight saving time
+// change are activations as the schedule's DST policy has it.
+func (s *SpecSchedule) Prev(t time.Time) time.Time {
+	if s.never() {
+		return time.Time{}
+	}
+	if s.Millisecond == nil {
+		return s.prevSecond(t)
+	}
+
+	// The start of the current second, if it is an activation.
+	second := t.Add(-time.Duration(t.Nanosecond()))
+	for i := len(s.Millisecond) - 1; i >= 0; i-- {
+		if prev := second.Add(time.Duration(s.Millisecond[i]) * time.Millisecond); prev.Before(t) {
+			if s.prevSecond(second.Add(time.Nanosecond)).Equal(second) {
+				return prev
+			}
+			break
 		}
-		t = t.Add(1 * time.Minute)
+	}
+	prev := s.prevSecond(second)
+	if prev.IsZero() {
+		return prev
+	}
+	return prev.Add(time.Duration(s.Millisecond[len(s.Millisecond)-1]) * time.Millisecond)
+}
+
+// prevMatch returns the last whole second before the given time whose wall
+// clock matches the schedule, finding times as nextMatch does.
+func (s *SpecSchedule) prevMatch(t time.Time) time.Time {
+	origLocation := t.Location()
+	loc := s.Location
+	if loc == time.Local {
+		loc = t.Location()
+	}
+
+	// Start at the latest possible time (the second before t).
+	t = t.Add(-time.Nanosecond).Truncate(time.Second).In(loc)
+
+	limit := time.Date(s.firstYear(t), time.January, 1, 0, 0, 0, 0, time.UTC)
 
-		if t.Minute() == 0 {
-			goto WRAP
+	// Search the wall clock times of each period with a constant UTC offset in
+	// turn, latest first.  Within a period, wall clock times and instants are
+	// in the same order, so the latest matching wall clock is the answer.
+	for {
+		start, _ := zoneBounds(t)
+		_, offset := t.Zone()
+		from := limit
+		if !start.IsZero() && wallClock(start.In(loc)).After(limit) {
+			from = wallClock(start.In(loc))
 		}
+		if wall, ok := s.prevWallClock(wallClock(t), from); ok {
+			return wall.Add(-time.Duration(offset) * time.Second).In(origLocation)
+		}
+		if from == limit {
+			return time.Time{}
+		}
+		t = start.Add(-time.Second).In(loc)
 	}
+}
 
-	for 1<<uint(t.Second())&s.Second == 0 {
-		if !added {
-			added = true
-			t = t.Truncate(time.Second)
+// prevWallClock returns the latest wall clock time between from and to,
+// inclusive, at which the schedule is activated.  Wall clock times are given
+// as times in UTC.
+func (s *SpecSchedule) prevWallClock(to, from time.Time) (time.Time, bool) {
+	for t := to; !t.Before(from); {
+		switch {
+		case !yearMatches(s, t.Year()):
+			year := s.prevYear(t.Year())
+			if year == 0 {
+				return time.Time{}, false
+			}
+			t = time.Date(year, time.December, 31, 23, 59, 59, 0, time.UTC)
+		case 1<<uint(t.Month())&s.Month == 0:
+			t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC).Add(-time.Second)
+		case !dayMatches(s, t):
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Add(-time.Second)
+		case 1<<uint(t.Hour())&s.Hour == 0:
+			t = t.Truncate(time.Hour).Add(-time.Second)
+		case 1<<uint(t.Minute())&s.Minute == 0:
+			t = t.Truncate(time.Minute).Add(-time.Second)
+		case 1<<uint(t.Second())&s.Second == 0:
+			t = t.Add(-time.Second)
+		default:
+			return t, true
 		}
-		t = t.Add(1 * time.Second)
+	}
+	return time.Time{}, false
+}
+
+// lastYear returns the last year in which Next searches from the given time,
+// in the schedule's location.  The calendar repeats every cycleYears, so a
+// schedule with no activation in them has none.
+func (s *SpecSchedule) lastYear(t time.Time) int {
+	if len(s.Year) > 0 {
+		return s.Year[len(s.Year)-1]
+	}
+	return t.Year() + cycleYears
+}
+
+// firstYear returns the first year in which Prev searches from the given time,
+// in the schedule's location, as lastYear does for Next.
+func (s *SpecSchedule) firstYear(t time.Time) int {
+	if len(s.Year) > 0 {
+		return s.Year[0]
+	}
+	return t.Year() - cycleYears
+}
+
+// monthDays has a bit set for each day of each month, in leap years, as
+// dayBits has them.
+var monthDays = [...]uint64{0, 1<<32 - 2, 1<<30 - 2, 1<<32 - 2, 1<<31 - 2, 1<<32 - 2, 1<<31 - 2,
+	1<<32 - 2, 1<<32 - 2, 1<<31 - 2, 1<<32 - 2, 1<<31 - 2, 1<<32 - 2}
+
+// never returns true if the schedule plainly has no activation in any year,
+// so that Next and Prev need not search a whole cycle of the calendar for
+// one: none of its days of the month exist in its months, as with "30 Feb",
+// and it has no days of the week to make up for them.  Within a cycle, each
+// day of each month falls on every day of the week, Feb 29 included.
+func (s *SpecSchedule) never() bool {
+	if s.Month&^starBit == 0 || s.Hour&^starBit == 0 || s.Minute&^starBit == 0 || s.Second&^starBit == 0 {
+		return true
+	}
+
+	// Day rules may match any day.
+	days := len(s.DomRules) > 0
+	for m := months.min; m <= months.max && !days; m++ {
+		days = s.Month&(1<<m) > 0 && s.Dom&monthDays[m] > 0
+	}
+	weekdays := len(s.DowRules) > 0 || s.Dow&(1<<(dow.max+1)-1) > 0
+	if s.Dom&starBit > 0 || s.Dow&starBit > 0 {
+		return !days || !weekdays
+	}
+	return !days && !weekdays
+}
+
+// granule returns the length of the periods into which the schedule divides
+// time: milliseconds if it has any, seconds if it activates on any but the
+// first of each minute, and minutes otherwise.
+func (s *SpecSchedule) granule() time.Duration {
+	switch {
+	case s.Millisecond != nil:
+		return time.Millisecond
+	case s.Second&^starBit != 1<<seconds.min:
+		return time.Second
+	}
+	return time.Minute
+}
 
-		if t.Second() == 0 {
-			goto WRAP
+// zoneBounds is like t.ZoneBounds, but finds the bounds itself where
+// ZoneBounds gives ones which do not contain t.  It does so for times past the
+// transitions listed in the time zone database, e.g. at the end of 2040 in
+// America/New_York.
+func zoneBounds(t time.Time) (start, end time.Time) {
+	start, end = t.ZoneBounds()
+	if !start.IsZero() && start.After(t) {
+		start = zoneChange(t, -1)
+	}
+	if !end.IsZero() && !end.After(t) {
+		end = zoneChange(t, 1)
+	}
+	return start, end
+}
+
+// zoneChange returns the first time after t with another UTC offset if dir is
+// 1, or the first time of the period with t's offset if dir is -1.  It returns
+// the zero time if the offset does not change within a year.
+func zoneChange(t time.Time, dir int) time.Time {
+	_, offset := t.Zone()
+	same := func(u time.Time) bool {
+		_, o := u.Zone()
+		return o == offset
+	}
+
+	// Step a day at a time to a time with another offset, and then halve the
+	// step down to the second.  Changes are on whole seconds.
+	in := t.Truncate(time.Second)
+	for i := 0; i < 366; i++ {
+		out := in.Add(time.Duration(dir) * 24 * time.Hour)
+		if !same(out) {
+			for d := out.Sub(in); d > time.Second || d < -time.Second; d = out.Sub(in) {
+				if mid := in.Add((d / 2).Truncate(time.Second)); same(mid) {
+					in = mid
+				} else {
+					out = mid
+				}
+			}
+			if dir > 0 {
+				return out
+			}
+			return in
 		}
+		in = out
 	}
+	return time.Time{}
+}
 
-	return t.In(origLocation)
+// wallClock returns the wall clock of the given time, as a time in UTC.
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
 }
 
 // dayMatches returns true if the schedule's day-of-week and day-of-month
 // restrictions are satisfied by the given time.
 func dayMatches(s *SpecSchedule, t time.Time) bool {
 	var (
-		domMatch bool = 1<<uint(t.Day())&s.Dom > 0
-		dowMatch bool = 1<<uint(t.Weekday())&s.Dow > 0
+		domMatch bool = 1<<uint(t.Day())&s.Dom > 0 || rulesMatch(s.DomRules, t)
+		dowMatch bool = 1<<uint(t.Weekday())&s.Dow > 0 || rulesMatch(s.DowRules, t)
 	)
 	if s.Dom&starBit > 0 || s.Dow&starBit > 0 {
 		return domMatch && dowMatch
 	}
 	return domMatch || dowMatch
 }
+
+// yearMatches returns true if the schedule is active in the given year.
+func yearMatches(s *SpecSchedule, year int) bool {
+	if s.Year == nil {
+		return true
+	}
+	i := sort.SearchInts(s.Year, year)
+	return i < len(s.Year) && s.Year[i] == year
+}
+
+// nextYear returns the first year after the given one in which the schedule
+// is active, or 0 if there is none.
+func (s *SpecSchedule) nextYear(year int) int {
+	if s.Year == nil {
+		return year + 1
+	}
+	i := sort.SearchInts(s.Year, year+1)
+	if i == len(s.Year) {
+		return 0
+	}
+	return s.Year[i]
+}
+
+// prevYear returns the last year before the given one in which the schedule
+// is active, or 0 if there is none.
+func (s *SpecSchedule) prevYear(year int) int {
+	if s.Year == nil {
+		return year - 1
+	}
+	i := sort.SearchInts(s.Year, year)
+	if i == 0 {
+		return 0
+	}
+	return s.Year[i-1]
+}
+
+// String returns the canonical spec for the schedule, which the default
+// parser, or one configured with the seconds field, turns back into an equal
+// schedule.  It has 5 fields if the schedule runs on second 0, 6 otherwise,
+// and 7 if it is limited to some years, e.g. "*/5 9-17 * * mon-fri".  The
+// location is given by a "TZ=" prefix unless it is time.Local.  The DST policy
+// is not part of it.
+func (s *SpecSchedule) String() string {
+	var fields []string
+	if s.Location != nil && s.Location != time.Local {
+		fields = append(fields, "TZ="+s.Location.String())
+	}
+	if s.Millisecond != nil {
+		fields = append(fields, valuesString(s.Millisecond, milliseconds, strconv.Itoa))
+	}
+	if s.Second != 1<<seconds.min || s.Year != nil || s.Millisecond != nil {
+		fields = append(fields, fieldString(s.Second, nil, seconds))
+	}
+	fields = append(fields,
+		fieldString(s.Minute, nil, minutes),
+		fieldString(s.Hour, nil, hours),
+		fieldString(s.Dom, s.DomRules, dom),
+		fieldString(s.Month, nil, months),
+		fieldString(s.Dow, s.DowRules, dow),
+	)
+	if s.Year != nil || s.Millisecond != nil {
+		// Specs with milliseconds always have a year field, so that their
+		// number of fields tells them apart.
+		year := "*"
+		if s.Year != nil {
+			year = valuesString(s.Year, years, strconv.Itoa)
+		}
+		fields = append(fields, year)
+	}
+	return strings.Join(fields, " ")
+}
+
+// MarshalText implements encoding.TextMarshaler, returning the canonical spec.
+func (s *SpecSchedule) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, accepting specs such as
+// those returned by String.
+func (s *SpecSchedule) UnmarshalText(text []byte) error {
+	schedule, err := parseText(string(text))
+	if err != nil {
+		return err
+	}
+	spec, ok := schedule.(*SpecSchedule)
+	if !ok {
+		return fmt.Errorf("not a crontab spec: %s", text)
+	}
+	*s = *spec
+	return nil
+}
+
+// fieldString returns the shortest expression for the bits and rules of a
+// field.  A star is only used if the field was given as one, since that
+// changes how the day fields are combined.
+func fieldString(bits uint64, rules []DayRule, r bounds) string {
+	var exprs []string
+	if bits&starBit > 0 {
+		exprs = append(exprs, "*")
+	} else if values := fieldValues(bits, r); len(values) > 0 {
+		exprs = append(exprs, valuesString(values, r, func(v int) string {
+			for name, value := range r.names {
+				if int(value) == v {
+					return name
+				}
+			}
+			return strconv.Itoa(v)
+		}))
+	}
+	for _, rule := range rules {
+		exprs = append(exprs, rule.String())
+	}
+	return strings.Join(exprs, ",")
+}
+
+// valuesString returns the shortest expression for the sorted values of a
+// field, naming them with the given function.
+func valuesString(values []int, r bounds, name func(int) string) string {
+	var (
+		first = values[0]
+		last  = values[len(values)-1]
+		step  = progression(values)
+	)
+	if step > 1 {
+		switch {
+		case first == int(r.min) && last+step > int(r.max):
+			return "*/" + strconv.Itoa(step)
+		case last+step > int(r.max):
+			return name(first) + "/" + strconv.Itoa(step)
+		}
+		return name(first) + "-" + name(last) + "/" + strconv.Itoa(step)
+	}
+
+	var exprs []string
+	for i := 0; i < len(values); {
+		j := i
+		for j+1 < len(values) && values[j+1] == values[j]+1 {
+			j++
+		}
+		switch {
+		case j-i >= 2:
+			exprs = append(exprs, name(values[i])+"-"+name(values[j]))
+		case j > i:
+			exprs = append(exprs, name(values[i]), name(values[j]))
+		default:
+			exprs = append(exprs, name(values[i]))
+		}
+		i = j + 1
+	}
+	return strings.Join(exprs, ",")
+}
+
+// DayRuleKind identifies the Quartz-style modifier used by a DayRule.
+type DayRuleKind int
+
+const (
+	LastDayOfMonth     DayRuleKind = iota + 1 // "L" or "L-3": the last day of the month, less N days
+	NearestWeekday                            // "15W": the weekday nearest to day N, within the month
+	LastWeekdayOfMonth                        // "LW": the last Monday to Friday of the month
+	NthDayOfWeek                              // "2#2": the Nth occurrence of Weekday in the month
+	LastDayOfWeek                             // "5L": the last occurrence of Weekday in the month
+	Workday                                   // "WD": a working day of ChinaWorkdays
+	NthWorkday                                // "3WD": the Nth working day of the month
+	LastWorkdayOfMonth                        // "LWD": the last working day of the month
+)
+
+// DayRule is a day-of-month or day-of-week expression that depends on the
+// length or layout of the month, and so cannot be stored as a bit set.
+type DayRule struct {
+	Kind DayRuleKind
+
+	// N is the offset from the end of the month for LastDayOfMonth, the
+	// day of month for NearestWeekday and the occurrence for NthDayOfWeek
+	// and NthWorkday.
+	N int
+
+	// Weekday is the day of the week for NthDayOfWeek and LastDayOfWeek.
+	Weekday time.Weekday
+}
+
+// Matches returns true if the day containing t satisfies the rule.
+func (r DayRule) Matches(t time.Time) bool {
+	var (
+		day  = t.Day()
+		last = daysIn(t.Month(), t.Year())
+	)
+	switch r.Kind {
+	case LastDayOfMonth:
+		return day == last-r.N
+	case NearestWeekday:
+		if r.N > last {
+			return false
+		}
+		return day == nearestWeekday(t.Year(), t.Month(), r.N, last)
+	case LastWeekdayOfMonth:
+		return day == nearestWeekday(t.Year(), t.Month(), last, last)
+	case NthDayOfWeek:
+		return t.Weekday() == r.Weekday && (day-1)/7+1 == r.N
+	case LastDayOfWeek:
+		return t.Weekday() == r.Weekday && day+7 > last
+	case Workday:
+		return ChinaWorkdays.isWorkday(t.Year(), t.Month(), day)
+	case NthWorkday:
+		return ChinaWorkdays.workdayOfMonth(t.Year(), t.Month(), day) == r.N
+	case LastWorkdayOfMonth:
+		return ChinaWorkdays.lastWorkdayOfMonth(t.Year(), t.Month(), day)
+	}
+	return false
+}
+
+// String returns the rule as it is written in a spec, e.g. "L-3" or "mon#1".
+func (r DayRule) String() string {
+	weekday := strings.ToLower(r.Weekday.String()[:3])
+	switch r.Kind {
+	case LastDayOfMonth:
+		if r.N > 0 {
+			return fmt.Sprintf("L-%d", r.N)
+		}
+		return "L"
+	case NearestWeekday:
+		return fmt.Sprintf("%dW", r.N)
+	case LastWeekdayOfMonth:
+		return "LW"
+	case NthDayOfWeek:
+		return fmt.Sprintf("%s#%d", weekday, r.N)
+	case LastDayOfWeek:
+		return weekday + "L"
+	case Workday:
+		return "WD"
+	case NthWorkday:
+		return fmt.Sprintf("%dWD", r.N)
+	case LastWorkdayOfMonth:
+		return "LWD"
+	}
+	return ""
+}
+
+// rulesMatch returns true if any of the given rules match the day containing t.
+func rulesMatch(rules []DayRule, t time.Time) bool {
+	for _, r := range rules {
+		if r.Matches(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// daysIn returns the number of days in the given month.
+func daysIn(m time.Month, year int) int {
+	return time.Date(year, m+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// nearestWeekday returns the day of month of the Monday to Friday closest to
+// the given day, without leaving the month (which has last days).
+func nearestWeekday(year int, m time.Month, day, last int) int {
+	switch time.Date(year, m, day, 0, 0, 0, 0, time.UTC).Weekday() {
+	case time.Saturday:
+		if day == 1 {
+			return day + 2
+		}
+		return day - 1
+	case time.Sunday:
+		if day == last {
+			return day - 2
+		}
+		return day + 1
+	}
+	return day
+}
@@ -1,6 +1,8 @@
 package cron
 
 import (
+	"encoding/json"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -175,14 +177,60 @@ func TestNext(t *testing.T) {
 		// Unsatisfiable
 		{"Mon Jul 9 23:35 2012", "0 0 0 30 Feb ?", ""},
 		{"Mon Jul 9 23:35 2012", "0 0 0 31 Apr ?", ""},
+		{"Mon Jul 9 23:35 2012", "0 0 0 30 Feb Mon", "Mon Feb 4 00:00 2013"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 ? Feb 6#5", "Sat Feb 29 00:00 2020"},
+
+		// Activations more than five years apart
+		{"Tue Mar 1 00:00 2016", "0 0 0 ? Feb 1#5", "Mon Feb 29 00:00 2044"},
+		{"Tue Mar 1 00:00 2016", "TZ=America/New_York 0 0 9 ? Feb 1#5", "2044-02-29T09:00:00-0500"},
 
 		// Monthly job
 		{"TZ=America/New_York 2012-11-04T00:00:00-0400", "0 0 3 3 * ?", "2012-12-03T03:00:00-0500"},
 
+		// Last day of the month
+		{"Mon Jul 9 23:35 2012", "0 0 0 L * ?", "Tue Jul 31 00:00 2012"},
+		{"Tue Jul 31 00:00 2012", "0 0 0 L * ?", "Fri Aug 31 00:00 2012"},
+		{"Mon Jan 30 12:00 2012", "0 0 0 L * ?", "Tue Jan 31 00:00 2012"},
+		{"Wed Feb 1 00:00 2012", "0 0 0 L * ?", "Wed Feb 29 00:00 2012"},
+		{"Fri Feb 1 00:00 2013", "0 0 0 L * ?", "Thu Feb 28 00:00 2013"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 L-3 * ?", "Sat Jul 28 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 1,L * ?", "Tue Jul 31 00:00 2012"},
+
+		// Nearest weekday, without leaving the month
+		{"Mon Jul 9 23:35 2012", "0 0 0 15W * ?", "Mon Jul 16 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 14W * ?", "Fri Jul 13 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 1W * ?", "Wed Aug 1 00:00 2012"},
+		{"Mon Aug 20 23:35 2012", "0 0 0 1W * ?", "Mon Sep 3 00:00 2012"},
+		{"Mon Sep 10 23:35 2012", "0 0 0 30W * ?", "Fri Sep 28 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 LW * ?", "Tue Jul 31 00:00 2012"},
+		{"Mon Sep 10 23:35 2012", "0 0 0 LW * ?", "Fri Sep 28 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 31W * ?", "Tue Jul 31 00:00 2012"},
+
+		// Nth and last weekday of the month
+		{"Mon Jul 9 23:35 2012", "0 0 0 ? * 2#2", "Tue Jul 10 00:00 2012"},
+		{"Tue Jul 10 00:00 2012", "0 0 0 ? * 2#2", "Tue Aug 14 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 ? * mon#1", "Mon Aug 6 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 ? * 5L", "Fri Jul 27 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 ? * friL", "Fri Jul 27 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 ? * 1#5", "Mon Jul 30 00:00 2012"},
+		{"Tue Jul 31 23:35 2012", "0 0 0 ? * 1#5", "Mon Oct 29 00:00 2012"},
+
+		// Modifiers restrict their field like any other value.
+		{"Mon Jul 9 23:35 2012", "0 0 0 L * 1", "Mon Jul 16 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 L Feb ?", "Thu Feb 28 00:00 2013"},
+
 		// Test the scenario of DST resulting in midnight not being a valid time.
 		// https://github.com/robfig/cron/issues/157
 		{"2018-10-17T05:00:00-0400", "TZ=America/Sao_Paulo 0 0 9 10 * ?", "2018-11-10T06:00:00-0500"},
 		{"2018-02-14T05:00:00-0500", "TZ=America/Sao_Paulo 0 0 9 22 * ?", "2018-02-22T07:00:00-0500"},
+
+		// Daylight savings time past the transitions listed in the time zone
+		// database, from 2041 in America/New_York.
+		{"2040-12-31T12:00:00-0500", "TZ=America/New_York 0 0 12 * * ?", "2041-01-01T12:00:00-0500"},
+		{"2045-01-01T00:00:00-0500", "TZ=America/New_York 0 0 9 1 Jul ?", "2045-07-01T09:00:00-0400"},
+		{"2045-03-12T00:00:00-0500", "TZ=America/New_York 0 0 2 * * ?", "2045-03-13T02:00:00-0400"},
+		{"2045-11-05T01:30:00-0400", "TZ=America/New_York 0 30 1 * * ?", "2045-11-05T01:30:00-0500"},
+		{"2038-01-01T00:00:00-0500", "TZ=America/New_York 0 0 0 30 2 *", ""},
 	}
 
 	for _, c := range runs {
@@ -199,6 +247,225 @@ func TestNext(t *testing.T) {
 	}
 }
 
+func TestNextWithYear(t *testing.T) {
+	parser := NewParser(Second | Minute | Hour | Dom | Month | Dow | Year)
+	runs := []struct {
+		time, spec string
+		expected   string
+	}{
+		{"Mon Jul 9 23:35 2012", "0 0 0 1 1 * 2027-2030", "Fri Jan 1 00:00 2027"},
+		{"Fri Jan 1 00:00 2027", "0 0 0 1 1 * 2027-2030", "Sat Jan 1 00:00 2028"},
+		{"Tue Jan 1 00:00 2030", "0 0 0 1 1 * 2027-2030", ""},
+		{"Sat Jun 1 00:00 2030", "0 0 0 1 1 * 2027-2030", ""},
+
+		// Years in the middle of a range
+		{"Mon Jul 9 23:35 2012", "30 * * * * * 2012", "Mon Jul 9 23:35:30 2012"},
+		{"Mon Dec 31 23:59:45 2012", "0 * * * * * 2012,2014", "Wed Jan 1 00:00 2014"},
+
+		// Beyond the five year search limit
+		{"Mon Jul 9 23:35 2012", "0 0 12 25 Dec * 2040", "Tue Dec 25 12:00 2040"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 29 Feb ? 2097-2099", ""},
+		{"Mon Jul 9 23:35 2012", "0 0 0 29 Feb ? 2030-2040", "Tue Feb 29 00:00 2032"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 ? * 5L 2050", "Fri Jan 28 00:00 2050"},
+		{"Mon Jul 9 23:35 2012", "TZ=America/New_York 0 0 0 1 1 ? 2045", "2045-01-01T00:00:00-0500"},
+		{"Mon Jul 9 23:35 2012", "TZ=America/New_York 0 0 0 30 2 ? 2040-2045", ""},
+	}
+
+	for _, c := range runs {
+		sched, err := parser.Parse(c.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		actual := sched.Next(getTime(c.time))
+		expected := getTime(c.expected)
+		if !actual.Equal(expected) {
+			t.Errorf("%s, \"%s\": (expected) %v != %v (actual)", c.time, c.spec, expected, actual)
+		}
+	}
+}
+
+func TestPrev(t *testing.T) {
+	parser := NewParser(Second | Minute | Hour | Dom | Month | Dow | YearOptional | Descriptor)
+	runs := []struct {
+		time, spec string
+		expected   string
+	}{
+		// Simple cases
+		{"Mon Jul 9 14:45 2012", "0 0/15 * * * *", "Mon Jul 9 14:30 2012"},
+		{"Mon Jul 9 14:59 2012", "0 0/15 * * * *", "Mon Jul 9 14:45 2012"},
+		{"Mon Jul 9 14:45:00.5 2012", "0 0/15 * * * *", "Mon Jul 9 14:45 2012"},
+		{"Mon Jul 9 14:45:01 2012", "* * * * * *", "Mon Jul 9 14:45 2012"},
+
+		// Wrap around hours, days, months and years
+		{"Mon Jul 9 15:10 2012", "0 20-35/15 * * * *", "Mon Jul 9 14:35 2012"},
+		{"Mon Jul 9 00:10 2012", "0 20-35/15 * * * *", "Sun Jul 8 23:35 2012"},
+		{"Mon Jul 9 00:00 2012", "0 0 12 * * *", "Sun Jul 8 12:00 2012"},
+		{"Sun Jul 1 00:00 2012", "0 0 0 31 * *", "Thu May 31 00:00 2012"},
+		{"Sun Jan 1 00:00 2012", "0 0 0 * * *", "Sat Dec 31 00:00 2011"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 29 Feb ?", "Wed Feb 29 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 L Feb ?", "Wed Feb 29 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 ? * 5L", "Fri Jun 29 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "@yearly", "Sun Jan 1 00:00 2012"},
+
+		// Years
+		{"Mon Jul 9 23:35 2012", "0 0 0 1 1 * 2005-2008", "Tue Jan 1 00:00 2008"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 1 1 * 2027", ""},
+		{"Mon Jul 9 23:35 2012", "0 0 12 25 Dec * 1990", "Tue Dec 25 12:00 1990"},
+
+		// Unsatisfiable
+		{"Mon Jul 9 23:35 2012", "0 0 0 30 Feb ?", ""},
+		{"Mon Jul 9 23:35 2012", "0 0 0 31 Apr,Jun ?", ""},
+
+		// Activations more than five years apart
+		{"Mon Feb 28 00:00 2044", "0 0 0 ? Feb 1#5", "Mon Feb 29 00:00 2016"},
+
+		// Daylight savings time 2am EST (-5) -> 3am EDT (-4)
+		{"2012-03-11T04:00:00-0400", "TZ=America/New_York 0 0 * * * ?", "2012-03-11T03:00:00-0400"},
+		{"2012-03-11T03:00:00-0400", "TZ=America/New_York 0 0 * * * ?", "2012-03-11T01:00:00-0500"},
+		{"2012-03-11T03:00:00-0400", "TZ=America/New_York 0 30 2 * * ?", "2012-03-10T02:30:00-0500"},
+		{"2012-03-12T00:00:00-0400", "TZ=America/New_York 0 30 2 * * ?", "2012-03-10T02:30:00-0500"},
+
+		// Daylight savings time 2am EDT (-4) -> 1am EST (-5)
+		{"2012-11-04T02:00:00-0500", "TZ=America/New_York 0 0 * * * ?", "2012-11-04T01:00:00-0500"},
+		{"2012-11-04T01:00:00-0500", "TZ=America/New_York 0 0 * * * ?", "2012-11-04T01:00:00-0400"},
+		{"2012-11-04T01:10:00-0500", "TZ=America/New_York 0 */15 * * * ?", "2012-11-04T01:00:00-0500"},
+		{"2012-11-04T01:00:00-0500", "TZ=America/New_York 0 */15 * * * ?", "2012-11-04T01:45:00-0400"},
+		{"2012-11-04T01:10:00-0500", "TZ=America/New_York 0 30 1 * * ?", "2012-11-04T01:30:00-0400"},
+		{"2012-11-04T01:40:00-0500", "TZ=America/New_York 0 30 1 * * ?", "2012-11-04T01:30:00-0500"},
+
+		// Times in time.Local are read in the location of the given time.
+		{"TZ=America/New_York 2012-03-11T03:00:00-0400", "0 0 * * * ?", "2012-03-11T01:00:00-0500"},
+
+		// Past the transitions listed in the time zone database.
+		{"2041-01-01T06:00:00-0500", "TZ=America/New_York 0 0 12 * * ?", "2040-12-31T12:00:00-0500"},
+		{"2045-07-01T12:00:00-0400", "TZ=America/New_York 0 0 9 * * ?", "2045-07-01T09:00:00-0400"},
+		{"2045-03-13T00:00:00-0400", "TZ=America/New_York 0 30 2 * * ?", "2045-03-11T02:30:00-0500"},
+		{"2045-11-05T01:10:00-0500", "TZ=America/New_York 0 30 1 * * ?", "2045-11-05T01:30:00-0400"},
+		{"2045-01-01T00:00:00-0500", "TZ=America/New_York 0 0 0 30 2 ?", ""},
+		{"2050-01-01T00:00:00-0500", "TZ=America/New_York 0 0 0 1 1 ? 2045", "2045-01-01T00:00:00-0500"},
+	}
+
+	for _, c := range runs {
+		sched, err := parser.Parse(c.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		actual := sched.(PrevScheduler).Prev(getTime(c.time))
+		expected := getTime(c.expected)
+		if !actual.Equal(expected) {
+			t.Errorf("%s, \"%s\": (expected) %v != %v (actual)", c.time, c.spec, expected, actual)
+		}
+	}
+}
+
+// Prev is the inverse of Next across daylight savings time changes.
+func TestPrevInvertsNext(t *testing.T) {
+	specs := []string{
+		"TZ=America/New_York 0 */20 * * * ?",
+		"TZ=America/New_York 0 30 1-3 * * ?",
+		"TZ=Europe/London 0 0 0/2 * * ?",
+		"TZ=America/Sao_Paulo 0 0 0 * * ?",
+	}
+	starts := []string{
+		"2012-03-10T22:00:00-0500",
+		"2012-11-03T22:00:00-0400",
+		"2018-10-20T22:00:00-0300",
+	}
+	for _, spec := range specs {
+		sched, err := secondParser.Parse(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, start := range starts {
+			times := NextN(sched, getTime(start), 20)
+			for i := 1; i < len(times); i++ {
+				if prev := sched.(PrevScheduler).Prev(times[i]); !prev.Equal(times[i-1]) {
+					t.Errorf("%s: expected the activation before %v to be %v, got %v", spec, times[i], times[i-1], prev)
+				}
+			}
+		}
+	}
+}
+
+func TestSpecString(t *testing.T) {
+	parser := NewParser(SecondOptional | Minute | Hour | Dom | Month | Dow | Descriptor)
+	yearParser := NewParser(Second | Minute | Hour | Dom | Month | Dow | Year)
+	tests := []struct {
+		spec, expected string
+	}{
+		{"*/5 9-17 * * mon-fri", "*/5 9-17 * * mon-fri"},
+		{"0 */5 9-17 ? * MON,TUE,WED,THU,FRI", "*/5 9-17 * * mon-fri"},
+		{"30 */5 9-17 * * 1-5", "30 */5 9-17 * * mon-fri"},
+		{"0-59 * * * *", "0-59 * * * *"},
+		{"0,1 0 1,2,3,10 * *", "0,1 0 1-3,10 * *"},
+		{"10/20 * * * *", "10/20 * * * *"},
+		{"0 2-10/4 * * *", "0 2-10/4 * * *"},
+		{"0 0 1 1,4,7,10 *", "0 0 1 */3 *"},
+		{"0 0 L,15W * *", "0 0 L,15W * *"},
+		{"0 0 1,L-3,LW * *", "0 0 1,L-3,LW * *"},
+		{"0 12 * * 1#1,5L,6", "0 12 * * sat,mon#1,friL"},
+		{"0 0 1-31 * *", "0 0 1-31 * *"},
+		{"TZ=America/New_York 0 6 * * *", "TZ=America/New_York 0 6 * * *"},
+		{"CRON_TZ=UTC 0 6 * * *", "TZ=UTC 0 6 * * *"},
+		{"@daily", "0 0 * * *"},
+		{"@yearly", "0 0 1 jan *"},
+	}
+
+	for _, c := range tests {
+		s, err := parser.Parse(c.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual := s.(*SpecSchedule).String(); actual != c.expected {
+			t.Errorf("%s: expected %q, got %q", c.spec, c.expected, actual)
+		}
+		again, err := parser.Parse(c.expected)
+		if err != nil {
+			t.Errorf("%s: %v", c.expected, err)
+		} else if !reflect.DeepEqual(again, s) {
+			t.Errorf("%s: expected %+v, got %+v", c.expected, s, again)
+		}
+	}
+
+	s, err := yearParser.Parse("0 0 0 1 1 * 2027-2030,2032")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual, expected := s.(*SpecSchedule).String(), "0 0 0 1 jan * 2027-2030,2032"; actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+}
+
+func TestSpecJSON(t *testing.T) {
+	s, err := ParseStandard("TZ=Asia/Tokyo */5 9-17 * * mon-fri")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `"TZ=Asia/Tokyo */5 9-17 * * mon-fri"`; string(data) != expected {
+		t.Errorf("expected %s, got %s", expected, data)
+	}
+
+	var actual SpecSchedule
+	if err := json.Unmarshal(data, &actual); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&actual, s) {
+		t.Errorf("expected %+v, got %+v", s, &actual)
+	}
+
+	for _, text := range []string{`"* * *"`, `"@every 5m"`, `5`} {
+		if err := json.Unmarshal([]byte(text), &actual); err == nil {
+			t.Errorf("%s: expected an error", text)
+		}
+	}
+}
+
 func TestErrors(t *testing.T) {
 	invalidSpecs := []string{
 		"xyz",
@@ -245,6 +512,59 @@ func getTime(value string) time.Time {
 	panic("could not parse time value " + value)
 }
 
+func TestMilliseconds(t *testing.T) {
+	parser := NewParser(Millisecond | Second | Minute | Hour | Dom | Month | Dow)
+	tests := []struct {
+		spec     string
+		time     string
+		next     []string
+		prev     string
+		text     string
+		describe string
+	}{
+		{"*/250 * * * * * *", "Mon Jul 9 14:45:00.3 2012",
+			[]string{"Mon Jul 9 14:45:00.5 2012", "Mon Jul 9 14:45:00.75 2012", "Mon Jul 9 14:45:01 2012"},
+			"Mon Jul 9 14:45:00.25 2012", "*/250 * * * * * * *", "Every second, every 250 milliseconds"},
+		{"0,500 30 * * * * *", "Mon Jul 9 14:45:30.2 2012",
+			[]string{"Mon Jul 9 14:45:30.5 2012", "Mon Jul 9 14:46:30 2012"},
+			"Mon Jul 9 14:45:30 2012", "0,500 30 * * * * * *", "At 30 seconds past the minute, at milliseconds 0 and 500"},
+		{"100 0 0 9 * * mon-fri", "Mon Jul 9 09:00:00.1 2012",
+			[]string{"Tue Jul 10 09:00:00.1 2012"},
+			"Fri Jul 6 09:00:00.1 2012", "100 0 0 9 * * mon-fri *", "At 09:00 on Monday through Friday, at millisecond 100"},
+		{"0 0 * * * * *", "Mon Jul 9 14:45:00.3 2012",
+			[]string{"Mon Jul 9 14:46 2012"},
+			"Mon Jul 9 14:45 2012", "* * * * *", "Every minute"},
+	}
+	for _, c := range tests {
+		sched, err := parser.Parse(c.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		assertTimes(t, c.spec, NextN(sched, getTime(c.time), len(c.next)), c.next)
+		if prev := sched.(*SpecSchedule).Prev(getTime(c.time)); !prev.Equal(getTime(c.prev)) {
+			t.Errorf("%s: expected prev %v, got %v", c.spec, getTime(c.prev), prev)
+		}
+		if text := sched.(*SpecSchedule).String(); text != c.text {
+			t.Errorf("%s: expected %q, got %q", c.spec, c.text, text)
+		}
+		if parsed, err := parseText(c.text); err != nil || !reflect.DeepEqual(parsed, sched) {
+			t.Errorf("%s: expected %q to parse back, got %v (%v)", c.spec, c.text, parsed, err)
+		}
+		if actual := Describe(sched); actual != c.describe {
+			t.Errorf("%s: expected %q, got %q", c.spec, c.describe, actual)
+		}
+	}
+
+	_, err := parser.Parse("1000 * * * * * *")
+	if perr, ok := err.(*ParseError); !ok || perr.Field != FieldMillisecond || perr.Reason != ReasonAboveMaximum || perr.Max != 999 {
+		t.Errorf("expected a milliseconds error, got %#v", err)
+	}
+	if _, err := parser.Parse("0 0 9 * * 1-5"); err == nil || !strings.Contains(err.Error(), "expected exactly 7 fields, found 6") {
+		t.Errorf("expected a field count error, got %v", err)
+	}
+}
+
 func TestNextWithTz(t *testing.T) {
 	runs := []struct {
 		time, spec string
@@ -298,3 +618,29 @@ func TestSlash0NoHang(t *testing.T) {
 		t.Error("expected an error on 0 increment")
 	}
 }
+
+func BenchmarkNext(b *testing.B) {
+	benchmarks := []struct {
+		name, spec string
+	}{
+		{"EveryMinute", "* * * * *"},
+		{"Hourly", "0 * * * *"},
+		{"Weekdays", "30 9 * * mon-fri"},
+		{"Sparse", "0 0 29 2 *"},
+		{"LastFriday", "0 18 * * 5L"},
+		{"Never", "0 0 30 2 *"},
+		{"NewYork", "TZ=America/New_York 30 2 * * *"},
+	}
+	start := time.Date(2012, time.July, 9, 23, 35, 0, 0, time.UTC)
+	for _, bm := range benchmarks {
+		sched, err := ParseStandard(bm.spec)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(bm.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				sched.Next(start)
+			}
+		})
+	}
+}
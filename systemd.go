@@ -0,0 +1,537 @@
+package cron
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SystemdParser is a ScheduleParser for systemd calendar events, as used by
+// the OnCalendar setting of timer units (see systemd.time(7)):
+//
+//	[weekdays] [[year-]month-day] [hour:minute[:second]] [time zone]
+//
+// such as:
+//
+//	Mon..Fri *-*-* 09:00:00
+//	*-*-01 04:00
+//	Sat,Sun 10:30 Europe/Berlin
+//	*:0/15
+//	weekly
+//
+// Each component is "*", or a list of values, ranges ("1..5") and repetitions
+// ("0/15", "1..12/2").  A day written after "~" instead of "-" counts back
+// from the end of the month, e.g. "*-02~03" is the third last day of
+// February.  The date defaults to "*-*-*", and the time to "00:00:00".  The
+// shorthands minutely, hourly, daily, weekly, monthly, quarterly,
+// semiannually and yearly are accepted, and so is a "TZ=" prefix.
+//
+// As in systemd, and unlike in crontab specs, an event with both weekdays and
+// days of the month occurs on days matching both of them.  Sub-second times
+// are not supported.
+type SystemdParser struct {
+	// Fallback, if set, parses specs that do not look like calendar events,
+	// such as crontab specs and descriptors.
+	Fallback ScheduleParser
+}
+
+// Parse returns the schedule of the given calendar event, or a *ParseError
+// if it is not valid.
+func (p SystemdParser) Parse(spec string) (Schedule, error) {
+	return p.ParseWithSeed(spec, "")
+}
+
+// ParseWithSeed is like Parse, but passes the seed on to the Fallback parser
+// if it accepts one.
+func (p SystemdParser) ParseWithSeed(spec, seed string) (Schedule, error) {
+	if p.Fallback != nil && !isSystemd(spec) {
+		if fallback, ok := p.Fallback.(SeededScheduleParser); ok {
+			return fallback.ParseWithSeed(spec, seed)
+		}
+		return p.Fallback.Parse(spec)
+	}
+	return parseSystemd(spec)
+}
+
+// systemdShorthands are the calendar events with names.
+var systemdShorthands = map[string]string{
+	"minutely":     "*-*-* *:*:00",
+	"hourly":       "*-*-* *:00:00",
+	"daily":        "*-*-* 00:00:00",
+	"monthly":      "*-*-01 00:00:00",
+	"weekly":       "Mon *-*-* 00:00:00",
+	"yearly":       "*-01-01 00:00:00",
+	"annually":     "*-01-01 00:00:00",
+	"quarterly":    "*-01,04,07,10-01 00:00:00",
+	"semiannually": "*-01,07-01 00:00:00",
+}
+
+// systemdWeekdays are the names of the days of the week in calendar events.
+var systemdWeekdays = map[string]uint{
+	"sun": 0, "sunday": 0,
+	"mon": 1, "monday": 1,
+	"tue": 2, "tuesday": 2,
+	"wed": 3, "wednesday": 3,
+	"thu": 4, "thursday": 4,
+	"fri": 5, "friday": 5,
+	"sat": 6, "saturday": 6,
+}
+
+// isSystemd returns true if the spec looks like a calendar event rather than
+// a crontab spec or descriptor: it has a time ("09:00"), a ".." range or a
+// "~" day, or is one of the shorthands.  A "~" jitter suffix is not a day.
+func isSystemd(spec string) bool {
+	_, rest, err := parseTimezone(strings.TrimSpace(spec))
+	if err != nil || strings.HasPrefix(rest, "@") || isRecurrence(rest) {
+		return false
+	}
+	rest, _, _ = splitJitter(rest)
+	fields := strings.Fields(rest)
+	if len(fields) > 0 && systemdShorthands[strings.ToLower(fields[0])] != "" {
+		return true
+	}
+	return strings.ContainsAny(rest, ":~") || strings.Contains(rest, "..")
+}
+
+// parseSystemd returns the schedule of the given calendar event.
+func parseSystemd(spec string) (Schedule, error) {
+	var (
+		original = spec
+		spans    = fieldSpans(spec)
+	)
+	if len(spans) == 0 {
+		return nil, &ParseError{Field: FieldSpec, Reason: ReasonEmpty, Message: "empty spec string"}
+	}
+	loc, spec, err := parseTimezone(strings.TrimSpace(spec))
+	if err != nil {
+		return nil, locate(err, original, FieldTimezone, spans[0].start, spans[0].end)
+	}
+	if len(strings.Fields(spec)) < len(spans) {
+		spans = spans[1:]
+	}
+
+	// A time zone may follow the event.
+	fields := strings.Fields(spec)
+	if n := len(fields); n > 1 && isSystemdTimezone(fields[n-1]) {
+		if loc, err = time.LoadLocation(fields[n-1]); err != nil {
+			err = parseErrorf(ReasonLocation, fields[n-1], "provided bad location %s: %v", fields[n-1], err)
+			return nil, locate(err, original, FieldTimezone, spans[n-1].start, spans[n-1].end)
+		}
+		fields, spans = fields[:n-1], spans[:n-1]
+	}
+
+	// Shorthands are expanded in place, and located as a whole.
+	if len(fields) == 1 {
+		if expanded := systemdShorthands[strings.ToLower(fields[0])]; expanded != "" {
+			fields = strings.Fields(expanded)
+			whole := spans[0]
+			spans = make([]span, len(fields))
+			for i := range spans {
+				spans[i] = whole
+			}
+		}
+	}
+
+	var (
+		event = systemdEvent{original: original, loc: loc}
+		i     = 0
+	)
+	if i < len(fields) && isSystemdWeekdays(fields[i]) {
+		event.weekdays = &fields[i]
+		event.weekdaySpan = spans[i]
+		i++
+	}
+	if i < len(fields) && !strings.Contains(fields[i], ":") {
+		event.date = &fields[i]
+		event.dateSpan = spans[i]
+		i++
+	}
+	if i < len(fields) && strings.Contains(fields[i], ":") {
+		event.time = &fields[i]
+		event.timeSpan = spans[i]
+		i++
+	}
+	if i < len(fields) || i == 0 {
+		err = parseErrorf(ReasonSyntax, "", "expected [weekdays] [date] [time] [time zone]: %s", original)
+		if i < len(fields) {
+			return nil, locate(err, original, FieldSpec, spans[i].start, spans[i].end)
+		}
+		return nil, locate(err, original, FieldSpec, 0, len(original))
+	}
+	return event.schedule()
+}
+
+// isSystemdTimezone returns true if the field may be a time zone rather than
+// a part of the event.
+func isSystemdTimezone(field string) bool {
+	c := field[0]
+	return ('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z') &&
+		!isSystemdWeekdays(field) && systemdShorthands[strings.ToLower(field)] == ""
+}
+
+// isSystemdWeekdays returns true if the field is a list of weekdays.
+func isSystemdWeekdays(field string) bool {
+	for _, item := range strings.Split(field, ",") {
+		for _, name := range strings.Split(item, "..") {
+			if _, ok := systemdWeekdays[strings.ToLower(name)]; !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// systemdEvent is a calendar event split into its parts, which are nil if
+// they were omitted.
+type systemdEvent struct {
+	original                        string
+	loc                             *time.Location
+	weekdays, date, time            *string
+	weekdaySpan, dateSpan, timeSpan span
+}
+
+// schedule returns the schedule of the event.
+func (e *systemdEvent) schedule() (Schedule, error) {
+	s := &SpecSchedule{
+		Second:   1 << seconds.min,
+		Minute:   1 << minutes.min,
+		Hour:     1 << hours.min,
+		Dom:      all(dom),
+		Month:    all(months),
+		Dow:      all(dow),
+		Location: e.loc,
+	}
+	var err error
+	if e.weekdays != nil {
+		if s.Dow, _, err = e.field(*e.weekdays, FieldDow, e.weekdaySpan.start, dow, false); err != nil {
+			return nil, err
+		}
+	}
+	if e.date != nil {
+		if err = e.parseDate(s); err != nil {
+			return nil, err
+		}
+	}
+	if e.time != nil {
+		if err = e.parseTime(s); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.Dom&starBit == 0 && s.Dow&starBit == 0 {
+		weekdays := s.Dow
+		s.Dow = all(dow)
+		return &SystemdSchedule{Date: s, Weekdays: weekdays}, nil
+	}
+	return s, nil
+}
+
+// parseDate sets the year, month and day of the schedule from the date:
+//
+//	[year "-"] month ("-" | "~") day
+func (e *systemdEvent) parseDate(s *SpecSchedule) error {
+	var (
+		date    = *e.date
+		start   = e.dateSpan.start
+		sep     = strings.LastIndexAny(date, "-~")
+		fromEnd = sep >= 0 && date[sep] == '~'
+	)
+	if sep < 0 {
+		err := parseErrorf(ReasonSyntax, date, "expected month-day or year-month-day: %s", date)
+		return locate(err, e.original, FieldSpec, start, e.dateSpan.end)
+	}
+	parts := strings.Split(date[:sep], "-")
+	if len(parts) > 2 {
+		err := parseErrorf(ReasonSyntax, date, "too many hyphens in date: %s", date)
+		return locate(err, e.original, FieldSpec, start, e.dateSpan.end)
+	}
+
+	var err error
+	offset := start
+	if len(parts) == 2 {
+		var (
+			bits uint64
+			list []int
+		)
+		if bits, list, err = e.field(parts[0], FieldYear, offset, years, false); err != nil {
+			return err
+		}
+		if bits&starBit == 0 {
+			s.Year = list
+		}
+		offset += len(parts[0]) + 1
+	}
+	month := parts[len(parts)-1]
+	if s.Month, _, err = e.field(month, FieldMonth, offset, months, false); err != nil {
+		return err
+	}
+	offset += len(month) + 1
+
+	day := date[sep+1:]
+	if !fromEnd {
+		s.Dom, _, err = e.field(day, FieldDom, offset, dom, false)
+		return err
+	}
+	_, values, err := e.field(day, FieldDom, offset, dom, true)
+	if err != nil {
+		return err
+	}
+	s.Dom = 0
+	for _, v := range values {
+		s.DomRules = append(s.DomRules, DayRule{Kind: LastDayOfMonth, N: v - 1})
+	}
+	return nil
+}
+
+// parseTime sets the hour, minute and second of the schedule from the time:
+//
+//	hour ":" minute [":" second]
+func (e *systemdEvent) parseTime(s *SpecSchedule) error {
+	parts := strings.Split(*e.time, ":")
+	if len(parts) > 3 {
+		err := parseErrorf(ReasonSyntax, *e.time, "too many colons in time: %s", *e.time)
+		return locate(err, e.original, FieldSpec, e.timeSpan.start, e.timeSpan.end)
+	}
+	var (
+		fields = []*uint64{&s.Hour, &s.Minute, &s.Second}
+		names  = []SpecField{FieldHour, FieldMinute, FieldSecond}
+		ranges = []bounds{hours, minutes, seconds}
+		offset = e.timeSpan.start
+		err    error
+	)
+	for i, part := range parts {
+		if strings.Contains(part, ".") {
+			err = parseErrorf(ReasonSyntax, part, "sub-second times are not supported: %s", *e.time)
+			return locate(err, e.original, names[i], offset, offset+len(part))
+		}
+		if *fields[i], _, err = e.field(part, names[i], offset, ranges[i], false); err != nil {
+			return err
+		}
+		offset += len(part) + 1
+	}
+	return nil
+}
+
+// field parses a component of the event, at the given offset in the spec,
+// and returns its bits and values.  Days counted from the end of the month
+// repeat downwards.
+func (e *systemdEvent) field(expr string, field SpecField, offset int, r bounds, fromEnd bool) (uint64, []int, error) {
+	var (
+		bits   uint64
+		values []int
+	)
+	names := r.names
+	if field == FieldDow {
+		names = systemdWeekdays
+	}
+	for _, item := range strings.Split(expr, ",") {
+		start, end, step, star, err := systemdRange(item, r, names, fromEnd)
+		if err != nil {
+			err = locate(err, e.original, field, offset, offset+len(expr))
+			if perr, ok := err.(*ParseError); ok {
+				perr.Min, perr.Max = int(r.min), int(r.max)
+			}
+			return 0, nil, err
+		}
+		if star && step == 1 {
+			bits |= starBit
+		}
+		for v := start; v <= end; v += step {
+			values = append(values, int(v))
+		}
+	}
+	sort.Ints(values)
+	for _, v := range values {
+		if v < 64 {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, dedupeInts(values), nil
+}
+
+// systemdRange returns the start, end and step of an item of a component:
+//
+//	"*" | value [".." value] ["/" step]
+//
+// where "value/step" repeats up to the end of the field.  Counting from the
+// end of the month, it repeats down to the last day instead.
+func systemdRange(item string, r bounds, names map[string]uint, fromEnd bool) (start, end, step uint, star bool, err error) {
+	var (
+		rangeAndStep = strings.Split(item, "/")
+		lowAndHigh   = strings.Split(rangeAndStep[0], "..")
+	)
+	if len(rangeAndStep) > 2 {
+		err = parseErrorf(ReasonSyntax, item, "too many slashes: %s", item)
+		return
+	}
+	if len(lowAndHigh) > 2 {
+		err = parseErrorf(ReasonSyntax, item, "too many ranges: %s", item)
+		return
+	}
+
+	if lowAndHigh[0] == "*" && len(lowAndHigh) == 1 {
+		start, end, star = r.min, r.max, true
+	} else {
+		if start, err = parseIntOrName(lowAndHigh[0], names); err != nil {
+			return
+		}
+		end = start
+		if len(lowAndHigh) == 2 {
+			if end, err = parseIntOrName(lowAndHigh[1], names); err != nil {
+				return
+			}
+		}
+	}
+
+	step = 1
+	if len(rangeAndStep) == 2 {
+		if step, err = mustParseInt(rangeAndStep[1]); err != nil {
+			return
+		}
+		if step == 0 {
+			err = parseErrorf(ReasonStep, item, "step of range should be a positive number: %s", item)
+			return
+		}
+		if len(lowAndHigh) == 1 && !star {
+			if fromEnd {
+				start, end = r.min+(start-r.min)%step, start
+			} else {
+				end = r.max
+			}
+		}
+	}
+
+	switch {
+	case start < r.min:
+		err = parseErrorf(ReasonBelowMinimum, item, "beginning of range (%d) below minimum (%d): %s", start, r.min, item)
+	case end > r.max:
+		err = parseErrorf(ReasonAboveMaximum, item, "end of range (%d) above maximum (%d): %s", end, r.max, item)
+	case start > end:
+		err = parseErrorf(ReasonReversedRange, item, "beginning of range (%d) beyond end of range (%d): %s", start, end, item)
+	}
+	return
+}
+
+// dedupeInts returns the sorted values without repeats.
+func dedupeInts(values []int) []int {
+	var deduped []int
+	for i, v := range values {
+		if i == 0 || v != values[i-1] {
+			deduped = append(deduped, v)
+		}
+	}
+	return deduped
+}
+
+// SystemdSchedule is a calendar event with both weekdays and days of the
+// month, which as in systemd occurs on days matching both of them, e.g.
+// "Fri *-*-13".  Other calendar events are parsed to a SpecSchedule.
+type SystemdSchedule struct {
+	// Date is the schedule of the event, without its weekdays.
+	Date *SpecSchedule
+
+	// Weekdays are the days of the week on which the event occurs, as a bit
+	// set like SpecSchedule.Dow.
+	Weekdays uint64
+}
+
+// Next returns the first time after the given one at which the date matches
+// on one of the weekdays, or the zero time if there is none within
+// cycleYears, as for SpecSchedule, or in the configured years.
+func (s *SystemdSchedule) Next(t time.Time) time.Time {
+	limit := t.AddDate(cycleYears, 0, 0)
+	for next := s.Date.Next(t); !next.IsZero(); {
+		if s.Date.Year == nil && next.After(limit) {
+			break
+		}
+		day := next
+		if s.Date.Location != time.Local {
+			day = next.In(s.Date.Location)
+		}
+		if 1<<uint(day.Weekday())&s.Weekdays > 0 {
+			return next.In(t.Location())
+		}
+		// Skip the rest of the day.
+		endOfDay := time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, day.Location()).Add(-time.Second)
+		next = s.Date.Next(endOfDay)
+	}
+	return time.Time{}
+}
+
+// String returns the calendar event, e.g. "Fri *-*-13 00:00:00", which
+// SystemdParser turns back into an equal schedule.
+func (s *SystemdSchedule) String() string {
+	d := s.Date
+	weekdays := systemdList(fieldValues(s.Weekdays, dow), func(v int) string {
+		return time.Weekday(v).String()[:3]
+	})
+	twoDigits := func(v int) string { return fmt.Sprintf("%02d", v) }
+
+	year := "*"
+	if d.Year != nil {
+		year = systemdValues(d.Year, years, strconv.Itoa)
+	}
+	var day string
+	if len(d.DomRules) == 0 {
+		day = "-" + systemdField(d.Dom, dom, twoDigits)
+	} else {
+		var fromEnd []int
+		for _, rule := range d.DomRules {
+			fromEnd = append(fromEnd, rule.N+1)
+		}
+		sort.Ints(fromEnd)
+		day = "~" + systemdList(fromEnd, twoDigits)
+	}
+	event := fmt.Sprintf("%s %s-%s%s %s:%s:%s", weekdays,
+		year, systemdField(d.Month, months, twoDigits), day,
+		systemdField(d.Hour, hours, twoDigits),
+		systemdField(d.Minute, minutes, twoDigits),
+		systemdField(d.Second, seconds, twoDigits))
+	if d.Location != nil && d.Location != time.Local {
+		event += " " + d.Location.String()
+	}
+	return event
+}
+
+// MarshalText implements encoding.TextMarshaler, returning the event.
+func (s *SystemdSchedule) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// systemdField returns a component of a calendar event for the given bits.
+func systemdField(bits uint64, r bounds, name func(int) string) string {
+	if bits&starBit > 0 {
+		return "*"
+	}
+	return systemdValues(fieldValues(bits, r), r, name)
+}
+
+// systemdValues returns a component of a calendar event for the sorted
+// values, in the syntax of valuesString but with ".." ranges.
+func systemdValues(values []int, r bounds, name func(int) string) string {
+	return strings.Replace(valuesString(values, r, name), "-", "..", -1)
+}
+
+// systemdList returns a component of a calendar event listing the sorted
+// values, with ".." ranges for runs of three or more.
+func systemdList(values []int, name func(int) string) string {
+	var items []string
+	for i := 0; i < len(values); {
+		j := i
+		for j+1 < len(values) && values[j+1] == values[j]+1 {
+			j++
+		}
+		switch {
+		case j-i >= 2:
+			items = append(items, name(values[i])+".."+name(values[j]))
+		case j > i:
+			items = append(items, name(values[i]), name(values[j]))
+		default:
+			items = append(items, name(values[i]))
+		}
+		i = j + 1
+	}
+	return strings.Join(items, ",")
+}
@@ -0,0 +1,196 @@
+package cron
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSystemdParse(t *testing.T) {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	tests := []struct {
+		spec     string
+		expected Schedule
+	}{
+		{"Mon..Fri *-*-* 09:00:00", &SpecSchedule{
+			Second: 1, Minute: 1, Hour: 1 << 9, Dom: all(dom), Month: all(months), Dow: getBits(1, 5, 1), Location: time.Local}},
+		{"*-*-01 04:00", &SpecSchedule{
+			Second: 1, Minute: 1, Hour: 1 << 4, Dom: 1 << 1, Month: all(months), Dow: all(dow), Location: time.Local}},
+		{"*:0/15", &SpecSchedule{
+			Second: 1, Minute: getBits(0, 59, 15), Hour: all(hours), Dom: all(dom), Month: all(months), Dow: all(dow), Location: time.Local}},
+		{"*-*-* *:*:*", &SpecSchedule{
+			Second: all(seconds), Minute: all(minutes), Hour: all(hours), Dom: all(dom), Month: all(months), Dow: all(dow), Location: time.Local}},
+		{"weekly", &SpecSchedule{
+			Second: 1, Minute: 1, Hour: 1, Dom: all(dom), Month: all(months), Dow: 1 << 1, Location: time.Local}},
+		{"quarterly UTC", &SpecSchedule{
+			Second: 1, Minute: 1, Hour: 1, Dom: 1 << 1, Month: 1<<1 | 1<<4 | 1<<7 | 1<<10, Dow: all(dow), Location: time.UTC}},
+		{"Sat,Sun 10:30 Europe/Berlin", &SpecSchedule{
+			Second: 1, Minute: 1 << 30, Hour: 1 << 10, Dom: all(dom), Month: all(months), Dow: 1<<0 | 1<<6, Location: berlin}},
+		{"TZ=Europe/Berlin sunday 10:30", &SpecSchedule{
+			Second: 1, Minute: 1 << 30, Hour: 1 << 10, Dom: all(dom), Month: all(months), Dow: 1 << 0, Location: berlin}},
+		{"2026..2028-1..12/3-1", &SpecSchedule{
+			Second: 1, Minute: 1, Hour: 1, Dom: 1 << 1, Month: 1<<1 | 1<<4 | 1<<7 | 1<<10, Dow: all(dow), Year: []int{2026, 2027, 2028}, Location: time.Local}},
+		{"12-25 8:05:30", &SpecSchedule{
+			Second: 1 << 30, Minute: 1 << 5, Hour: 1 << 8, Dom: 1 << 25, Month: 1 << 12, Dow: all(dow), Location: time.Local}},
+		{"*-02~03", &SpecSchedule{
+			Second: 1, Minute: 1, Hour: 1, Month: 1 << 2, Dow: all(dow), Location: time.Local,
+			DomRules: []DayRule{{Kind: LastDayOfMonth, N: 2}}}},
+		{"*-*~3/1", &SpecSchedule{
+			Second: 1, Minute: 1, Hour: 1, Month: all(months), Dow: all(dow), Location: time.Local,
+			DomRules: []DayRule{{Kind: LastDayOfMonth, N: 0}, {Kind: LastDayOfMonth, N: 1}, {Kind: LastDayOfMonth, N: 2}}}},
+
+		// Both weekdays and days of the month
+		{"Fri *-*-13", &SystemdSchedule{
+			Date: &SpecSchedule{
+				Second: 1, Minute: 1, Hour: 1, Dom: 1 << 13, Month: all(months), Dow: all(dow), Location: time.Local},
+			Weekdays: 1 << 5}},
+	}
+
+	for _, c := range tests {
+		actual, err := SystemdParser{}.Parse(c.spec)
+		if err != nil {
+			t.Errorf("%s: %v", c.spec, err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("%s: expected %v, got %v", c.spec, c.expected, actual)
+		}
+	}
+}
+
+func TestSystemdNext(t *testing.T) {
+	tests := []struct {
+		spec, time string
+		expected   []string
+	}{
+		{"Mon..Fri *-*-* 09:00", "Fri Jul 13 12:00 2012",
+			[]string{"Mon Jul 16 09:00 2012", "Tue Jul 17 09:00 2012"}},
+		{"Fri *-*-13", "Mon Jul 9 00:00 2012",
+			[]string{"Fri Jul 13 00:00 2012", "Fri Sep 13 00:00 2013", "Fri Dec 13 00:00 2013"}},
+		{"Mon *-05~07/1 10:00", "Mon Jul 9 00:00 2012",
+			[]string{"Mon May 27 10:00 2013", "Mon May 26 10:00 2014"}},
+		{"Mon *-*-01..07 *:0/30", "Mon Jul 9 00:00 2012",
+			[]string{"Mon Aug 6 00:00 2012", "Mon Aug 6 00:30 2012", "Mon Aug 6 01:00 2012"}},
+		{"Sat 2012-12-01..07 08:00", "Mon Jul 9 00:00 2012",
+			[]string{"Sat Dec 1 08:00 2012"}},
+		{"Sat 2012-12-08..14 08:00", "Mon Jul 9 00:00 2012",
+			[]string{"Sat Dec 8 08:00 2012"}},
+		{"Mon 2012-12-25", "Mon Jul 9 00:00 2012", nil},
+
+		// Weekdays in the location of the event
+		{"Sun *-*-01..07 23:30 America/New_York", "TZ=UTC 2012-07-02T00:00:00-0000",
+			[]string{"2012-07-02T03:30:00-0000", "2012-08-06T03:30:00-0000"}},
+	}
+
+	for _, c := range tests {
+		sched, err := SystemdParser{}.Parse(c.spec)
+		if err != nil {
+			t.Errorf("%s: %v", c.spec, err)
+			continue
+		}
+		n := len(c.expected)
+		if n == 0 {
+			n = 1
+		}
+		assertTimes(t, c.spec, NextN(sched, getTime(c.time), n), c.expected)
+	}
+}
+
+func TestSystemdString(t *testing.T) {
+	tests := []struct {
+		spec, expected string
+	}{
+		{"Fri *-*-13", "Fri *-*-13 00:00:00"},
+		{"Mon,Tue,Thu..Sat 2026..2030/2-1..12/3-01..07 9:00 UTC", "Mon,Tue,Thu..Sat 2026..2030/2-*/3-01..07 09:00:00 UTC"},
+		{"mon *-05~07/1 10:00 Europe/Berlin", "Mon *-05~01..07 10:00:00 Europe/Berlin"},
+		{"Sun,Sat *-*-*/2 *:*:*", "Sun,Sat *-*-*/2 *:*:*"},
+	}
+
+	for _, c := range tests {
+		sched, err := SystemdParser{}.Parse(c.spec)
+		if err != nil {
+			t.Errorf("%s: %v", c.spec, err)
+			continue
+		}
+		actual := sched.(*SystemdSchedule).String()
+		if actual != c.expected {
+			t.Errorf("%s: expected %q, got %q", c.spec, c.expected, actual)
+		}
+
+		// Entries encode the event, and decode it again.
+		data, err := json.Marshal(Entry{Schedule: sched})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var entry Entry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			t.Errorf("%s: %v", data, err)
+			continue
+		}
+		if !reflect.DeepEqual(entry.Schedule, sched) {
+			t.Errorf("%s: expected %v, got %v", data, sched, entry.Schedule)
+		}
+	}
+}
+
+func TestSystemdFallback(t *testing.T) {
+	parser := SystemdParser{Fallback: standardParser}
+	for _, spec := range []string{"0 9 * * 1-5", "@every 5m", "@every 1h from 2026-01-01T09:30:00Z", "RRULE:FREQ=DAILY", "0 * * * * ~H5m"} {
+		if _, ok := parser.Fallback.(Parser); !ok {
+			t.Fatal("expected a Parser")
+		}
+		expected, err := standardParser.Parse(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual, err := parser.Parse(spec)
+		if err != nil {
+			t.Errorf("%s: %v", spec, err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%s: expected %v, got %v", spec, expected, actual)
+		}
+	}
+	if _, err := parser.Parse("Mon..Fri 09:00"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSystemdParseErrors(t *testing.T) {
+	tests := []struct {
+		spec           string
+		field          SpecField
+		offset, length int
+		reason         ParseErrorReason
+	}{
+		{"", FieldSpec, 0, 0, ReasonEmpty},
+		{"*-*-* 25:00", FieldHour, 6, 2, ReasonAboveMaximum},
+		{"*-13-01", FieldMonth, 2, 2, ReasonAboveMaximum},
+		{"*-*-00", FieldDom, 4, 2, ReasonBelowMinimum},
+		{"1969-*-01", FieldYear, 0, 4, ReasonBelowMinimum},
+		{"Mon *-*-05..01", FieldDom, 8, 6, ReasonReversedRange},
+		{"*:0/0", FieldMinute, 2, 3, ReasonStep},
+		{"*:*:00.5", FieldSecond, 4, 4, ReasonSyntax},
+		{"*-*-* 09:00 Mars/Olympus", FieldTimezone, 12, 12, ReasonLocation},
+		{"TZ=Mars/Olympus 09:00", FieldTimezone, 3, 12, ReasonLocation},
+		{"*-*-* 09:00 10:00", FieldSpec, 12, 5, ReasonSyntax},
+		{"2026-01-01-01", FieldSpec, 0, 13, ReasonSyntax},
+		{"Mon..Fri *-*-x", FieldDom, 13, 1, ReasonNumber},
+	}
+
+	for _, c := range tests {
+		_, err := SystemdParser{}.Parse(c.spec)
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("%q: expected a ParseError, got %v", c.spec, err)
+			continue
+		}
+		if perr.Field != c.field || perr.Offset != c.offset || perr.Length != c.length || perr.Reason != c.reason {
+			t.Errorf("%q: expected %s at %d+%d for %s, got %s at %d+%d for %s: %v", c.spec,
+				c.field, c.offset, c.length, c.reason, perr.Field, perr.Offset, perr.Length, perr.Reason, perr)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package cron
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WorkdayCalendar is a Calendar of working days: Monday to Friday, except
+// for public holidays, plus the weekend days which are made working days to
+// make up for them (调休).  Years for which it has no data only have
+// weekends off.
+type WorkdayCalendar struct {
+	// Location in which days begin and end.  As with SpecSchedule,
+	// time.Local means the location of the time given to Excludes.
+	Location *time.Location
+
+	mu    sync.RWMutex
+	years map[int]workdayYear
+}
+
+// workdayYear holds the adjustments of a year: the days off and the weekend
+// days worked.
+type workdayYear struct {
+	holidays, workdays map[civilDate]bool
+}
+
+// ChinaWorkdays is the working calendar of mainland China, as published each
+// year by the State Council, for the years from 2023 to 2026.  Later years
+// may be added with Load or LoadFile.
+//
+// It is consulted by the "WD" day-of-month modifiers in specs.
+var ChinaWorkdays = mustParseWorkdays(chinaWorkdayData)
+
+// NewWorkdayCalendar returns a calendar in which Monday to Friday are working
+// days, until adjustments are loaded into it.
+func NewWorkdayCalendar() *WorkdayCalendar {
+	return &WorkdayCalendar{Location: time.Local, years: make(map[int]workdayYear)}
+}
+
+// ParseWorkdays reads a calendar from a list of adjustments, as read by Load.
+func ParseWorkdays(r io.Reader) (*WorkdayCalendar, error) {
+	c := NewWorkdayCalendar()
+	if err := c.Load(r); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func mustParseWorkdays(data string) *WorkdayCalendar {
+	c, err := ParseWorkdays(strings.NewReader(data))
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// LoadFile is like Load, reading the adjustments from the file at the given
+// path.
+func (c *WorkdayCalendar) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return c.Load(f)
+}
+
+// Load reads adjustments to the calendar, one day or range of days per line,
+// followed by "holiday" (or "休") for days off and "workday" (or "班") for
+// weekend days worked:
+//
+//	# 2025
+//	2025-01-01 holiday 元旦
+//	2025-01-26 workday
+//	2025-01-28..2025-02-04 holiday 春节
+//
+// Anything after the kind is ignored, as are blank lines and lines starting
+// with "#".  The adjustments replace those of the calendar for each of the
+// years they cover, so that the data of a year may be corrected; holidays
+// spanning the new year belong to the year in which they end.
+func (c *WorkdayCalendar) Load(r io.Reader) error {
+	var (
+		years   = make(map[int]workdayYear)
+		scanner = bufio.NewScanner(r)
+	)
+	for n := 1; scanner.Scan(); n++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) < 2 {
+			return fmt.Errorf("line %d: expected a date and holiday or workday: %s", n, scanner.Text())
+		}
+		from, to, err := parseDateRange(fields[0])
+		if err != nil {
+			return fmt.Errorf("line %d: %v", n, err)
+		}
+		var holiday bool
+		switch strings.ToLower(fields[1]) {
+		case "holiday", "休":
+			holiday = true
+		case "workday", "班":
+		default:
+			return fmt.Errorf("line %d: expected holiday or workday, found %s", n, fields[1])
+		}
+		// Holidays around the new year belong to the year in which they end.
+		year, ok := years[to.Year()]
+		if !ok {
+			year = workdayYear{make(map[civilDate]bool), make(map[civilDate]bool)}
+			years[to.Year()] = year
+		}
+		for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+			if holiday {
+				year.holidays[dateOf(day)] = true
+			} else {
+				year.workdays[dateOf(day)] = true
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.years == nil {
+		c.years = make(map[int]workdayYear)
+	}
+	for y, year := range years {
+		c.years[y] = year
+	}
+	return nil
+}
+
+// parseDateRange parses a date, "2006-01-02", or a range of dates,
+// "2006-01-02..2006-01-08".
+func parseDateRange(s string) (from, to time.Time, err error) {
+	parts := strings.SplitN(s, "..", 2)
+	if from, err = time.Parse("2006-01-02", parts[0]); err != nil {
+		return from, to, fmt.Errorf("failed to parse date %s", parts[0])
+	}
+	to = from
+	if len(parts) == 2 {
+		if to, err = time.Parse("2006-01-02", parts[1]); err != nil {
+			return from, to, fmt.Errorf("failed to parse date %s", parts[1])
+		}
+		if to.Before(from) {
+			return from, to, fmt.Errorf("end of range before its beginning: %s", s)
+		}
+	}
+	return from, to, nil
+}
+
+// Years returns the years for which the calendar has adjustments, in order.
+func (c *WorkdayCalendar) Years() []int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var years []int
+	for y := range c.years {
+		years = append(years, y)
+	}
+	sort.Ints(years)
+	return years
+}
+
+// Excludes returns true if the day of the given time is not a working day.
+func (c *WorkdayCalendar) Excludes(t time.Time) bool {
+	if c.Location != nil && c.Location != time.Local {
+		t = t.In(c.Location)
+	}
+	return !c.isWorkday(t.Year(), t.Month(), t.Day())
+}
+
+// IsWorkday returns true if the day of the given time is a working day.
+func (c *WorkdayCalendar) IsWorkday(t time.Time) bool {
+	return !c.Excludes(t)
+}
+
+// isWorkday returns true if the given day is a working day.  Days out of the
+// month are normalized, as by time.Date.
+func (c *WorkdayCalendar) isWorkday(year int, month time.Month, day int) bool {
+	date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, y := range []int{date.Year(), date.Year() + 1} {
+		switch adjustments := c.years[y]; {
+		case adjustments.holidays[dateOf(date)]:
+			return false
+		case adjustments.workdays[dateOf(date)]:
+			return true
+		}
+	}
+	return date.Weekday() != time.Saturday && date.Weekday() != time.Sunday
+}
+
+// workdayOfMonth returns the number of the working day of the month which
+// the given day is, or 0 if it is not a working day.
+func (c *WorkdayCalendar) workdayOfMonth(year int, month time.Month, day int) int {
+	if !c.isWorkday(year, month, day) {
+		return 0
+	}
+	n := 1
+	for d := 1; d < day; d++ {
+		if c.isWorkday(year, month, d) {
+			n++
+		}
+	}
+	return n
+}
+
+// lastWorkdayOfMonth returns true if the given day is the last working day
+// of its month.
+func (c *WorkdayCalendar) lastWorkdayOfMonth(year int, month time.Month, day int) bool {
+	if !c.isWorkday(year, month, day) {
+		return false
+	}
+	for d := day + 1; d <= daysIn(month, year); d++ {
+		if c.isWorkday(year, month, d) {
+			return false
+		}
+	}
+	return true
+}
+
+// chinaWorkdayData are the holidays and make-up working days announced by
+// the General Office of the State Council.
+const chinaWorkdayData = `
+# 2023
+2022-12-31..2023-01-02 holiday 元旦
+2023-01-21..2023-01-27 holiday 春节
+2023-01-28..2023-01-29 workday
+2023-04-05 holiday 清明节
+2023-04-29..2023-05-03 holiday 劳动节
+2023-04-23 workday
+2023-05-06 workday
+2023-06-22..2023-06-24 holiday 端午节
+2023-06-25 workday
+2023-09-29..2023-10-06 holiday 中秋节、国庆节
+2023-10-07..2023-10-08 workday
+
+# 2024
+2023-12-30..2024-01-01 holiday 元旦
+2024-02-10..2024-02-17 holiday 春节
+2024-02-04 workday
+2024-02-18 workday
+2024-04-04..2024-04-06 holiday 清明节
+2024-04-07 workday
+2024-05-01..2024-05-05 holiday 劳动节
+2024-04-28 workday
+2024-05-11 workday
+2024-06-08..2024-06-10 holiday 端午节
+2024-09-15..2024-09-17 holiday 中秋节
+2024-09-14 workday
+2024-10-01..2024-10-07 holiday 国庆节
+2024-09-29 workday
+2024-10-12 workday
+
+# 2025
+2025-01-01 holiday 元旦
+2025-01-28..2025-02-04 holiday 春节
+2025-01-26 workday
+2025-02-08 workday
+2025-04-04..2025-04-06 holiday 清明节
+2025-05-01..2025-05-05 holiday 劳动节
+2025-04-27 workday
+2025-05-31..2025-06-02 holiday 端午节
+2025-10-01..2025-10-08 holiday 国庆节、中秋节
+2025-09-28 workday
+2025-10-11 workday
+
+# 2026
+2026-01-01..2026-01-03 holiday 元旦
+2026-01-04 workday
+2026-02-15..2026-02-23 holiday 春节
+2026-02-14 workday
+2026-02-28 workday
+2026-04-04..2026-04-06 holiday 清明节
+2026-05-01..2026-05-05 holiday 劳动节
+2026-05-09 workday
+2026-06-19..2026-06-21 holiday 端午节
+2026-09-25..2026-09-27 holiday 中秋节
+2026-10-01..2026-10-07 holiday 国庆节
+2026-09-20 workday
+2026-10-10 workday
+`
@@ -0,0 +1,199 @@
+package cron
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChinaWorkdays(t *testing.T) {
+	tests := []struct {
+		date    string
+		workday bool
+	}{
+		{"Wed Jan 1 09:00 2025", false},
+		{"Thu Jan 2 09:00 2025", true},
+		{"Sat Jan 25 09:00 2025", false},
+		{"Sun Jan 26 09:00 2025", true},
+		{"Tue Jan 28 09:00 2025", false},
+		{"Tue Feb 4 09:00 2025", false},
+		{"Wed Feb 5 09:00 2025", true},
+		{"Sat Feb 8 09:00 2025", true},
+		{"Wed Oct 8 09:00 2025", false},
+		{"Sat Oct 11 09:00 2025", true},
+		{"Sun Feb 18 09:00 2024", true},
+		{"Sat Dec 30 09:00 2023", false},
+		{"Mon Jan 1 09:00 2024", false},
+
+		// Years without data only have weekends off.
+		{"Thu Jan 1 09:00 2099", true},
+		{"Sat Jan 3 09:00 2099", false},
+	}
+	for _, c := range tests {
+		if actual := ChinaWorkdays.IsWorkday(getTime(c.date)); actual != c.workday {
+			t.Errorf("%s: expected workday %v, got %v", c.date, c.workday, actual)
+		}
+		if actual := ChinaWorkdays.Excludes(getTime(c.date)); actual == c.workday {
+			t.Errorf("%s: expected excluded %v, got %v", c.date, !c.workday, actual)
+		}
+	}
+}
+
+func TestWorkdaySpecs(t *testing.T) {
+	tests := []struct {
+		spec     string
+		time     string
+		expected []string
+	}{
+		// Settlement at 10:00 on each working day, over the Spring Festival.
+		{"0 10 WD * *", "Fri Jan 24 12:00 2025", []string{
+			"Sun Jan 26 10:00 2025",
+			"Mon Jan 27 10:00 2025",
+			"Wed Feb 5 10:00 2025",
+			"Thu Feb 6 10:00 2025",
+			"Fri Feb 7 10:00 2025",
+			"Sat Feb 8 10:00 2025",
+			"Mon Feb 10 10:00 2025",
+		}},
+		{"0 10 1WD * *", "Sat Dec 20 00:00 2025", []string{
+			"Sun Jan 4 10:00 2026",
+			"Mon Feb 2 10:00 2026",
+			"Mon Mar 2 10:00 2026",
+		}},
+		{"0 10 3WD 10 *", "Mon Jan 1 00:00 2024", []string{
+			"Tue Oct 10 10:00 2024",
+			"Sat Oct 11 10:00 2025",
+		}},
+		{"0 17 LWD * *", "Sun Sep 1 00:00 2024", []string{
+			"Mon Sep 30 17:00 2024",
+			"Thu Oct 31 17:00 2024",
+		}},
+		{"0 17 LWD * *", "Mon Jan 20 00:00 2025", []string{
+			"Mon Jan 27 17:00 2025",
+			"Fri Feb 28 17:00 2025",
+		}},
+		{"0 10 WD,L * *", "Thu Sep 26 12:00 2024", []string{
+			"Fri Sep 27 10:00 2024",
+			"Sun Sep 29 10:00 2024",
+			"Mon Sep 30 10:00 2024",
+			"Tue Oct 8 10:00 2024",
+		}},
+	}
+	for _, c := range tests {
+		sched, err := ParseStandard(c.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		assertTimes(t, c.spec, NextN(sched, getTime(c.time), len(c.expected)), c.expected)
+	}
+}
+
+func TestWorkdaySpecErrors(t *testing.T) {
+	for _, spec := range []string{"0 10 0WD * *", "0 10 32WD * *", "0 10 xWD * *", "0 10 * * WD"} {
+		if _, err := ParseStandard(spec); err == nil {
+			t.Errorf("%s: expected an error", spec)
+		}
+	}
+}
+
+func TestWorkdayDescribe(t *testing.T) {
+	tests := []struct {
+		spec, en, zh string
+	}{
+		{"0 10 WD * *", "At 10:00 on working days", "每个工作日 10:00"},
+		{"0 10 2WD * *", "At 10:00 on the 2nd working day of the month", "每月第2个工作日 10:00"},
+		{"30 17 LWD * *", "At 17:30 on the last working day of the month", "每月最后一个工作日 17:30"},
+	}
+	for _, c := range tests {
+		sched, err := ParseStandard(c.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual := Describe(sched); actual != c.en {
+			t.Errorf("%s: expected %q, got %q", c.spec, c.en, actual)
+		}
+		if actual := Chinese.Describe(sched); actual != c.zh {
+			t.Errorf("%s: expected %q, got %q", c.spec, c.zh, actual)
+		}
+		if actual := sched.(*SpecSchedule).String(); actual != c.spec {
+			t.Errorf("expected %q, got %q", c.spec, actual)
+		}
+	}
+}
+
+// Weekdays are Monday through Friday, while working days follow the Chinese
+// calendar, so their descriptions must differ in Chinese too.
+func TestWorkdayDescribeWeekday(t *testing.T) {
+	for _, specs := range [][2]string{{"0 0 LW * *", "0 0 LWD * *"}, {"0 0 15W * *", "0 0 WD * *"}} {
+		weekday, _ := ParseStandard(specs[0])
+		workday, _ := ParseStandard(specs[1])
+		if a, b := Chinese.Describe(weekday), Chinese.Describe(workday); a == b || strings.Contains(a, "工作日") {
+			t.Errorf("%s and %s: expected distinct descriptions, got %q and %q", specs[0], specs[1], a, b)
+		}
+	}
+}
+
+func TestWorkdayCalendarLoad(t *testing.T) {
+	c, err := ParseWorkdays(strings.NewReader(`
+# 2030
+2030-01-01 holiday 元旦
+2029-12-29 班
+2029-12-31..2030-01-01 休
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if years := c.Years(); len(years) != 2 || years[0] != 2029 || years[1] != 2030 {
+		t.Errorf("expected years [2029 2030], got %v", years)
+	}
+	for _, date := range []string{"Mon Dec 31 09:00 2029", "Tue Jan 1 09:00 2030", "Sun Jan 6 09:00 2030"} {
+		if c.IsWorkday(getTime(date)) {
+			t.Errorf("expected %s not to be a working day", date)
+		}
+	}
+	if !c.IsWorkday(getTime("Sat Dec 29 09:00 2029")) {
+		t.Error("expected Sat Dec 29 2029 to be a working day")
+	}
+
+	// Loading a year again replaces it.
+	dir, err := ioutil.TempDir("", "workdays")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "workdays.txt")
+	if err := ioutil.WriteFile(path, []byte("2030-01-02 holiday\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if !c.IsWorkday(getTime("Tue Jan 1 09:00 2030")) || c.IsWorkday(getTime("Wed Jan 2 09:00 2030")) {
+		t.Error("expected the adjustments of 2030 to be replaced")
+	}
+
+	for _, data := range []string{
+		"2030-01-01\n",
+		"2030-01-01 off\n",
+		"2030-13-01 holiday\n",
+		"2030-01-05..2030-01-01 holiday\n",
+	} {
+		if _, err := ParseWorkdays(strings.NewReader(data)); err == nil {
+			t.Errorf("%q: expected an error", data)
+		}
+	}
+	if err := c.LoadFile(filepath.Join(dir, "missing.txt")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestWorkdayCalendarSchedule(t *testing.T) {
+	sched, _ := ParseStandard("0 10 * * 1-5")
+	s := AvoidHolidays(sched, ChinaWorkdays, NextBusinessDay)
+	assertTimes(t, "0 10 * * 1-5", NextN(s, time.Date(2025, time.September, 30, 12, 0, 0, 0, time.Local), 2),
+		[]string{"Thu Oct 9 10:00 2025", "Fri Oct 10 10:00 2025"})
+}